@@ -0,0 +1,202 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mendes11/swarm-browser/internal/config"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+// Bookmarker holds the prompt naming a new bookmark and the result of the last bookmark operation
+type Bookmarker struct {
+	// Location being bookmarked, while prompting for its name
+	location *config.Bookmark
+
+	input  textinput.Model
+	status string
+	err    error
+}
+
+// NewBookmarker creates the bookmark prompt
+func NewBookmarker() *Bookmarker {
+	input := textinput.New()
+	input.CharLimit = 50
+	input.Width = 30
+	return &Bookmarker{input: input}
+}
+
+// Prompting reports whether the user is typing the name of a bookmark
+func (b *Bookmarker) Prompting() bool {
+	return b.location != nil
+}
+
+// Prompt asks for the name of a bookmark of the location, suggesting its last part
+func (b *Bookmarker) Prompt(location config.Bookmark) {
+	b.location = &location
+	b.input.Prompt = fmt.Sprintf("Bookmark %s as: ", bookmarkPath(location))
+	b.input.SetValue(location.Name)
+	b.input.CursorEnd()
+	b.input.Focus()
+	b.SetStatus("", nil)
+}
+
+// ClosePrompt hides the prompt, returning the prompted location and the typed name
+func (b *Bookmarker) ClosePrompt() (*config.Bookmark, string) {
+	location, name := b.location, strings.TrimSpace(b.input.Value())
+	b.location = nil
+	b.input.Blur()
+	return location, name
+}
+
+// SetStatus displays the result of the last operation
+func (b *Bookmarker) SetStatus(status string, err error) {
+	b.status = status
+	b.err = err
+}
+
+// View renders the prompt and status lines, if any
+func (b *Bookmarker) View() string {
+	lines := []string{}
+	if b.Prompting() {
+		lines = append(lines, b.input.View())
+	}
+	if b.err != nil {
+		lines = append(lines, lipgloss.NewStyle().Foreground(ColorError).Render(fmt.Sprintf("Error: %v", b.err)))
+	} else if b.status != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(ColorSuccess).Render(b.status))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// bookmarkPath renders the location of a bookmark, e.g. prod > backend > backend_api
+func bookmarkPath(bookmark config.Bookmark) string {
+	parts := []string{bookmark.Cluster}
+	for _, part := range []string{bookmark.Stack, bookmark.Service} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " > ")
+}
+
+// currentLocation returns the location of the current view, named after its last part. Views
+// opened from a stack or service, e.g. the mounts of a task, are located at that stack or service.
+func (m *Model) currentLocation() config.Bookmark {
+	location := config.Bookmark{Cluster: m.currentClusterName}
+	if frame := m.nav.current; m.state != StacksList && frame.stack != nil {
+		location.Stack = frame.stack.Name
+		if m.state != ServicesList && frame.service != nil {
+			location.Service = frame.service.Name
+		}
+	}
+	switch m.state {
+	case StacksList, ServicesList, TaskList:
+		// The filter only applies to the view it was typed in
+		location.Filter = m.filterInput.Value()
+	}
+	location.Name = location.Cluster
+	if location.Service != "" {
+		location.Name = location.Service
+	} else if location.Stack != "" {
+		location.Name = location.Stack
+	}
+	return location
+}
+
+// saveBookmark adds a bookmark to the state file, replacing the one with the same name
+func (m *Model) saveBookmark(bookmark config.Bookmark) {
+	m.userState.AddBookmark(bookmark)
+	if err := m.userState.Save(m.conf.StateFilePath); err != nil {
+		m.bookmarker.SetStatus("", err)
+		return
+	}
+	m.bookmarker.SetStatus(fmt.Sprintf("Bookmarked %s as %s", bookmarkPath(bookmark), bookmark.Name), nil)
+}
+
+// deleteBookmark removes a bookmark from the state file
+func (m *Model) deleteBookmark(name string) {
+	m.userState.RemoveBookmark(name)
+	if err := m.userState.Save(m.conf.StateFilePath); err != nil {
+		m.bookmarker.SetStatus("", err)
+		return
+	}
+	m.bookmarker.SetStatus(fmt.Sprintf("Deleted bookmark %s", name), nil)
+}
+
+// selectedBookmark returns the bookmark under the cursor of the bookmarks list
+func (m *Model) selectedBookmark() (config.Bookmark, bool) {
+	row := m.table.SelectedRow()
+	if row == nil {
+		return config.Bookmark{}, false
+	}
+	return m.userState.Bookmark(row[0])
+}
+
+// openBookmark navigates to the location of a bookmark, connecting to its cluster first when
+// it isn't the current one. The stacks and services on the way are opened by followBookmark
+// as they are listed.
+func (m *Model) openBookmark(bookmark config.Bookmark) tea.Cmd {
+	if _, found := m.conf.Clusters[bookmark.Cluster]; !found {
+		m.bookmarker.SetStatus("", fmt.Errorf("the cluster %s of bookmark %s isn't configured", bookmark.Cluster, bookmark.Name))
+		m.table.SetHeight(m.tableHeight())
+		return nil
+	}
+	m.openingBookmark = &bookmark
+	m.bookmarker.SetStatus("", nil)
+	m.clearFilter()
+	if bookmark.Cluster != m.currentClusterName || m.browser == nil {
+		return m.switchCluster(bookmark.Cluster)
+	}
+	return m.listStacks()
+}
+
+// followBookmark continues opening a bookmark once the stacks or services of the current view
+// are listed, returning the command listing the next ones. Once the location is reached, its
+// filter is applied.
+func (m *Model) followBookmark() tea.Cmd {
+	bookmark := m.openingBookmark
+	if bookmark == nil || m.browser == nil {
+		return nil
+	}
+	switch {
+	case m.state == StacksList && bookmark.Stack != "":
+		index := slices.IndexFunc(m.stacks, func(stack models.Stack) bool { return stack.Name == bookmark.Stack })
+		if index < 0 {
+			m.bookmarkNotFound(fmt.Errorf("stack %s of bookmark %s not found", bookmark.Stack, bookmark.Name))
+			return nil
+		}
+		// Back from the services returns to the stack
+		m.selectRow(stackKey(m.stacks[index]))
+		return m.listServices(m.stacks[index])
+	case m.state == ServicesList && bookmark.Service != "":
+		index := slices.IndexFunc(m.services, func(service models.Service) bool { return service.Name == bookmark.Service })
+		if index < 0 {
+			m.bookmarkNotFound(fmt.Errorf("service %s of bookmark %s not found", bookmark.Service, bookmark.Name))
+			return nil
+		}
+		m.selectRow(serviceKey(m.services[index]))
+		return m.listTasks(m.services[index])
+	}
+
+	m.openingBookmark = nil
+	if bookmark.Filter != "" {
+		m.filterInput.SetValue(bookmark.Filter)
+		m.updateFilter()
+		m.refreshCurrentView()
+	}
+	m.table.SetHeight(m.tableHeight())
+	return nil
+}
+
+// bookmarkNotFound stops opening a bookmark, e.g. when its service was removed, staying
+// at the part of its location that was reached
+func (m *Model) bookmarkNotFound(err error) {
+	m.openingBookmark = nil
+	m.bookmarker.SetStatus("", err)
+	m.table.SetHeight(m.tableHeight())
+}
@@ -1,8 +1,6 @@
 package app
 
 import (
-	"fmt"
-
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mendes11/swarm-browser/internal/core/models"
 )
@@ -49,5 +47,5 @@ func renderConnectionStatus(status ConnectionStatuses) string {
 	case Connected:
 		return ConnectedStyle.Render(string(status))
 	}
-	panic(fmt.Sprintf("Invalid status %v", status))
+	return DisconnectedStyle.Render(string(status))
 }
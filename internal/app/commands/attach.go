@@ -2,15 +2,18 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"os"
+	"os/user"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mendes11/swarm-browser/internal/asciicast"
 	"github.com/mendes11/swarm-browser/internal/core"
 	"github.com/mendes11/swarm-browser/internal/core/models"
-	"golang.org/x/term"
+	"github.com/moby/moby/api/types/swarm"
 )
 
 // ContainerAttachedMsg is sent when starting a container attachment
@@ -18,32 +21,56 @@ type ContainerAttachedMsg struct {
 	Service *models.Service
 	Task    *models.Task
 	Conn    core.ContainerConnection
+	// Debug sessions run in a sidecar of the task, removed when the session ends
+	Debug bool
 }
 
-// ContainerOutputMsg sends container output to the UI
+// ContainerOutputMsg sends container output of a session to the UI
 type ContainerOutputMsg struct {
-	Data []byte
+	SessionID int
+	Data      []byte
 }
 
-// ContainerDetachedMsg is sent when the container session ends
+// ContainerDetachedMsg is sent when the container session ends.
+// SessionID is zero when attaching failed before a session was opened.
 type ContainerDetachedMsg struct {
-	Err error
+	SessionID int
+	Err       error
+}
+
+// SessionOptions configures the container sessions opened by the attach commands
+type SessionOptions struct {
+	// Multiplexer runs the shell inside tmux or screen, when the container provides it
+	Multiplexer string
+	// RecordDir enables recording the sessions as asciicast files in the directory
+	RecordDir   string
+	RecordInput bool
+	// Shells detects the shell to run, caching it across sessions
+	Shells *core.ShellProbe
+	// Metadata of the recordings, completed with the task details once attached
+	Metadata asciicast.Metadata
 }
 
 // AttachToService creates a command to attach to a service's container
-func AttachToService(browser core.ClusterBrowser, service models.Service) tea.Cmd {
+func AttachToService(ctx context.Context, browser core.ClusterBrowser, service models.Service, opts SessionOptions) tea.Cmd {
 	return func() tea.Msg {
 		log.Printf("Attaching to service %s\n", service.Name)
-		conn, err := browser.AttachToService(context.Background(), service, []string{"/bin/bash"})
+		task, err := runningTask(ctx, browser, service)
 		if err != nil {
-			// Try with bash if sh fails
-			conn, err = browser.AttachToService(context.Background(), service, []string{"/bin/sh"})
-			if err != nil {
-				log.Println(fmt.Errorf("failed to attach to service: %w", err))
-				return ContainerDetachedMsg{Err: fmt.Errorf("failed to attach to service: %w", err)}
-			}
+			return ContainerDetachedMsg{Err: fmt.Errorf("failed to attach to service: %w", err)}
+		}
+		conn, err := attach(ctx, browser, task, opts, func(cmd []string) (core.ContainerConnection, error) {
+			return browser.AttachToService(ctx, service, cmd)
+		})
+		if err != nil {
+			log.Println(fmt.Errorf("failed to attach to service: %w", err))
+			return ContainerDetachedMsg{Err: fmt.Errorf("failed to attach to service: %w", err)}
 		}
 		log.Printf("Attached to service %s\n", service.Name)
+		if err := recordSession(conn, opts, nil); err != nil {
+			conn.Close()
+			return ContainerDetachedMsg{Err: err}
+		}
 		return ContainerAttachedMsg{
 			Service: &service,
 			Conn:    conn,
@@ -51,18 +78,21 @@ func AttachToService(browser core.ClusterBrowser, service models.Service) tea.Cm
 	}
 }
 
-func AttachToTask(browser core.ClusterBrowser, task models.Task) tea.Cmd {
+func AttachToTask(ctx context.Context, browser core.ClusterBrowser, task models.Task, opts SessionOptions) tea.Cmd {
 	return func() tea.Msg {
 		log.Printf("Attaching to task %s\n", task.TaskID)
-		conn, err := browser.AttachToTask(context.Background(), task, []string{"/bin/bash"})
+		conn, err := attach(ctx, browser, task, opts, func(cmd []string) (core.ContainerConnection, error) {
+			return browser.AttachToTask(ctx, task, cmd)
+		})
 		if err != nil {
-			conn, err = browser.AttachToTask(context.Background(), task, []string{"/bin/sh"})
-			if err != nil {
-				log.Println(fmt.Errorf("failed to attach to service: %w", err))
-				return ContainerDetachedMsg{Err: err}
-			}
+			log.Println(fmt.Errorf("failed to attach to service: %w", err))
+			return ContainerDetachedMsg{Err: err}
 		}
 		log.Printf("Attached to Task %s\n", task.TaskID)
+		if err := recordSession(conn, opts, &task); err != nil {
+			conn.Close()
+			return ContainerDetachedMsg{Err: err}
+		}
 		return ContainerAttachedMsg{
 			Task: &task,
 			Conn: conn,
@@ -70,45 +100,160 @@ func AttachToTask(browser core.ClusterBrowser, task models.Task) tea.Cmd {
 	}
 }
 
-// ReadContainerOutput continuously reads from the container connection
-func ReadContainerOutput(conn core.ContainerConnection) tea.Cmd {
+// DebugTask starts a debug sidecar sharing the namespaces of the task container, opening a
+// session in it. The sidecar is removed when the session is closed.
+func DebugTask(ctx context.Context, browser core.ClusterBrowser, task models.Task, image string, opts SessionOptions) tea.Cmd {
+	if image == "" {
+		image = core.DefaultDebugImage
+	}
 	return func() tea.Msg {
-		buffer := make([]byte, 4096)
-		n, err := conn.Conn().Read(buffer)
+		log.Printf("Starting a %s debug sidecar for task %s\n", image, task.TaskID)
+		sidecar, err := browser.StartDebugSidecar(ctx, task, image)
 		if err != nil {
-			if err == io.EOF {
-				return ContainerDetachedMsg{Err: nil}
+			return ContainerDetachedMsg{Err: fmt.Errorf("failed to start the debug sidecar: %w", err)}
+		}
+		// The sidecar outlives the request, which may be cancelled by then
+		remove := func() {
+			if err := browser.RemoveDebugSidecar(context.Background(), sidecar); err != nil {
+				log.Printf("commands.DebugTask: %v\n", err)
 			}
+		}
+		// Multiplexers would keep the session running, while it ends with the sidecar
+		opts.Multiplexer = ""
+		conn, err := attach(ctx, browser, sidecar, opts, func(cmd []string) (core.ContainerConnection, error) {
+			return browser.AttachToTask(ctx, sidecar, cmd)
+		})
+		if err != nil {
+			remove()
+			return ContainerDetachedMsg{Err: fmt.Errorf("failed to attach to the debug sidecar: %w", err)}
+		}
+		log.Printf("Attached to debug sidecar %s of task %s\n", sidecar.ContainerID, task.TaskID)
+		if err := recordSession(conn, opts, &task); err != nil {
+			conn.Close()
+			remove()
 			return ContainerDetachedMsg{Err: err}
 		}
-		return ContainerOutputMsg{Data: buffer[:n]}
+		return ContainerAttachedMsg{
+			Task:  &task,
+			Conn:  &debugConnection{ContainerConnection: conn, remove: remove},
+			Debug: true,
+		}
 	}
 }
 
-// SendToContainer sends input to the container
-func SendToContainer(conn core.ContainerConnection, data []byte) tea.Cmd {
-	return func() tea.Msg {
-		_, err := conn.Conn().Write(data)
-		if err != nil {
-			return ContainerDetachedMsg{Err: err}
+// debugConnection is the connection to a debug sidecar session, removing the sidecar once closed
+type debugConnection struct {
+	core.ContainerConnection
+	remove func()
+}
+
+func (c *debugConnection) Close() error {
+	err := c.ContainerConnection.Close()
+	// Removing the container takes a while, don't hold the UI
+	go c.remove()
+	return err
+}
+
+// attach opens a shell with attachFn, inside the multiplexer if any, otherwise running the
+// best shell found in the task container
+func attach(ctx context.Context, browser core.ClusterBrowser, task models.Task, opts SessionOptions, attachFn func(cmd []string) (core.ContainerConnection, error)) (core.ContainerConnection, error) {
+	shells := opts.Shells
+	if shells == nil {
+		shells = core.NewShellProbe()
+	}
+	shell, err := shells.Detect(ctx, browser, task)
+	if errors.Is(err, core.ErrNoShell) {
+		return nil, fmt.Errorf("task %s: %w (looked for %s), its image is probably distroless", task.TaskID, err, strings.Join(core.Shells, ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("commands.attach: Found shell %s in task %s\n", shell, task.TaskID)
+	if cmd := core.SessionCommand(opts.Multiplexer); cmd != nil {
+		return attachFn(cmd)
+	}
+	return attachFn([]string{shell})
+}
+
+// runningTask returns the task of the service that AttachToService attaches to
+func runningTask(ctx context.Context, browser core.ClusterBrowser, service models.Service) (models.Task, error) {
+	tasks, err := browser.ListTasks(ctx, service)
+	if err != nil {
+		return models.Task{}, err
+	}
+	for _, task := range tasks {
+		if task.Status == swarm.TaskStateRunning {
+			return task, nil
 		}
+	}
+	return models.Task{}, fmt.Errorf("service %s has no running tasks", service.Name)
+}
+
+// recordSession starts recording the session when enabled. For audits, a session that can't
+// be recorded is not opened.
+func recordSession(conn core.ContainerConnection, opts SessionOptions, task *models.Task) error {
+	if opts.RecordDir == "" {
 		return nil
 	}
+	metadata := opts.Metadata
+	metadata.ContainerID = conn.ContainerID()
+	if task != nil {
+		metadata.Task = task.TaskID
+		metadata.Node = task.Node.Hostname
+	}
+	if current, err := user.Current(); err == nil {
+		metadata.User = current.Username
+	}
+	recorder, path, err := asciicast.Create(opts.RecordDir, asciicast.Header{
+		Width:    80,
+		Height:   24,
+		Title:    fmt.Sprintf("%s@%s", metadata.Service, metadata.Cluster),
+		Env:      map[string]string{"TERM": "xterm-256color"},
+		Metadata: &metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start recording the session: %w", err)
+	}
+	if err := core.RecordSession(conn, recorder, opts.RecordInput); err != nil {
+		recorder.Close()
+		return fmt.Errorf("failed to start recording the session: %w", err)
+	}
+	log.Printf("Recording session to %s\n", path)
+	return nil
 }
 
-// ResizeContainerTTY resizes the container's TTY
-func ResizeContainerTTY(conn core.ContainerConnection) tea.Cmd {
+// ReadContainerOutput continuously reads from the container connection of a session
+func ReadContainerOutput(sessionID int, conn core.ContainerConnection) tea.Cmd {
 	return func() tea.Msg {
-		width, height, err := term.GetSize(int(os.Stdout.Fd()))
+		buffer := make([]byte, 4096)
+		n, err := conn.Reader().Read(buffer)
 		if err != nil {
-			// Default to 80x24 if we can't get the size
-			width, height = 80, 24
+			if err == io.EOF {
+				return ContainerDetachedMsg{SessionID: sessionID, Err: nil}
+			}
+			return ContainerDetachedMsg{SessionID: sessionID, Err: err}
 		}
+		return ContainerOutputMsg{SessionID: sessionID, Data: buffer[:n]}
+	}
+}
 
-		err = conn.ResizeTTY(context.Background(), uint(width), uint(height))
+// SendToContainer sends input to the container of a session
+func SendToContainer(sessionID int, conn core.ContainerConnection, data []byte) tea.Cmd {
+	return func() tea.Msg {
+		_, err := conn.Conn().Write(data)
 		if err != nil {
+			return ContainerDetachedMsg{SessionID: sessionID, Err: err}
+		}
+		return nil
+	}
+}
+
+// ResizeContainerTTY resizes the container's TTY to the size of the terminal pane
+func ResizeContainerTTY(conn core.ContainerConnection, width, height int) tea.Cmd {
+	return func() tea.Msg {
+		if err := conn.ResizeTTY(context.Background(), uint(width), uint(height)); err != nil {
 			// Non-fatal, just log it
-			return nil
+			log.Printf("commands.ResizeContainerTTY: %v\n", err)
 		}
 		return nil
 	}
@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"log"
+	"os"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// CopyToClipboard sets the system clipboard with an OSC52 sequence, which the terminal
+// handles even when swarm-browser runs over SSH. Inside tmux or screen, the sequence
+// is wrapped so the multiplexer passes it through.
+func CopyToClipboard(text string) tea.Cmd {
+	return func() tea.Msg {
+		sequence := osc52.New(text)
+		switch {
+		case os.Getenv("TMUX") != "":
+			sequence = sequence.Tmux()
+		case os.Getenv("STY") != "":
+			sequence = sequence.Screen()
+		}
+		if _, err := sequence.WriteTo(os.Stdout); err != nil {
+			log.Printf("commands.CopyToClipboard: %v\n", err)
+		}
+		return nil
+	}
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"context"
 	"log"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -15,19 +16,22 @@ type ClusterConnected struct {
 }
 
 type ClusterConnectionFailed struct {
-	Err error
+	Cluster models.Cluster
+	Err     error
 }
 
-func ConnectToCluster(cluster models.Cluster) tea.Cmd {
+func ConnectToCluster(ctx context.Context, cluster models.Cluster) tea.Cmd {
 	return func() tea.Msg {
 		log.Println("Initializing ClusterBrowser")
 		browser := core.New(cluster)
 		log.Println("Inspecting Cluster Node")
-		nodeInfo, err := browser.InspectNode(cluster.Node)
+		nodeInfo, err := browser.InspectNode(ctx, cluster.Node)
 		if err != nil {
 			log.Printf("Failed to inspect cluster node: %v\n", err)
+			browser.Close()
 			return ClusterConnectionFailed{
-				Err: err,
+				Cluster: cluster,
+				Err:     err,
 			}
 		}
 		log.Println("Successfully Connected to Cluster")
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/moby/moby/api/types/swarm"
+)
+
+// ExecResultsMsg holds the results of a command run in all the tasks of a service
+type ExecResultsMsg struct {
+	Service     models.Service
+	CommandLine string
+	Results     []models.ExecResult
+}
+
+type ExecFailed struct {
+	Err error
+}
+
+// ExecInServiceTasks runs a shell command line in every running task of the service, in parallel
+func ExecInServiceTasks(ctx context.Context, browser core.ClusterBrowser, service models.Service, commandLine string) tea.Cmd {
+	return func() tea.Msg {
+		tasks, err := browser.ListTasks(ctx, service)
+		if err != nil {
+			return ExecFailed{Err: err}
+		}
+		running := make([]models.Task, 0, len(tasks))
+		for _, task := range tasks {
+			if task.Status == swarm.TaskStateRunning {
+				running = append(running, task)
+			}
+		}
+		if len(running) == 0 {
+			return ExecFailed{Err: fmt.Errorf("service %s has no running tasks", service.Name)}
+		}
+		log.Printf("commands.ExecInServiceTasks: Running %q in %d tasks of %s\n", commandLine, len(running), service.Name)
+		return ExecResultsMsg{
+			Service:     service,
+			CommandLine: commandLine,
+			Results:     core.ExecInTasks(ctx, browser, running, core.ShellCommand(commandLine)),
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+type ContainerDirListed struct {
+	Task  models.Task
+	Path  string
+	Files []models.FileInfo
+}
+
+type ListContainerDirError struct {
+	Task models.Task
+	Path string
+	Err  error
+}
+
+// FileCopied is sent when a download or upload finishes
+type FileCopied struct {
+	Description string
+}
+
+type CopyFileError struct {
+	Err error
+}
+
+// ListContainerDir lists a directory of the task container, its working directory when
+// dirPath is empty
+func ListContainerDir(ctx context.Context, browser core.ClusterBrowser, task models.Task, dirPath string) tea.Cmd {
+	return func() tea.Msg {
+		if dirPath == "" {
+			workingDir, err := browser.ContainerWorkingDir(ctx, task)
+			if err != nil {
+				return ListContainerDirError{Task: task, Path: "the working directory", Err: err}
+			}
+			dirPath = workingDir
+		}
+		log.Printf("commands.ListContainerDir: Listing %s in task %s\n", dirPath, task.TaskID)
+		files, err := browser.ListContainerDir(ctx, task, dirPath)
+		if err != nil {
+			return ListContainerDirError{Task: task, Path: dirPath, Err: err}
+		}
+		return ContainerDirListed{Task: task, Path: dirPath, Files: files}
+	}
+}
+
+func DownloadFromContainer(ctx context.Context, browser core.ClusterBrowser, task models.Task, srcPath string, dstDir string) tea.Cmd {
+	return func() tea.Msg {
+		log.Printf("commands.DownloadFromContainer: Copying %s from task %s into %s\n", srcPath, task.TaskID, dstDir)
+		if err := core.DownloadFromTask(ctx, browser, task, srcPath, dstDir); err != nil {
+			return CopyFileError{Err: err}
+		}
+		return FileCopied{Description: fmt.Sprintf("Downloaded %s to %s", srcPath, dstDir)}
+	}
+}
+
+func UploadToContainer(ctx context.Context, browser core.ClusterBrowser, task models.Task, srcPath string, dstDir string) tea.Cmd {
+	return func() tea.Msg {
+		log.Printf("commands.UploadToContainer: Copying %s into %s of task %s\n", srcPath, dstDir, task.TaskID)
+		if err := core.UploadToTask(ctx, browser, task, srcPath, dstDir); err != nil {
+			return CopyFileError{Err: err}
+		}
+		return FileCopied{Description: fmt.Sprintf("Uploaded %s to %s", srcPath, dstDir)}
+	}
+}
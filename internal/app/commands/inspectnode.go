@@ -1,13 +1,16 @@
 package commands
 
 import (
+	"context"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mendes11/swarm-browser/internal/core"
 	"github.com/mendes11/swarm-browser/internal/core/models"
 )
 
 type InspectNodeFailed struct {
-	Err error
+	Node models.Node
+	Err  error
 }
 
 type NodeInspected struct {
@@ -15,11 +18,11 @@ type NodeInspected struct {
 	Info models.NodeInfo
 }
 
-func InspectNode(browser core.SwarmConnector, node models.Node) tea.Cmd {
+func InspectNode(ctx context.Context, browser core.ClusterBrowser, node models.Node) tea.Cmd {
 	return func() tea.Msg {
-		nodeInfo, err := browser.InspectNode(node)
+		nodeInfo, err := browser.InspectNode(ctx, node)
 		if err != nil {
-			return InspectNodeFailed{Err: err}
+			return InspectNodeFailed{Node: node, Err: err}
 		}
 		return NodeInspected{
 			Node: node,
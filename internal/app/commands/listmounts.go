@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"log"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+type TaskMountsUpdated struct {
+	Task   models.Task
+	Mounts []models.Mount
+}
+
+type ListTaskMountsError struct {
+	Task models.Task
+	Err  error
+}
+
+type NodeVolumesUpdated struct {
+	Node    models.Node
+	Volumes []models.Volume
+}
+
+type ListNodeVolumesError struct {
+	Node models.Node
+	Err  error
+}
+
+func ListTaskMounts(ctx context.Context, browser core.ClusterBrowser, task models.Task) tea.Cmd {
+	return func() tea.Msg {
+		log.Printf("commands.ListTaskMounts: Listing mounts for task %s\n", task.TaskID)
+		mounts, err := browser.ListTaskMounts(ctx, task)
+		if err != nil {
+			return ListTaskMountsError{Task: task, Err: err}
+		}
+		log.Printf("Found %d mounts\n", len(mounts))
+		return TaskMountsUpdated{Task: task, Mounts: mounts}
+	}
+}
+
+func ListNodeVolumes(ctx context.Context, browser core.ClusterBrowser, node models.Node) tea.Cmd {
+	return func() tea.Msg {
+		log.Printf("commands.ListNodeVolumes: Listing volumes for node %s\n", node.Hostname)
+		volumes, err := browser.ListNodeVolumes(ctx, node)
+		if err != nil {
+			return ListNodeVolumesError{Node: node, Err: err}
+		}
+		log.Printf("Found %d volumes\n", len(volumes))
+		return NodeVolumesUpdated{Node: node, Volumes: volumes}
+	}
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"context"
+	"log"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+type NetworksUpdated struct {
+	Networks []models.Network
+}
+
+type ListNetworksError struct {
+	Err error
+}
+
+type NetworkAttachmentsUpdated struct {
+	Network     models.Network
+	Attachments []models.NetworkAttachment
+}
+
+type ListNetworkAttachmentsError struct {
+	Network models.Network
+	Err     error
+}
+
+func ListNetworks(ctx context.Context, browser core.ClusterBrowser) tea.Cmd {
+	return func() tea.Msg {
+		log.Println("Listing Networks...")
+		networks, err := browser.ListNetworks(ctx)
+		if err != nil {
+			return ListNetworksError{Err: err}
+		}
+		log.Printf("Found %d networks\n", len(networks))
+		return NetworksUpdated{Networks: networks}
+	}
+}
+
+func ListNetworkAttachments(ctx context.Context, browser core.ClusterBrowser, network models.Network) tea.Cmd {
+	return func() tea.Msg {
+		log.Printf("commands.ListNetworkAttachments: Listing attachments for network %s\n", network.Name)
+		attachments, err := browser.ListNetworkAttachments(ctx, network)
+		if err != nil {
+			return ListNetworkAttachmentsError{Network: network, Err: err}
+		}
+		log.Printf("Found %d attachments\n", len(attachments))
+		return NetworkAttachmentsUpdated{Network: network, Attachments: attachments}
+	}
+}
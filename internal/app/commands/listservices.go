@@ -15,16 +15,18 @@ type ServicesUpdated struct {
 }
 
 type ListServicesError struct {
-	Err error
+	Stack models.Stack
+	Err   error
 }
 
-func ListServices(browser core.ClusterBrowser, stack models.Stack) tea.Cmd {
+func ListServices(ctx context.Context, browser core.ClusterBrowser, stack models.Stack) tea.Cmd {
 	return func() tea.Msg {
 		log.Printf("Listing Services for stack: %s...\n", stack.Name)
-		services, err := browser.ListServices(context.Background(), stack)
+		services, err := browser.ListServices(ctx, stack)
 		if err != nil {
 			return ListServicesError{
-				Err: err,
+				Stack: stack,
+				Err:   err,
 			}
 		}
 		log.Printf("Services List Received: %v\n", services)
@@ -17,10 +17,10 @@ type ListStacksError struct {
 	Err error
 }
 
-func ListStacks(browser core.ClusterBrowser) tea.Cmd {
+func ListStacks(ctx context.Context, browser core.ClusterBrowser) tea.Cmd {
 	return func() tea.Msg {
 		log.Println("Listing Stacks...")
-		stacks, err := browser.ListStacks(context.Background())
+		stacks, err := browser.ListStacks(ctx)
 		if err != nil {
 			return ListStacksError{
 				Err: err,
@@ -15,15 +15,16 @@ type TasksUpdated struct {
 }
 
 type ListTasksError struct {
-	Err error
+	Service models.Service
+	Err     error
 }
 
-func ListTasks(browser core.ClusterBrowser, service models.Service) tea.Cmd {
+func ListTasks(ctx context.Context, browser core.ClusterBrowser, service models.Service) tea.Cmd {
 	return func() tea.Msg {
 		log.Printf("commands.ListTasks: Listing tasks for service %s\n", service.Name)
-		tasks, err := browser.ListTasks(context.Background(), service)
+		tasks, err := browser.ListTasks(ctx, service)
 		if err != nil {
-			return ListTasksError{Err: err}
+			return ListTasksError{Service: service, Err: err}
 		}
 		log.Printf("Found %d tasks\n", len(tasks))
 		return TasksUpdated{Service: service, Tasks: tasks}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/moby/moby/api/types/swarm"
+)
+
+type PortForwardStarted struct {
+	Forward models.PortForward
+}
+
+type PortForwardStopped struct {
+	Forward models.PortForward
+}
+
+type PortForwardError struct {
+	Err error
+}
+
+func StartPortForward(ctx context.Context, browser core.ClusterBrowser, task models.Task, localPort, remotePort int) tea.Cmd {
+	return func() tea.Msg {
+		log.Printf("commands.StartPortForward: Forwarding %d to %d of task %s\n", localPort, remotePort, task.TaskID)
+		forward, err := browser.StartPortForward(ctx, task, localPort, remotePort)
+		if err != nil {
+			return PortForwardError{Err: err}
+		}
+		return PortForwardStarted{Forward: forward}
+	}
+}
+
+// StartServicePortForward forwards the port of the first running task of the service
+func StartServicePortForward(ctx context.Context, browser core.ClusterBrowser, service models.Service, localPort, remotePort int) tea.Cmd {
+	return func() tea.Msg {
+		tasks, err := browser.ListTasks(ctx, service)
+		if err != nil {
+			return PortForwardError{Err: err}
+		}
+		for _, task := range tasks {
+			if task.Status != swarm.TaskStateRunning {
+				continue
+			}
+			log.Printf("commands.StartServicePortForward: Forwarding %d to %d of task %s\n", localPort, remotePort, task.TaskID)
+			forward, err := browser.StartPortForward(ctx, task, localPort, remotePort)
+			if err != nil {
+				return PortForwardError{Err: err}
+			}
+			return PortForwardStarted{Forward: forward}
+		}
+		return PortForwardError{Err: fmt.Errorf("service %s has no running tasks", service.Name)}
+	}
+}
+
+func StopPortForward(ctx context.Context, browser core.ClusterBrowser, forward models.PortForward) tea.Cmd {
+	return func() tea.Msg {
+		log.Printf("commands.StopPortForward: Stopping %s\n", forward)
+		if err := browser.StopPortForward(ctx, forward); err != nil {
+			return PortForwardError{Err: err}
+		}
+		return PortForwardStopped{Forward: forward}
+	}
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"context"
+	"log"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+// StatsInterval is how often the displayed stats are refreshed
+const StatsInterval = time.Second
+
+// StatsStreamStarted is sent once the stats stream for the displayed tasks is open
+type StatsStreamStarted struct {
+	StreamID  int
+	Stats     <-chan models.ContainerStats
+	ServiceOf map[string]string // Maps task IDs to their service IDs
+}
+
+// StatsReceived carries a single stats sample of a task
+type StatsReceived struct {
+	StreamID int
+	Stats    models.ContainerStats
+}
+
+// StatsStreamEnded is sent when the stats stream is closed
+type StatsStreamEnded struct {
+	StreamID int
+}
+
+type StatsStreamError struct {
+	StreamID int
+	Err      error
+}
+
+// StatsTicked signals that the displayed stats should be redrawn
+type StatsTicked struct {
+	StreamID int
+}
+
+// StreamTaskStats opens a stats stream for the tasks of a single service
+func StreamTaskStats(ctx context.Context, browser core.ClusterBrowser, streamID int, service models.Service, tasks []models.Task) tea.Cmd {
+	return func() tea.Msg {
+		log.Printf("commands.StreamTaskStats: Streaming stats for %d tasks of %s\n", len(tasks), service.Name)
+		serviceOf := make(map[string]string, len(tasks))
+		for _, task := range tasks {
+			serviceOf[task.TaskID] = service.ID
+		}
+		statsCh, err := browser.StreamTaskStats(ctx, tasks)
+		if err != nil {
+			return StatsStreamError{StreamID: streamID, Err: err}
+		}
+		return StatsStreamStarted{StreamID: streamID, Stats: statsCh, ServiceOf: serviceOf}
+	}
+}
+
+// StreamServicesStats lists the tasks of every service and opens a single stats stream for all of them
+func StreamServicesStats(ctx context.Context, browser core.ClusterBrowser, streamID int, services []models.Service) tea.Cmd {
+	return func() tea.Msg {
+		log.Printf("commands.StreamServicesStats: Streaming stats for %d services\n", len(services))
+		serviceOf := make(map[string]string)
+		allTasks := make([]models.Task, 0)
+		for _, service := range services {
+			tasks, err := browser.ListTasks(ctx, service)
+			if err != nil {
+				return StatsStreamError{StreamID: streamID, Err: err}
+			}
+			for _, task := range tasks {
+				serviceOf[task.TaskID] = service.ID
+			}
+			allTasks = append(allTasks, tasks...)
+		}
+		statsCh, err := browser.StreamTaskStats(ctx, allTasks)
+		if err != nil {
+			return StatsStreamError{StreamID: streamID, Err: err}
+		}
+		return StatsStreamStarted{StreamID: streamID, Stats: statsCh, ServiceOf: serviceOf}
+	}
+}
+
+// WaitForStats waits for the next sample of the stats stream
+func WaitForStats(streamID int, statsCh <-chan models.ContainerStats) tea.Cmd {
+	return func() tea.Msg {
+		stats, ok := <-statsCh
+		if !ok {
+			return StatsStreamEnded{StreamID: streamID}
+		}
+		return StatsReceived{StreamID: streamID, Stats: stats}
+	}
+}
+
+// TickStats schedules the next stats redraw
+func TickStats(streamID int) tea.Cmd {
+	return tea.Tick(StatsInterval, func(time.Time) tea.Msg {
+		return StatsTicked{StreamID: streamID}
+	})
+}
@@ -2,133 +2,297 @@ package app
 
 import (
 	"fmt"
-	"os"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mendes11/swarm-browser/internal/app/commands"
 	"github.com/mendes11/swarm-browser/internal/core"
-	"golang.org/x/term"
+	"github.com/mendes11/swarm-browser/internal/terminal"
 )
 
-// ExitContainerMsg is sent when exiting the container view
+// ExitContainerMsg is sent when leaving the container view. The session keeps running in background.
 type ExitContainerViewMsg struct {
 	Err error
 }
 
-// ExitContainerView returns a command that signals to exit the container view
+// ExitContainerView returns a command that signals to leave the container view
 func ExitContainerView(err error) tea.Cmd {
 	return func() tea.Msg {
 		return ExitContainerViewMsg{Err: err}
 	}
 }
 
-// ContainerView handles the interactive container session
+// containerSessionLabel returns the short name of a session, displayed in its tab
+func containerSessionLabel(msg commands.ContainerAttachedMsg) string {
+	switch {
+	case msg.Task != nil:
+		taskID := msg.Task.TaskID
+		if len(taskID) > 12 {
+			taskID = taskID[:12]
+		}
+		if msg.Debug {
+			return "debug:" + taskID
+		}
+		return taskID
+	case msg.Service != nil:
+		return msg.Service.Name
+	default:
+		return msg.Conn.ContainerID()
+	}
+}
+
+// containerSessionTitle describes the attached service or task
+func containerSessionTitle(msg commands.ContainerAttachedMsg) string {
+	containerID := msg.Conn.ContainerID()
+	if len(containerID) > 12 {
+		containerID = containerID[:12]
+	}
+	switch {
+	case msg.Task != nil && msg.Debug:
+		return fmt.Sprintf("debug sidecar of task %s on %s (container %s)", msg.Task.TaskID, msg.Task.Node.Hostname, containerID)
+	case msg.Task != nil:
+		return fmt.Sprintf("task %s on %s (container %s)", msg.Task.TaskID, msg.Task.Node.Hostname, containerID)
+	case msg.Service != nil:
+		return fmt.Sprintf("service %s (container %s)", msg.Service.Name, containerID)
+	default:
+		return fmt.Sprintf("container %s", containerID)
+	}
+}
+
+// SessionKeys are the keys handled by the container view rather than sent to the container
+type SessionKeys struct {
+	Detach   key.Binding
+	CopyMode key.Binding
+	// NextSession and PrevSession are handled by the browser, the view only shows them
+	NextSession key.Binding
+	PrevSession key.Binding
+}
+
+// ContainerView handles the interactive container session.
+//
+// The container output is fed into a terminal emulator, which is rendered in a pane
+// above a status bar with the session title and the detach hint.
 type ContainerView struct {
-	conn         core.ContainerConnection
-	rawMode      bool
-	oldTermState *term.State
-	err          error
+	sessionID int
+	conn      core.ContainerConnection
+	title     string
+	term      *terminal.Terminal
+	keys      SessionKeys
+
+	// Position of the view in the window, used to translate mouse events
+	top          int
+	width        int
+	height       int
+	mouseEnabled bool
+	shown        bool
+	// browserMouse is set when the browser reports the mouse, given back when the session
+	// stops capturing it
+	browserMouse bool
+
+	// Copy mode, browsing the scrollback instead of sending keys to the container
+	copy   *copyMode
+	notice string
 }
 
-// NewContainerView creates a new container view
-func NewContainerView(conn core.ContainerConnection) ContainerView {
+// NewContainerView creates a new container view, keeping the given number of lines of output
+// once they scroll off the screen (terminal.DefaultScrollback when zero)
+func NewContainerView(sessionID int, conn core.ContainerConnection, title string, scrollback int, keys SessionKeys) ContainerView {
+	term := terminal.New(80, 24)
+	if scrollback > 0 {
+		term.SetScrollback(scrollback)
+	}
 	return ContainerView{
-		conn:    conn,
-		rawMode: false,
+		sessionID: sessionID,
+		conn:      conn,
+		title:     title,
+		term:      term,
+		keys:      keys,
 	}
 }
 
-// Init starts the container session
+// Init starts reading the container output
 func (v ContainerView) Init() tea.Cmd {
-	return tea.Batch(
-		commands.EnterRawMode(),
-		commands.ReadContainerOutput(v.conn),
-	)
+	return commands.ReadContainerOutput(v.sessionID, v.conn)
 }
 
-// RestoreTerminal restores the terminal to its original state
-func (v *ContainerView) RestoreTerminal() {
-	if v.rawMode && v.oldTermState != nil {
-		term.Restore(int(os.Stdin.Fd()), v.oldTermState)
-		v.rawMode = false
+// SetSize places the view at the given window row with the given size,
+// resizing the container TTY to the space left for the terminal pane.
+func (v *ContainerView) SetSize(top, width, height int) tea.Cmd {
+	v.top, v.width, v.height = top, width, height
+	// The status bar takes a line
+	termWidth, termHeight := max(width, 1), max(height-1, 1)
+	if currentWidth, currentHeight := v.term.Size(); currentWidth == termWidth && currentHeight == termHeight {
+		return nil
 	}
+	v.term.Resize(termWidth, termHeight)
+	return commands.ResizeContainerTTY(v.conn, termWidth, termHeight)
 }
 
 // Update handles messages for the container view
 func (v ContainerView) Update(msg tea.Msg) (ContainerView, tea.Cmd) {
 	switch msg := msg.(type) {
-	case commands.EnteredRawModeMsg:
-		v.rawMode = true
-		v.oldTermState = msg.TerminalState
-
-		return v, tea.Batch(tea.ExitAltScreen, tea.ShowCursor)
-
-	case commands.EnterRawModeErrMsg:
-		return v, ExitContainerView(nil)
-
 	case commands.ContainerOutputMsg:
-		// Display container output directly
-		os.Stdout.Write(msg.Data)
-		// Continue reading
-		return v, commands.ReadContainerOutput(v.conn)
-
-	case commands.ContainerDetachedMsg:
-		v.RestoreTerminal()
-		v.err = msg.Err
-		if v.conn != nil {
-			v.conn.Close()
+		v.term.Write(msg.Data)
+		cmds := []tea.Cmd{commands.ReadContainerOutput(v.sessionID, v.conn)}
+		// Answer terminal queries, such as the cursor position report
+		if replies := v.term.Replies(); len(replies) > 0 {
+			cmds = append(cmds, commands.SendToContainer(v.sessionID, v.conn, replies))
 		}
-		// Return a command to go back to the previous view
-		return v, tea.Batch(tea.EnterAltScreen, tea.HideCursor, ExitContainerView(msg.Err))
+		// Only capture the mouse while the application running in the container asks for it
+		if mouseEnabled := v.term.MouseMode() != terminal.MouseNone; mouseEnabled != v.mouseEnabled {
+			v.mouseEnabled = mouseEnabled
+			if v.shown {
+				cmds = append(cmds, v.mouseMode())
+			}
+		}
+		return v, tea.Batch(cmds...)
 
 	case tea.KeyMsg:
-		// return v, nil
-		// Convert the Bubbletea key message to actual terminal bytes
-		data := KeyToBytes(msg)
-
-		// Check if it's the detach key (Ctrl+\) - returns nil to signal detachment
-		if data == nil && (msg.Type == tea.KeyCtrlBackslash || msg.String() == "ctrl+\\") {
-			v.RestoreTerminal()
-			if v.conn != nil {
-				v.conn.Close()
+		// Check if it's the detach key (Ctrl+\ by default), going back to the browser
+		if key.Matches(msg, v.keys.Detach) {
+			return v, v.leave(nil)
+		}
+		v.notice = ""
+		if v.copy != nil {
+			text, done := v.copy.update(msg, v.term)
+			if done {
+				v.copy = nil
 			}
-			return v, ExitContainerView(nil)
+			if text != "" {
+				v.notice = fmt.Sprintf("Copied %d characters to the clipboard", len([]rune(text)))
+				return v, commands.CopyToClipboard(text)
+			}
+			return v, nil
+		}
+		if key.Matches(msg, v.keys.CopyMode) {
+			v.copy = newCopyMode(v.term)
+			return v, nil
 		}
 
-		// Send the converted bytes to the container
+		// Convert the Bubbletea key message to actual terminal bytes
+		data := EncodeKey(msg, v.term.AppCursorKeys(), v.term.BracketedPaste())
 		if len(data) > 0 {
-			_, err := v.conn.Conn().Write(data)
-			if err != nil {
-				// Connection error - detach and report error
-				v.RestoreTerminal()
+			if _, err := v.conn.Conn().Write(data); err != nil {
+				// Connection error - end the session and report error
 				return v, func() tea.Msg {
-					return commands.ContainerDetachedMsg{Err: err}
+					return commands.ContainerDetachedMsg{SessionID: v.sessionID, Err: err}
 				}
 			}
 		}
 		return v, nil
+
+	case tea.MouseMsg:
+		if !v.mouseEnabled || v.copy != nil {
+			// The wheel browses the scrollback, like in copy mode
+			v.scroll(msg)
+			return v, nil
+		}
+		x, y := msg.X, msg.Y-v.top
+		termWidth, termHeight := v.term.Size()
+		if x < 0 || y < 0 || x >= termWidth || y >= termHeight {
+			return v, nil
+		}
+		data := MouseToBytes(msg, x, y, v.term.MouseMode(), v.term.MouseSGR())
+		if len(data) > 0 {
+			return v, commands.SendToContainer(v.sessionID, v.conn, data)
+		}
+		return v, nil
 	}
 
 	return v, nil
 }
 
+// scroll moves copy mode through the scrollback on wheel events, starting it when scrolling up
+func (v *ContainerView) scroll(msg tea.MouseMsg) {
+	if msg.Action != tea.MouseActionPress {
+		return
+	}
+	lines := 0
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		lines = -mouseWheelLines
+	case tea.MouseButtonWheelDown:
+		lines = mouseWheelLines
+	default:
+		return
+	}
+	if v.copy == nil {
+		if lines > 0 {
+			return
+		}
+		v.copy = newCopyMode(v.term)
+	}
+	if v.copy.scroll(lines, v.term) {
+		v.copy = nil
+	}
+}
+
+// leave returns to the browser, which takes the mouse back
+func (v *ContainerView) leave(err error) tea.Cmd {
+	return ExitContainerView(err)
+}
+
+// Show is called when the session becomes the displayed one, capturing the mouse again if needed
+func (v *ContainerView) Show() tea.Cmd {
+	v.shown = true
+	v.mouseEnabled = v.term.MouseMode() != terminal.MouseNone
+	return v.mouseMode()
+}
+
+// Hide is called when another session is displayed instead
+func (v *ContainerView) Hide() {
+	v.shown = false
+}
+
+// mouseMode reports the mouse events the session needs: all of them when the application
+// running in the container asks for the mouse, the browser ones otherwise
+func (v *ContainerView) mouseMode() tea.Cmd {
+	if v.mouseEnabled {
+		return tea.EnableMouseAllMotion
+	}
+	return browserMouseMode(v.browserMouse)
+}
+
+// browserMouseMode reports the mouse events used by the browser, clicks and the wheel, when
+// enabled
+func browserMouseMode(enabled bool) tea.Cmd {
+	if enabled {
+		return tea.EnableMouseCellMotion
+	}
+	return tea.DisableMouse
+}
+
+// Close ends the exec session
+func (v *ContainerView) Close() error {
+	return v.conn.Close()
+}
+
 // View renders the container view
 func (v ContainerView) View() string {
-	if v.err != nil {
-		return lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
-			Render(fmt.Sprintf("Error: %v\nPress any key to return...", v.err))
+	title := v.title
+	if termTitle := v.term.Title(); termTitle != "" {
+		title = fmt.Sprintf("%s — %s", title, termTitle)
 	}
-
-	// In raw mode, we don't render anything through bubbletea
-	// The terminal output is handled directly
-	if v.rawMode {
-		return "Press ctrl+d or exit the container to return to the view\n"
+	termWidth, termHeight := v.term.Size()
+	status := fmt.Sprintf("%s • %s back • %s copy mode • %s/%s switch session • %dx%d",
+		title, v.keys.Detach.Help().Key, v.keys.CopyMode.Help().Key,
+		v.keys.PrevSession.Help().Key, v.keys.NextSession.Help().Key, termWidth, termHeight)
+	if v.notice != "" {
+		status = fmt.Sprintf("%s • %s", v.notice, status)
+	}
+	if v.copy == nil {
+		return lipgloss.JoinVertical(lipgloss.Left,
+			v.term.Render(true),
+			containerStatusStyle.Width(v.width).MaxWidth(v.width).Render(status),
+		)
 	}
 
-	// Show a message before entering raw mode
-	return lipgloss.NewStyle().
-		Padding(1).
-		Render(fmt.Sprintf("Attaching to %s...", v.conn.ContainerID()))
+	v.copy.clamp(v.term)
+	v.copy.highlight(v.term)
+	status = v.copy.status(v.term)
+	return lipgloss.JoinVertical(lipgloss.Left,
+		v.term.RenderRows(v.copy.top, v.copy.decorate),
+		containerStatusStyle.Width(v.width).MaxWidth(v.width).Render(status),
+	)
 }
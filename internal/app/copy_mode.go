@@ -0,0 +1,363 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mendes11/swarm-browser/internal/terminal"
+)
+
+// endOfLine selects up to the end of the row in Terminal.Text
+const endOfLine = 1 << 30
+
+// copyMode browses the output of a session, scrollback included, with vi-style keys,
+// selecting text to copy to the clipboard. Positions are terminal rows, which keep
+// their number while new output scrolls them off the screen.
+type copyMode struct {
+	x, row int // Cursor
+	top    int // First displayed row
+
+	// Selection, from the anchor to the cursor
+	selecting bool
+	lines     bool // Whole lines, as with V
+	anchorX   int
+	anchorRow int
+
+	// Search, prompted with / (forward) or ? (backward) and repeated with n / N
+	prompt     *textinput.Model
+	pattern    *regexp.Regexp
+	backward   bool
+	message    string
+	highlights map[int][][2]int // Matches of the displayed rows, as cell ranges
+}
+
+var (
+	searchMatchStyle   = terminal.Style{Fg: terminal.PaletteColor(0), Bg: terminal.PaletteColor(3)}
+	searchCurrentStyle = terminal.Style{Fg: terminal.PaletteColor(0), Bg: terminal.PaletteColor(11), Bold: true}
+)
+
+// newCopyMode starts copy mode at the terminal cursor
+func newCopyMode(term *terminal.Terminal) *copyMode {
+	x, y := term.Cursor()
+	return &copyMode{x: x, row: term.ScreenTop() + y, top: term.ScreenTop()}
+}
+
+// update handles a key, returning the text to copy, if any, and whether copy mode ends
+func (c *copyMode) update(msg tea.KeyMsg, term *terminal.Terminal) (string, bool) {
+	if c.prompt != nil {
+		c.updatePrompt(msg, term)
+		return "", false
+	}
+	c.message = ""
+	width, height := term.Size()
+	switch msg.String() {
+	case "/", "?":
+		prompt := textinput.New()
+		prompt.Prompt = msg.String()
+		prompt.Cursor.SetMode(cursor.CursorStatic)
+		if c.pattern != nil {
+			prompt.Placeholder = c.pattern.String()
+		}
+		prompt.Focus()
+		c.prompt = &prompt
+		c.backward = msg.String() == "?"
+		return "", false
+	case "n", "N":
+		c.searchNext(term, msg.String() == "N")
+	case "h", "left":
+		c.x--
+	case "l", "right":
+		c.x++
+	case "k", "up":
+		c.row--
+	case "j", "down":
+		c.row++
+	case "0", "home":
+		c.x = 0
+	case "$", "end":
+		c.x = lineEnd(term.Row(c.row))
+	case "w":
+		c.x = nextWord(term.Row(c.row), c.x)
+	case "b":
+		c.x = previousWord(term.Row(c.row), c.x)
+	case "g":
+		c.row, c.x = term.FirstRow(), 0
+	case "G":
+		c.row = term.ScreenTop() + height - 1
+	case "ctrl+u":
+		c.row -= height / 2
+	case "ctrl+d":
+		c.row += height / 2
+	case "ctrl+b", "pgup":
+		c.row -= height
+	case "ctrl+f", "pgdown":
+		c.row += height
+	case "v", "V":
+		lines := msg.String() == "V"
+		if c.selecting && c.lines == lines {
+			c.selecting = false
+		} else {
+			c.selecting, c.lines = true, lines
+			c.anchorX, c.anchorRow = c.x, c.row
+		}
+	case "y", "enter":
+		if !c.selecting {
+			// Like yy, copy the cursor line
+			return term.RowText(c.row), true
+		}
+		return c.selection(term), true
+	case "esc":
+		if c.selecting {
+			c.selecting = false
+			return "", false
+		}
+		return "", true
+	case "q", "ctrl+c":
+		return "", true
+	}
+	c.x = min(max(c.x, 0), width-1)
+	c.clamp(term)
+	return "", false
+}
+
+// updatePrompt edits the search pattern, searching once entered
+func (c *copyMode) updatePrompt(msg tea.KeyMsg, term *terminal.Terminal) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		c.prompt = nil
+	case tea.KeyEnter:
+		value := c.prompt.Value()
+		c.prompt = nil
+		if value == "" {
+			// Repeat the last search, in the new direction
+			c.searchNext(term, false)
+			return
+		}
+		pattern, err := regexp.Compile(value)
+		if err != nil {
+			c.message = fmt.Sprintf("Invalid pattern: %v", err)
+			return
+		}
+		c.pattern = pattern
+		c.searchNext(term, false)
+	default:
+		prompt, _ := c.prompt.Update(msg)
+		c.prompt = &prompt
+	}
+}
+
+// searchNext moves the cursor to the next match of the pattern in the search direction,
+// or the opposite one when reverse is set, wrapping around the available rows
+func (c *copyMode) searchNext(term *terminal.Terminal, reverse bool) {
+	if c.pattern == nil {
+		c.message = "No previous search"
+		return
+	}
+	_, height := term.Size()
+	first, last := term.FirstRow(), term.ScreenTop()+height-1
+	backward := c.backward != reverse
+	rows := last - first + 1
+	for i := 0; i <= rows; i++ {
+		row := c.row + i
+		if backward {
+			row = c.row - i
+		}
+		// Wrap around
+		row = first + ((row-first)%rows+rows)%rows
+		matches := rowMatches(term, row, c.pattern)
+		if backward {
+			for j := len(matches) - 1; j >= 0; j-- {
+				if i > 0 || matches[j][0] < c.x || i == rows {
+					c.x, c.row = matches[j][0], row
+					c.clamp(term)
+					return
+				}
+			}
+			continue
+		}
+		for _, match := range matches {
+			if i > 0 || match[0] > c.x || i == rows {
+				c.x, c.row = match[0], row
+				c.clamp(term)
+				return
+			}
+		}
+	}
+	c.message = fmt.Sprintf("Pattern not found: %s", c.pattern)
+}
+
+// rowMatches returns the cell ranges of a row matching the pattern, end excluded
+func rowMatches(term *terminal.Terminal, row int, pattern *regexp.Regexp) [][2]int {
+	// Map the byte offsets of the row text to cells
+	var sb strings.Builder
+	cellAt := []int{}
+	for x, cell := range term.Row(row) {
+		if cell.Width == 0 {
+			continue
+		}
+		content := cell.String()
+		sb.WriteString(content)
+		for range len(content) {
+			cellAt = append(cellAt, x)
+		}
+	}
+	text := sb.String()
+	cellAt = append(cellAt, len(term.Row(row)))
+
+	var matches [][2]int
+	for _, match := range pattern.FindAllStringIndex(strings.TrimRight(text, " "), -1) {
+		// Skip empty matches, which can't be highlighted
+		if match[0] == match[1] {
+			continue
+		}
+		matches = append(matches, [2]int{cellAt[match[0]], cellAt[match[1]]})
+	}
+	return matches
+}
+
+// highlight finds the matches of the rows about to be displayed
+func (c *copyMode) highlight(term *terminal.Terminal) {
+	c.highlights = nil
+	if c.pattern == nil {
+		return
+	}
+	_, height := term.Size()
+	c.highlights = make(map[int][][2]int, height)
+	for row := c.top; row < c.top+height; row++ {
+		if matches := rowMatches(term, row, c.pattern); len(matches) > 0 {
+			c.highlights[row] = matches
+		}
+	}
+}
+
+// status describes the copy mode state in the session status bar
+func (c *copyMode) status(term *terminal.Terminal) string {
+	if c.prompt != nil {
+		return c.prompt.View()
+	}
+	_, height := term.Size()
+	status := fmt.Sprintf("COPY %d/%d", c.row-term.FirstRow()+1, term.ScreenTop()+height-term.FirstRow())
+	if c.message != "" {
+		return fmt.Sprintf("%s • %s", status, c.message)
+	}
+	return status + " • hjkl/w/b/0/$ move • g/G top/bottom • v/V select • y copy • / ? search • n/N next/prev • q exit"
+}
+
+// clamp keeps the cursor within the available rows, scrolling the view to show it.
+// Output keeps coming in copy mode, dropping old rows from the scrollback.
+func (c *copyMode) clamp(term *terminal.Terminal) {
+	_, height := term.Size()
+	last := term.ScreenTop() + height - 1
+	c.row = min(max(c.row, term.FirstRow()), last)
+	c.anchorRow = max(c.anchorRow, term.FirstRow())
+	if c.row < c.top {
+		c.top = c.row
+	}
+	if c.row >= c.top+height {
+		c.top = c.row - height + 1
+	}
+	c.top = min(max(c.top, term.FirstRow()), term.ScreenTop())
+}
+
+// scroll moves the view by the given number of rows, taking the cursor along when it would go
+// off the view. It reports whether copy mode ends, when scrolling down back to the screen
+// with nothing selected.
+func (c *copyMode) scroll(rows int, term *terminal.Terminal) bool {
+	_, height := term.Size()
+	c.top = min(max(c.top+rows, term.FirstRow()), term.ScreenTop())
+	c.row = min(max(c.row, c.top), c.top+height-1)
+	c.clamp(term)
+	return rows > 0 && c.top == term.ScreenTop() && !c.selecting && c.prompt == nil
+}
+
+// selected reports whether a cell is in the selection
+func (c *copyMode) selected(x, row int) bool {
+	if !c.selecting {
+		return false
+	}
+	fromX, fromRow, toX, toRow := c.bounds()
+	if row < fromRow || row > toRow {
+		return false
+	}
+	if c.lines {
+		return true
+	}
+	return (row > fromRow || x >= fromX) && (row < toRow || x <= toX)
+}
+
+// bounds returns the selection start and end, in order
+func (c *copyMode) bounds() (fromX, fromRow, toX, toRow int) {
+	fromX, fromRow, toX, toRow = c.anchorX, c.anchorRow, c.x, c.row
+	if fromRow > toRow || fromRow == toRow && fromX > toX {
+		fromX, fromRow, toX, toRow = toX, toRow, fromX, fromRow
+	}
+	if c.lines {
+		fromX, toX = 0, endOfLine
+	}
+	return fromX, fromRow, toX, toRow
+}
+
+func (c *copyMode) selection(term *terminal.Terminal) string {
+	return term.Text(c.bounds())
+}
+
+// decorate highlights the selection and the cursor when rendering the terminal
+func (c *copyMode) decorate(x, row int, style terminal.Style) terminal.Style {
+	for _, match := range c.highlights[row] {
+		if x >= match[0] && x < match[1] {
+			style = searchMatchStyle
+			if row == c.row && c.x == match[0] {
+				style = searchCurrentStyle
+			}
+			break
+		}
+	}
+	if c.selected(x, row) {
+		style.Reverse = !style.Reverse
+	}
+	if x == c.x && row == c.row {
+		style.Reverse = !style.Reverse
+		style.Underline = true
+	}
+	return style
+}
+
+func isBlank(cell terminal.Cell) bool {
+	return cell.Content == "" || cell.Content == " "
+}
+
+// nextWord returns the start of the word after x, or the last cell
+func nextWord(line []terminal.Cell, x int) int {
+	for x < len(line) && !isBlank(line[x]) {
+		x++
+	}
+	for x < len(line) && isBlank(line[x]) {
+		x++
+	}
+	return min(x, max(len(line)-1, 0))
+}
+
+// previousWord returns the start of the word before x
+func previousWord(line []terminal.Cell, x int) int {
+	x = min(x, len(line))
+	for x > 0 && isBlank(line[x-1]) {
+		x--
+	}
+	for x > 0 && !isBlank(line[x-1]) {
+		x--
+	}
+	return x
+}
+
+// lineEnd returns the last cell of a line that isn't blank
+func lineEnd(line []terminal.Cell) int {
+	x := len(line) - 1
+	for x > 0 && isBlank(line[x]) {
+		x--
+	}
+	return max(x, 0)
+}
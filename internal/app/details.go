@@ -0,0 +1,150 @@
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/moby/moby/api/types/swarm"
+)
+
+// detailField is a line of the detail pane, a label followed by its value
+type detailField struct {
+	label string
+	value string
+	style lipgloss.Style
+}
+
+func field(label, value string) detailField {
+	return detailField{label: label, value: value, style: TextStyle}
+}
+
+// tableSectionView renders the table, next to the details of the selected item on wide windows
+func (m Model) tableSectionView() string {
+	tableView := TableStyle.Render(m.table.View())
+	width := m.detailPaneWidth()
+	if width == 0 {
+		return tableView
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, tableView, m.detailPaneView(width, lipgloss.Height(tableView)))
+}
+
+// detailPaneView renders the details of the selected service or task in a pane of the given
+// size, borders included
+func (m Model) detailPaneView(width, height int) string {
+	var heading string
+	var fields []detailField
+	switch m.state {
+	case ServicesList:
+		if service, found := selectedItem(&m, m.services, serviceKey); found {
+			heading, fields = service.Name, m.serviceDetails(service)
+		}
+	case TaskList:
+		if task, found := selectedItem(&m, m.tasks, taskKey); found {
+			heading, fields = task.TaskID, m.taskDetails(task)
+		}
+	}
+
+	style := TableStyle.Padding(0, 1)
+	innerWidth := max(width-style.GetHorizontalFrameSize(), 1)
+	innerHeight := max(height-style.GetVerticalFrameSize(), 1)
+	wrap := lipgloss.NewStyle().Width(innerWidth)
+
+	var lines []string
+	if heading != "" {
+		lines = append(lines, strings.Split(wrap.Render(detailHeadingStyle.Render(heading)), "\n")...)
+	}
+	for _, detail := range fields {
+		line := LabelStyle.Render(detail.label+": ") + detail.style.Render(detail.value)
+		lines = append(lines, strings.Split(wrap.Render(line), "\n")...)
+	}
+	if len(lines) > innerHeight {
+		lines = lines[:innerHeight]
+	}
+	return style.
+		Width(innerWidth + style.GetHorizontalPadding()).
+		Height(innerHeight).
+		Render(strings.Join(lines, "\n"))
+}
+
+// serviceDetails summarizes the spec of a service and its resource usage
+func (m Model) serviceDetails(service models.Service) []detailField {
+	stats, found := m.stats.Service(service.ID)
+	fields := []detailField{
+		field("ID", service.ID),
+		field("Stack", service.Stack.Name),
+		field("Image", cmp.Or(service.Image, "-")),
+		field("Mode", cmp.Or(service.Mode, "-")),
+		field("Replicas", fmt.Sprintf("%d/%d", service.RunningTasks, service.DesiredTasks)),
+		field("Ports", joinOrDash(service.Ports)),
+		field("Constraints", joinOrDash(service.Constraints)),
+	}
+	if service.UpdateState != "" {
+		fields = append(fields, field("Update", fmt.Sprintf("%s %s ago", service.UpdateState, formatAge(service.UpdatedAt))))
+	}
+	fields = append(fields,
+		field("CPU", formatCPU(stats, found)),
+		field("Memory", formatMemory(stats, found)),
+	)
+	return append(fields, labelFields(service.Labels)...)
+}
+
+// taskDetails describes the status of a task, its container and the node running it
+func (m Model) taskDetails(task models.Task) []detailField {
+	stats, found := m.stats.Task(task.TaskID)
+	status := string(task.Status)
+	if task.DesiredState != "" && task.DesiredState != task.Status {
+		status += fmt.Sprintf(" (desired %s)", task.DesiredState)
+	}
+	fields := []detailField{
+		field("Status", status),
+		field("Message", cmp.Or(task.Message, "-")),
+	}
+	if task.Err != "" {
+		fields = append(fields, detailField{label: "Error", value: task.Err, style: notificationErrorStyle})
+	}
+	if task.Status == swarm.TaskStateFailed || task.Status == swarm.TaskStateComplete || task.ExitCode != 0 {
+		fields = append(fields, field("Exit code", strconv.Itoa(task.ExitCode)))
+	}
+	fields = append(fields,
+		field("Container", cmp.Or(task.ContainerID, "-")),
+		field("Image", cmp.Or(task.Image, "-")),
+		field("Created", formatAge(task.CreatedAt)+" ago"),
+		field("CPU", formatCPU(stats, found)),
+		field("Memory", formatMemory(stats, found)),
+		field("Net I/O", formatIO(stats.NetRx, stats.NetTx, found)),
+		field("Block I/O", formatIO(stats.BlockRead, stats.BlockWrite, found)),
+		field("Node", cmp.Or(task.Node.Hostname, task.NodeInfo.Name, "-")),
+		field("Host", cmp.Or(task.Node.Host, "-")),
+	)
+	if info := task.NodeInfo; info != (models.NodeInfo{}) {
+		fields = append(fields,
+			field("Role", cmp.Or(info.Role, "-")),
+			field("State", fmt.Sprintf("%s, %s", cmp.Or(info.State, "-"), cmp.Or(info.Availability, "-"))),
+			field("Platform", cmp.Or(info.Platform, "-")),
+			field("Resources", fmt.Sprintf("%d CPUs, %s", info.CPUs, formatBytes(info.Memory))),
+		)
+	}
+	return fields
+}
+
+// labelFields lists labels by name
+func labelFields(labels map[string]string) []detailField {
+	var fields []detailField
+	for _, name := range slices.Sorted(maps.Keys(labels)) {
+		fields = append(fields, field(name, labels[name]))
+	}
+	return fields
+}
+
+func joinOrDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+// execDetailHeight is the height of the pane showing the output of the selected task
+const execDetailHeight = 12
+
+// CommandRunner holds the prompt of the command run in all the tasks of a service, and its results
+type CommandRunner struct {
+	// Target of the prompted command
+	service *models.Service
+
+	input  textinput.Model
+	status string
+	err    error
+
+	// Last run
+	Service     models.Service
+	CommandLine string
+	Results     []models.ExecResult
+
+	// Lines the output of each task is scrolled up from its end, by task ID
+	scrolled map[string]int
+}
+
+// NewCommandRunner creates the command prompt
+func NewCommandRunner() *CommandRunner {
+	input := textinput.New()
+	input.Placeholder = "curl -s localhost/health"
+	input.CharLimit = 500
+	input.Width = 60
+	return &CommandRunner{input: input}
+}
+
+// Prompting reports whether the user is typing a command
+func (r *CommandRunner) Prompting() bool {
+	return r.service != nil
+}
+
+// Prompt asks for the command to run in the tasks of the service, suggesting the last one
+func (r *CommandRunner) Prompt(service models.Service) {
+	r.service = &service
+	r.input.Prompt = fmt.Sprintf("Run in all tasks of %s: ", service.Name)
+	r.input.SetValue(r.CommandLine)
+	r.input.CursorEnd()
+	r.input.Focus()
+	r.SetStatus("", nil)
+}
+
+// ClosePrompt hides the prompt, returning the prompted service and the typed command line
+func (r *CommandRunner) ClosePrompt() (*models.Service, string) {
+	service, value := r.service, strings.TrimSpace(r.input.Value())
+	r.service = nil
+	r.input.Blur()
+	return service, value
+}
+
+// SetStatus displays the state of the last run
+func (r *CommandRunner) SetStatus(status string, err error) {
+	r.status = status
+	r.err = err
+}
+
+// SetResults keeps the results of a run
+func (r *CommandRunner) SetResults(service models.Service, commandLine string, results []models.ExecResult) {
+	r.Service = service
+	r.CommandLine = commandLine
+	r.Results = results
+	r.scrolled = nil
+	failed := 0
+	for _, result := range results {
+		if !result.Succeeded() {
+			failed++
+		}
+	}
+	r.SetStatus(fmt.Sprintf("Ran `%s` in %d tasks of %s, %d failed", commandLine, len(results), service.Name, failed), nil)
+}
+
+// Result returns the result of the task with the given ID
+func (r *CommandRunner) Result(taskID string) (models.ExecResult, bool) {
+	for _, result := range r.Results {
+		if result.Task.TaskID == taskID {
+			return result, true
+		}
+	}
+	return models.ExecResult{}, false
+}
+
+// View renders the prompt and status lines, if any
+func (r *CommandRunner) View() string {
+	lines := []string{}
+	if r.Prompting() {
+		lines = append(lines, r.input.View())
+	}
+	if r.err != nil {
+		lines = append(lines, lipgloss.NewStyle().Foreground(ColorError).Render(fmt.Sprintf("Error: %v", r.err)))
+	} else if r.status != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(ColorSuccess).Render(r.status))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// Scroll moves the output pane of a result by the given number of lines, up when negative
+func (r *CommandRunner) Scroll(result models.ExecResult, lines int) {
+	if r.scrolled == nil {
+		r.scrolled = map[string]int{}
+	}
+	hidden := max(len(resultLines(result))-(execDetailHeight-1), 0)
+	r.scrolled[result.Task.TaskID] = min(max(r.scrolled[result.Task.TaskID]-lines, 0), hidden)
+}
+
+// DetailView renders the output of a task result in a pane of the given size
+func (r *CommandRunner) DetailView(result models.ExecResult, width int) string {
+	heading := fmt.Sprintf("%s on %s • exit %d • %s", result.Task.TaskID, result.Task.Node.Hostname, result.ExitCode, result.Duration.Round(1e6))
+	if result.Err != nil {
+		heading = fmt.Sprintf("%s on %s • failed", result.Task.TaskID, result.Task.Node.Hostname)
+	}
+	if result.Truncated {
+		heading += " • output truncated"
+	}
+	lines := resultLines(result)
+	// Show the end of the output, where errors usually are, unless scrolled up
+	if len(lines) > execDetailHeight-1 {
+		end := len(lines) - r.scrolled[result.Task.TaskID]
+		lines = lines[end-(execDetailHeight-1) : end]
+		if scrolled := r.scrolled[result.Task.TaskID]; scrolled > 0 {
+			heading += fmt.Sprintf(" • %d more lines below", scrolled)
+		}
+	}
+	for len(lines) < execDetailHeight-1 {
+		lines = append(lines, "")
+	}
+	style := lipgloss.NewStyle().MaxWidth(width)
+	return style.Render(lipgloss.JoinVertical(lipgloss.Left, append([]string{execHeadingStyle.Render(heading)}, lines...)...))
+}
+
+// resultLines returns the lines of the error and output of a task result
+func resultLines(result models.ExecResult) []string {
+	lines := []string{}
+	if result.Err != nil {
+		lines = append(lines, execStderrStyle.Render(result.Err.Error()))
+	}
+	for _, line := range outputLines(result.Stdout) {
+		lines = append(lines, line)
+	}
+	for _, line := range outputLines(result.Stderr) {
+		lines = append(lines, execStderrStyle.Render(line))
+	}
+	return lines
+}
+
+// outputLines splits command output into lines, dropping the trailing line break
+// and the control characters that would break the layout
+func outputLines(output []byte) []string {
+	text := strings.TrimRight(string(output), "\n")
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Map(func(r rune) rune {
+			if r == '\t' {
+				return ' '
+			}
+			if r < ' ' || r == 0x7f {
+				return -1
+			}
+			return r
+		}, line)
+	}
+	return lines
+}
+
+// firstOutputLine summarizes the output of a result in a table cell
+func firstOutputLine(result models.ExecResult) string {
+	if result.Err != nil {
+		return result.Err.Error()
+	}
+	for _, output := range [][]byte{result.Stdout, result.Stderr} {
+		for _, line := range outputLines(output) {
+			if strings.TrimSpace(line) != "" {
+				return line
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+type filePromptMode int
+
+const (
+	noFilePrompt filePromptMode = iota
+	downloadFilePrompt
+	uploadFilePrompt
+)
+
+// FileBrowser holds the state of the container filesystem view
+type FileBrowser struct {
+	Task  models.Task
+	Path  string
+	Files []models.FileInfo
+
+	prompt filePromptMode
+	input  textinput.Model
+	status string
+	err    error
+}
+
+// NewFileBrowser creates a file browser for a task container. Its path is set once the first
+// directory is listed, the working directory of the container.
+func NewFileBrowser(task models.Task) *FileBrowser {
+	input := textinput.New()
+	input.CharLimit = 255
+	input.Width = 50
+	return &FileBrowser{
+		Task:  task,
+		input: input,
+	}
+}
+
+// Prompting reports whether the user is typing a download or upload path
+func (f *FileBrowser) Prompting() bool {
+	return f.prompt != noFilePrompt
+}
+
+// PromptDownload asks for the local directory to download the selected file into
+func (f *FileBrowser) PromptDownload() {
+	f.prompt = downloadFilePrompt
+	f.input.Prompt = "Download to: "
+	f.input.SetValue(".")
+	f.input.Focus()
+}
+
+// PromptUpload asks for the local file to upload into the current directory
+func (f *FileBrowser) PromptUpload() {
+	f.prompt = uploadFilePrompt
+	f.input.Prompt = fmt.Sprintf("Upload into %s: ", f.Path)
+	f.input.SetValue("")
+	f.input.Focus()
+}
+
+// ClosePrompt hides the prompt, returning its mode and the typed value
+func (f *FileBrowser) ClosePrompt() (filePromptMode, string) {
+	mode, value := f.prompt, f.input.Value()
+	f.prompt = noFilePrompt
+	f.input.Blur()
+	return mode, value
+}
+
+// SetStatus displays the result of the last operation
+func (f *FileBrowser) SetStatus(status string, err error) {
+	f.status = status
+	f.err = err
+}
+
+// View renders the current location along with the prompt and status lines
+func (f *FileBrowser) View() string {
+	lines := []string{
+		LabelStyle.Render(fmt.Sprintf("%s@%s: ", f.Task.TaskID, f.Task.Node.Hostname)) + TextStyle.Render(f.Path),
+	}
+	if f.Prompting() {
+		lines = append(lines, f.input.View())
+	}
+	if f.err != nil {
+		lines = append(lines, lipgloss.NewStyle().Foreground(ColorError).Render(fmt.Sprintf("Error: %v", f.err)))
+	} else if f.status != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(ColorSuccess).Render(f.status))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,274 @@
+package app
+
+import (
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mendes11/swarm-browser/internal/app/commands"
+	"github.com/mendes11/swarm-browser/internal/config"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/mendes11/swarm-browser/internal/query"
+)
+
+// The filter fields of the views besides stacks, services and tasks, which are shared
+// with the subcommands in the query package
+var (
+	networkFields    = []string{"name", "driver", "scope", "subnet", "attachable", "ingress"}
+	attachmentFields = []string{"service", "task", "node", "address"}
+	mountFields      = []string{"type", "name", "source", "destination", "driver", "mode", "size"}
+	volumeFields     = []string{"name", "driver", "scope", "used", "size"}
+	fileFields       = []string{"name", "type", "size", "mode", "target"}
+	forwardFields    = []string{"local", "remote", "task", "node"}
+	execResultFields = []string{"task", "node", "exit", "duration", "output"}
+	sessionFields    = []string{"id", "session", "cluster", "state", "target"}
+	clusterFields    = []string{"name", "host", "nodes"}
+	bookmarkFields   = []string{"name", "cluster", "stack", "service", "filter"}
+	errorFields      = []string{"id", "time", "cluster", "error", "cause"}
+)
+
+// filterFields returns the fields the filter of the current view can name
+func (m *Model) filterFields() []string {
+	switch m.state {
+	case StacksList:
+		return query.StackFields
+	case ServicesList:
+		return slices.Concat(query.ServiceFields, []string{"cpu", "memory"})
+	case TaskList:
+		return slices.Concat(query.TaskFields, []string{"cpu", "memory"})
+	case NetworksList:
+		return networkFields
+	case NetworkAttachmentsList:
+		return attachmentFields
+	case TaskMountsList:
+		return mountFields
+	case NodeVolumesList:
+		return volumeFields
+	case ContainerFiles:
+		return fileFields
+	case PortForwardsList:
+		return forwardFields
+	case ExecResultsList:
+		return execResultFields
+	case SessionsList:
+		return sessionFields
+	case ClusterSelection:
+		return clusterFields
+	case BookmarksList:
+		return bookmarkFields
+	case ErrorLogList:
+		return errorFields
+	}
+	return nil
+}
+
+// updateFilter parses the filter input as it is typed. The last valid query stays applied
+// while the input has an error, which is displayed next to it.
+func (m *Model) updateFilter() {
+	filter, err := query.Parse(m.filterInput.Value())
+	if err == nil {
+		err = filter.Check(m.filterFields())
+	}
+	m.filterErr = err
+	if err == nil {
+		m.filter = filter
+	}
+}
+
+// filterView renders the filter input, with its error below, if any
+func (m Model) filterView() string {
+	if !m.filterActive && m.filterInput.Value() == "" {
+		return ""
+	}
+	view := m.filterInput.View()
+	if m.filterErr != nil {
+		view += "\n" + lipgloss.NewStyle().Foreground(ColorError).Width(m.width).Render("Invalid filter: "+m.filterErr.Error())
+	}
+	return view
+}
+
+// withStats adds the resource usage of a service or task to its filter fields,
+// in percent and bytes
+func withStats(item query.Item, stats models.ContainerStats, found bool) query.Item {
+	if found {
+		item.Fields["cpu"] = strconv.FormatFloat(stats.CPUPercent, 'f', 1, 64)
+		item.Fields["memory"] = strconv.FormatUint(stats.MemoryUsage, 10)
+	}
+	return item
+}
+
+func (m *Model) serviceItem(service models.Service) query.Item {
+	stats, found := m.stats.Service(service.ID)
+	return withStats(query.ServiceItem(service), stats, found)
+}
+
+func (m *Model) taskItem(task models.Task) query.Item {
+	stats, found := m.stats.Task(task.TaskID)
+	return withStats(query.TaskItem(task), stats, found)
+}
+
+func networkItem(network models.Network) query.Item {
+	return query.Item{
+		Fields: map[string]string{
+			"name":       network.Name,
+			"driver":     network.Driver,
+			"scope":      network.Scope,
+			"subnet":     network.SubnetsString(),
+			"attachable": yesNo(network.Attachable),
+			"ingress":    yesNo(network.Ingress),
+		},
+		Text: []string{network.Name, network.Driver, network.SubnetsString()},
+	}
+}
+
+func attachmentItem(attachment models.NetworkAttachment) query.Item {
+	return query.Item{
+		Fields: map[string]string{
+			"service": attachment.Service.Name,
+			"task":    attachment.TaskID,
+			"node":    attachment.Node.Hostname,
+			"address": attachment.Address,
+		},
+		Text: []string{attachment.Service.Name, attachment.TaskID, attachment.Node.Hostname, attachment.Address},
+	}
+}
+
+func mountItem(mount models.Mount) query.Item {
+	mode := "ro"
+	if mount.RW {
+		mode = "rw"
+	}
+	return query.Item{
+		Fields: map[string]string{
+			"type":        mount.Type,
+			"name":        mount.Name,
+			"source":      mount.Source,
+			"destination": mount.Destination,
+			"driver":      mount.Driver,
+			"mode":        mode,
+			"size":        strconv.FormatInt(mount.Size, 10),
+		},
+		Text: []string{mount.Type, mount.Name, mount.Source, mount.Destination},
+	}
+}
+
+func volumeItem(volume models.Volume) query.Item {
+	return query.Item{
+		Fields: map[string]string{
+			"name":   volume.Name,
+			"driver": volume.Driver,
+			"scope":  volume.Scope,
+			"used":   strconv.FormatInt(volume.RefCount, 10),
+			"size":   strconv.FormatInt(volume.Size, 10),
+		},
+		Text: []string{volume.Name, volume.Driver},
+	}
+}
+
+func fileItem(file models.FileInfo) query.Item {
+	fileType := "file"
+	switch {
+	case file.IsDir():
+		fileType = "dir"
+	case file.LinkTarget != "":
+		fileType = "link"
+	}
+	return query.Item{
+		Fields: map[string]string{
+			"name":   file.Name,
+			"type":   fileType,
+			"size":   strconv.FormatInt(file.Size, 10),
+			"mode":   file.Mode.String(),
+			"target": file.LinkTarget,
+		},
+		Text: []string{file.Name},
+	}
+}
+
+func forwardItem(forward models.PortForward) query.Item {
+	return query.Item{
+		Fields: map[string]string{
+			"local":  strconv.Itoa(forward.LocalPort),
+			"remote": strconv.Itoa(forward.RemotePort),
+			"task":   forward.Task.TaskID,
+			"node":   forward.Task.Node.Hostname,
+		},
+		Text: []string{forward.Task.TaskID, forward.Task.Node.Hostname, forward.String()},
+	}
+}
+
+func execResultItem(result models.ExecResult) query.Item {
+	exitCode := strconv.Itoa(result.ExitCode)
+	if result.Err != nil {
+		exitCode = "error"
+	}
+	output := string(result.Stdout) + string(result.Stderr)
+	return query.Item{
+		Fields: map[string]string{
+			"task":     result.Task.TaskID,
+			"node":     result.Task.Node.Hostname,
+			"exit":     exitCode,
+			"duration": strconv.FormatFloat(result.Duration.Seconds(), 'f', 3, 64),
+			"output":   output,
+		},
+		Text: []string{result.Task.TaskID, result.Task.Node.Hostname, output},
+	}
+}
+
+func (m *Model) sessionItem(session *ContainerSession) query.Item {
+	state := "attached"
+	if info, ok := m.sessions.ExecInfo(session); ok {
+		state = "detached"
+		if info.Exited {
+			state = "exited"
+		}
+	}
+	return query.Item{
+		Fields: map[string]string{
+			"id":      strconv.Itoa(session.ID),
+			"session": session.Label,
+			"cluster": session.Cluster,
+			"state":   state,
+			"target":  session.Title,
+		},
+		Text: []string{session.Label, session.Title, session.Cluster},
+	}
+}
+
+func clusterItem(cluster commands.ClusterTableRow) query.Item {
+	return query.Item{
+		Fields: map[string]string{
+			"name":  cluster.Name,
+			"host":  cluster.Host,
+			"nodes": strconv.Itoa(cluster.NodeCount),
+		},
+		Text: []string{cluster.Name, cluster.Host},
+	}
+}
+
+func bookmarkItem(bookmark config.Bookmark) query.Item {
+	return query.Item{
+		Fields: map[string]string{
+			"name":    bookmark.Name,
+			"cluster": bookmark.Cluster,
+			"stack":   bookmark.Stack,
+			"service": bookmark.Service,
+			"filter":  bookmark.Filter,
+		},
+		Text: []string{bookmark.Name, bookmark.Cluster, bookmark.Stack, bookmark.Service},
+	}
+}
+
+func errorItem(logged notification) query.Item {
+	return query.Item{
+		Fields: map[string]string{
+			"id":      strconv.Itoa(logged.id),
+			"time":    logged.at.Format(time.DateTime),
+			"cluster": logged.cluster,
+			"error":   logged.message,
+			"cause":   rootCause(logged.err),
+		},
+		Text: []string{logged.message, logged.cluster, logged.err.Error()},
+	}
+}
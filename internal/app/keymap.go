@@ -0,0 +1,196 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/mendes11/swarm-browser/internal/config"
+)
+
+// keymapPresets are the bindings applied by the preset of a keymap file, by action name
+var keymapPresets = map[string]map[string][]string{
+	"default": {},
+	"vim": {
+		"back":         {"h", "backspace"},
+		"enter":        {"l", "enter"},
+		"top":          {"g", "home"},
+		"bottom":       {"G", "end"},
+		"page-up":      {"ctrl+b", "pgup"},
+		"page-down":    {"ctrl+f", "pgdown"},
+		"next-session": {"alt+l", "alt+right"},
+		"prev-session": {"alt+h", "alt+left"},
+	},
+	"emacs": {
+		"up":        {"ctrl+p", "up"},
+		"down":      {"ctrl+n", "down"},
+		"page-up":   {"alt+v", "pgup"},
+		"page-down": {"ctrl+v", "pgdown"},
+		"top":       {"alt+<", "home"},
+		"bottom":    {"alt+>", "end"},
+		"back":      {"ctrl+b", "backspace"},
+		"enter":     {"ctrl+f", "enter"},
+		"cancel":    {"ctrl+g", "esc"},
+		"filter":    {"ctrl+s", "/"},
+	},
+}
+
+// KeymapPresets returns the names of the presets a keymap file can start from
+func KeymapPresets() []string {
+	return slices.Sorted(maps.Keys(keymapPresets))
+}
+
+// NewAppKeyMap returns the default keybindings with the preset and the overrides of a keymap
+// file applied, failing when an action is unknown or a key is bound to several actions of a view
+func NewAppKeyMap(keymap config.Keymap) (AppKeyMap, error) {
+	keys := DefaultAppKeyMap()
+	if keymap.Preset != "" {
+		preset, found := keymapPresets[keymap.Preset]
+		if !found {
+			return keys, fmt.Errorf("unknown preset %q, expected one of %s", keymap.Preset, strings.Join(KeymapPresets(), ", "))
+		}
+		for name, bound := range preset {
+			keys.rebind(name, bound)
+		}
+	}
+	for name, bound := range keymap.Keys {
+		if !keys.rebind(name, bound) {
+			return keys, fmt.Errorf("unknown action %q, expected one of %s", name, strings.Join(slices.Sorted(maps.Keys(keys.actions())), ", "))
+		}
+	}
+	return keys, keys.Validate()
+}
+
+// actions returns the bindings by the action names used in keymap files
+func (k *AppKeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":              &k.Table.LineUp,
+		"down":            &k.Table.LineDown,
+		"page-up":         &k.Table.PageUp,
+		"page-down":       &k.Table.PageDown,
+		"half-page-up":    &k.Table.HalfPageUp,
+		"half-page-down":  &k.Table.HalfPageDown,
+		"top":             &k.Table.GotoTop,
+		"bottom":          &k.Table.GotoBottom,
+		"back":            &k.Back,
+		"go-forward":      &k.GoForward,
+		"refresh":         &k.Refresh,
+		"cluster":         &k.Cluster,
+		"connect":         &k.Connect,
+		"networks":        &k.Networks,
+		"mounts":          &k.Mounts,
+		"volumes":         &k.Volumes,
+		"files":           &k.Files,
+		"download":        &k.Download,
+		"upload":          &k.Upload,
+		"forward":         &k.Forward,
+		"forwards":        &k.Forwards,
+		"stop":            &k.Stop,
+		"filter":          &k.Filter,
+		"sort":            &k.Sort,
+		"reverse":         &k.Reverse,
+		"exec":            &k.Exec,
+		"debug":           &k.Debug,
+		"enter":           &k.Enter,
+		"cancel":          &k.Cancel,
+		"sessions":        &k.Sessions,
+		"detach-session":  &k.DetachSession,
+		"close-session":   &k.CloseSession,
+		"next-session":    &k.NextSession,
+		"prev-session":    &k.PrevSession,
+		"go-to-session":   &k.GoToSession,
+		"detach":          &k.Detach,
+		"copy-mode":       &k.CopyMode,
+		"bookmark":        &k.Bookmark,
+		"bookmarks":       &k.Bookmarks,
+		"delete-bookmark": &k.DeleteBookmark,
+		"error-log":       &k.ErrorLog,
+		"retry":           &k.Retry,
+		"help":            &k.Help,
+		"quit":            &k.Quit,
+	}
+}
+
+// rebind replaces the keys of an action, and their help, reporting whether the action exists.
+// An action without keys is disabled.
+func (k *AppKeyMap) rebind(name string, keys []string) bool {
+	binding, found := k.actions()[name]
+	if !found {
+		return false
+	}
+	binding.SetKeys(keys...)
+	binding.SetHelp(keysHelp(keys), binding.Help().Desc)
+	binding.SetEnabled(len(keys) > 0)
+	return true
+}
+
+// arrowKeys are the arrow keys, as displayed in the help
+var arrowKeys = map[string]string{"up": "↑", "down": "↓", "left": "←", "right": "→"}
+
+// keysHelp renders keys the way the help does, e.g. ↑/k or alt+→
+func keysHelp(keys []string) string {
+	help := make([]string, len(keys))
+	for i, k := range keys {
+		modifiers, name := "", k
+		if index := strings.LastIndex(k, "+"); index >= 0 && index < len(k)-1 {
+			modifiers, name = k[:index+1], k[index+1:]
+		}
+		if arrow, found := arrowKeys[name]; found {
+			name = arrow
+		}
+		help[i] = modifiers + name
+	}
+	return strings.Join(help, "/")
+}
+
+// keymapViews are the views checked for conflicting keys
+var keymapViews = []ViewState{
+	StacksList, ServicesList, TaskList, NetworksList, NetworkAttachmentsList, TaskMountsList,
+	NodeVolumesList, ContainerFiles, PortForwardsList, SessionsList, ExecResultsList,
+	ClusterSelection, BookmarksList, ErrorLogList,
+}
+
+// Validate reports a key bound to several actions of the same view. The actions of a view
+// are the ones of its help along with the table navigation, except for the session keys,
+// which are only handled while a session is displayed.
+func (k AppKeyMap) Validate() error {
+	sessionKeys := []key.Binding{k.Detach, k.CopyMode, k.NextSession, k.PrevSession, k.GoToSession}
+	for _, view := range keymapViews {
+		k.SetEnterHelpText(view)
+		bindings := slices.Concat(k.FullHelpForView(view)...)
+		bindings = append(bindings, k.Table.HalfPageUp, k.Table.HalfPageDown, k.Cancel)
+		bindings = slices.DeleteFunc(bindings, func(binding key.Binding) bool {
+			return slices.ContainsFunc(sessionKeys, func(sessionKey key.Binding) bool {
+				return sessionKey.Help().Desc == binding.Help().Desc
+			})
+		})
+		if err := conflicts(bindings); err != nil {
+			return fmt.Errorf("%w in the %s view", err, view)
+		}
+	}
+	if err := conflicts(sessionKeys); err != nil {
+		return fmt.Errorf("%w in container sessions", err)
+	}
+	return nil
+}
+
+// conflicts returns an error naming the first key of several bindings, told apart by their
+// help. Bindings listed twice aren't conflicting.
+func conflicts(bindings []key.Binding) error {
+	bound := make(map[string]string)
+	for _, binding := range bindings {
+		if !binding.Enabled() {
+			continue
+		}
+		action := binding.Help().Desc
+		for _, k := range binding.Keys() {
+			if other, found := bound[k]; found && other != action {
+				return fmt.Errorf("%s is bound to both %q and %q", k, other, action)
+			}
+			bound[k] = action
+		}
+	}
+	return nil
+}
@@ -11,13 +11,46 @@ type AppKeyMap struct {
 	Table table.KeyMap
 
 	// App-specific actions
-	Back    key.Binding
-	Refresh key.Binding
-	Cluster key.Binding
-	Connect key.Binding
-	Filter  key.Binding
-	Enter   key.Binding
-	Cancel  key.Binding
+	Back      key.Binding
+	GoForward key.Binding
+	Refresh   key.Binding
+	Cluster   key.Binding
+	Connect   key.Binding
+	Networks  key.Binding
+	Mounts    key.Binding
+	Volumes   key.Binding
+	Files     key.Binding
+	Download  key.Binding
+	Upload    key.Binding
+	Forward   key.Binding
+	Forwards  key.Binding
+	Stop      key.Binding
+	Filter    key.Binding
+	Sort      key.Binding
+	Reverse   key.Binding
+	Exec      key.Binding
+	Debug     key.Binding
+	Enter     key.Binding
+	Cancel    key.Binding
+
+	// Container sessions
+	Sessions      key.Binding
+	DetachSession key.Binding
+	CloseSession  key.Binding
+	NextSession   key.Binding
+	PrevSession   key.Binding
+	GoToSession   key.Binding
+	Detach        key.Binding
+	CopyMode      key.Binding
+
+	// Bookmarks
+	Bookmark       key.Binding
+	Bookmarks      key.Binding
+	DeleteBookmark key.Binding
+
+	// Errors
+	ErrorLog key.Binding
+	Retry    key.Binding
 
 	// Application
 	Help key.Binding
@@ -27,14 +60,17 @@ type AppKeyMap struct {
 // DefaultAppKeyMap returns the default keybindings combining table and app keys
 func DefaultAppKeyMap() AppKeyMap {
 	return AppKeyMap{
-		// Use the default table keymap
-		Table: table.DefaultKeyMap(),
+		Table: defaultTableKeyMap(),
 
 		// App-specific actions
 		Back: key.NewBinding(
 			key.WithKeys("backspace"),
 			key.WithHelp("backspace", "back"),
 		),
+		GoForward: key.NewBinding(
+			key.WithKeys("]"),
+			key.WithHelp("]", "forward"),
+		),
 		Cancel: key.NewBinding(
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "cancel"),
@@ -55,10 +91,117 @@ func DefaultAppKeyMap() AppKeyMap {
 			key.WithKeys("a"),
 			key.WithHelp("a", "connect"),
 		),
+		Networks: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "networks"),
+		),
+		Mounts: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "mounts"),
+		),
+		Volumes: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "node volumes"),
+		),
+		Files: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "files"),
+		),
+		Download: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "download"),
+		),
+		Upload: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "upload"),
+		),
+		Forward: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "port forward"),
+		),
+		Forwards: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "port forwards"),
+		),
+		Stop: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "stop forward"),
+		),
 		Filter: key.NewBinding(
 			key.WithKeys("/"),
 			key.WithHelp("/", "filter"),
 		),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort column"),
+		),
+		Reverse: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "sort order"),
+		),
+		Exec: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "run in all tasks"),
+		),
+		Debug: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "debug sidecar"),
+		),
+		Sessions: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "sessions"),
+		),
+		DetachSession: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "detach session"),
+		),
+		CloseSession: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "close session"),
+		),
+		NextSession: key.NewBinding(
+			key.WithKeys("alt+right"),
+			key.WithHelp("alt+→", "next session"),
+		),
+		PrevSession: key.NewBinding(
+			key.WithKeys("alt+left"),
+			key.WithHelp("alt+←", "previous session"),
+		),
+		// The nth key of GoToSession shows the nth session
+		GoToSession: key.NewBinding(
+			key.WithKeys("alt+1", "alt+2", "alt+3", "alt+4", "alt+5", "alt+6", "alt+7", "alt+8", "alt+9"),
+			key.WithHelp("alt+1…9", "go to session"),
+		),
+		Detach: key.NewBinding(
+			key.WithKeys("ctrl+\\"),
+			key.WithHelp("ctrl+\\", "back to browser"),
+		),
+		CopyMode: key.NewBinding(
+			key.WithKeys("ctrl+]"),
+			key.WithHelp("ctrl+]", "copy mode"),
+		),
+		Bookmark: key.NewBinding(
+			key.WithKeys("B"),
+			key.WithHelp("B", "bookmark"),
+		),
+		Bookmarks: key.NewBinding(
+			key.WithKeys("'"),
+			key.WithHelp("'", "bookmarks"),
+		),
+		DeleteBookmark: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "delete bookmark"),
+		),
+
+		// Errors
+		ErrorLog: key.NewBinding(
+			key.WithKeys("!"),
+			key.WithHelp("!", "error log"),
+		),
+		Retry: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "retry"),
+		),
 
 		// Application
 		Help: key.NewBinding(
@@ -72,6 +215,19 @@ func DefaultAppKeyMap() AppKeyMap {
 	}
 }
 
+// defaultTableKeyMap returns the table keymap of bubbles without the letters of the actions,
+// f, u and d, which they would shadow in the views of these actions
+func defaultTableKeyMap() table.KeyMap {
+	keys := table.DefaultKeyMap()
+	keys.PageDown.SetKeys("pgdown", " ")
+	keys.PageDown.SetHelp("pgdn/space", "page down")
+	keys.HalfPageUp.SetKeys("ctrl+u")
+	keys.HalfPageUp.SetHelp("ctrl+u", "½ page up")
+	keys.HalfPageDown.SetKeys("ctrl+d")
+	keys.HalfPageDown.SetHelp("ctrl+d", "½ page down")
+	return keys
+}
+
 // ShortHelp returns keybindings to be shown in the mini help view
 func (k AppKeyMap) ShortHelp() []key.Binding {
 	// Show the most important keys in compact view
@@ -94,6 +250,18 @@ func (k AppKeyMap) ShortHelpForView(viewState ViewState) []key.Binding {
 			k.Table.LineDown,
 			k.Enter,
 			k.Cluster,
+			k.Networks,
+			k.Bookmarks,
+			k.Refresh,
+			k.Help,
+			k.Quit,
+		}
+	case NetworksList, NetworkAttachmentsList:
+		return []key.Binding{
+			k.Table.LineUp,
+			k.Table.LineDown,
+			k.Enter,
+			k.Back,
 			k.Refresh,
 			k.Help,
 			k.Quit,
@@ -105,6 +273,8 @@ func (k AppKeyMap) ShortHelpForView(viewState ViewState) []key.Binding {
 			k.Enter,
 			k.Back,
 			k.Cluster,
+			k.Forward,
+			k.Exec,
 			k.Refresh,
 			k.Help,
 			k.Quit,
@@ -117,6 +287,69 @@ func (k AppKeyMap) ShortHelpForView(viewState ViewState) []key.Binding {
 			k.Back,
 			k.Cluster,
 			k.Connect,
+			k.Debug,
+			k.Mounts,
+			k.Files,
+			k.Forward,
+			k.Help,
+			k.Quit,
+		}
+	case ContainerFiles:
+		return []key.Binding{
+			k.Table.LineUp,
+			k.Table.LineDown,
+			k.Enter,
+			k.Back,
+			k.Download,
+			k.Upload,
+			k.Help,
+			k.Quit,
+		}
+	case TaskMountsList:
+		return []key.Binding{
+			k.Table.LineUp,
+			k.Table.LineDown,
+			k.Back,
+			k.Volumes,
+			k.Refresh,
+			k.Help,
+			k.Quit,
+		}
+	case NodeVolumesList:
+		return []key.Binding{
+			k.Table.LineUp,
+			k.Table.LineDown,
+			k.Back,
+			k.Refresh,
+			k.Help,
+			k.Quit,
+		}
+	case PortForwardsList:
+		return []key.Binding{
+			k.Table.LineUp,
+			k.Table.LineDown,
+			k.Back,
+			k.Stop,
+			k.Help,
+			k.Quit,
+		}
+	case ExecResultsList:
+		return []key.Binding{
+			k.Table.LineUp,
+			k.Table.LineDown,
+			k.Back,
+			k.Refresh,
+			k.Help,
+			k.Quit,
+		}
+	case SessionsList:
+		return []key.Binding{
+			k.Table.LineUp,
+			k.Table.LineDown,
+			k.Enter,
+			k.Back,
+			k.DetachSession,
+			k.CloseSession,
 			k.Help,
 			k.Quit,
 		}
@@ -129,6 +362,25 @@ func (k AppKeyMap) ShortHelpForView(viewState ViewState) []key.Binding {
 			k.Help,
 			k.Quit,
 		}
+	case BookmarksList:
+		return []key.Binding{
+			k.Table.LineUp,
+			k.Table.LineDown,
+			k.Enter,
+			k.Back,
+			k.DeleteBookmark,
+			k.Help,
+			k.Quit,
+		}
+	case ErrorLogList:
+		return []key.Binding{
+			k.Table.LineUp,
+			k.Table.LineDown,
+			k.Back,
+			k.Retry,
+			k.Help,
+			k.Quit,
+		}
 	default:
 		return k.ShortHelp()
 	}
@@ -150,9 +402,9 @@ func (k AppKeyMap) FullHelp() [][]key.Binding {
 			k.Table.GotoBottom,
 		},
 		// App actions
-		{k.Enter, k.Back, k.Refresh, k.Connect, k.Filter},
+		{k.Enter, k.Back, k.GoForward, k.Refresh, k.Connect, k.Filter},
 		// App controls
-		{k.Help, k.Quit},
+		{k.Retry, k.ErrorLog, k.Help, k.Quit},
 	}
 }
 
@@ -160,7 +412,30 @@ func (k AppKeyMap) FullHelp() [][]key.Binding {
 func (k AppKeyMap) FullHelpForView(viewState ViewState) [][]key.Binding {
 	switch viewState {
 	case StacksList:
-		// In stacks list, don't show back, but show connect
+		// The stacks list is the first view, back only returns e.g. to the bookmarks it was opened from
+		return [][]key.Binding{
+			// Table navigation
+			{
+				k.Table.LineUp,
+				k.Table.LineDown,
+				k.Table.PageUp,
+				k.Table.PageDown,
+			},
+			// More table navigation
+			{
+				k.Table.GotoTop,
+				k.Table.GotoBottom,
+			},
+			// App actions
+			{k.Enter, k.Back, k.GoForward, k.Cluster, k.Networks, k.Forwards, k.Sessions, k.Refresh, k.Connect, k.Filter},
+			// Bookmarks
+			{k.Bookmark, k.Bookmarks},
+			// Sorting
+			{k.Sort, k.Reverse},
+			// App controls
+			{k.Retry, k.ErrorLog, k.Help, k.Quit},
+		}
+	case NetworksList, NetworkAttachmentsList:
 		return [][]key.Binding{
 			// Table navigation
 			{
@@ -174,10 +449,14 @@ func (k AppKeyMap) FullHelpForView(viewState ViewState) [][]key.Binding {
 				k.Table.GotoTop,
 				k.Table.GotoBottom,
 			},
-			// App actions - no back in stacks list
-			{k.Enter, k.Cluster, k.Refresh, k.Connect, k.Filter},
+			// App actions
+			{k.Enter, k.Back, k.GoForward, k.Refresh, k.Filter},
+			// Bookmarks
+			{k.Bookmark, k.Bookmarks},
+			// Sorting
+			{k.Sort, k.Reverse},
 			// App controls
-			{k.Help, k.Quit},
+			{k.Retry, k.ErrorLog, k.Help, k.Quit},
 		}
 	case ServicesList:
 		// In services list, show back but not connect
@@ -195,9 +474,15 @@ func (k AppKeyMap) FullHelpForView(viewState ViewState) [][]key.Binding {
 				k.Table.GotoBottom,
 			},
 			// App actions - show back but not connect
-			{k.Enter, k.Back, k.Cluster, k.Refresh, k.Filter},
+			{k.Enter, k.Back, k.GoForward, k.Cluster, k.Refresh, k.Exec, k.Filter},
+			// Port forwarding and sessions
+			{k.Forward, k.Forwards, k.Sessions},
+			// Bookmarks
+			{k.Bookmark, k.Bookmarks},
+			// Sorting
+			{k.Sort, k.Reverse},
 			// App controls
-			{k.Help, k.Quit},
+			{k.Retry, k.ErrorLog, k.Help, k.Quit},
 		}
 	case TaskList:
 		// In task list, show both back and connect
@@ -215,9 +500,140 @@ func (k AppKeyMap) FullHelpForView(viewState ViewState) [][]key.Binding {
 				k.Table.GotoBottom,
 			},
 			// App actions
-			{k.Enter, k.Back, k.Cluster, k.Refresh, k.Connect, k.Filter},
+			{k.Enter, k.Back, k.GoForward, k.Cluster, k.Refresh, k.Connect, k.Filter},
+			// Task inspection
+			{k.Mounts, k.Volumes, k.Files, k.Debug},
+			// Port forwarding and sessions
+			{k.Forward, k.Forwards, k.Sessions},
+			// Bookmarks
+			{k.Bookmark, k.Bookmarks},
+			// Sorting
+			{k.Sort, k.Reverse},
+			// App controls
+			{k.Retry, k.ErrorLog, k.Help, k.Quit},
+		}
+	case ContainerFiles:
+		return [][]key.Binding{
+			// Table navigation
+			{
+				k.Table.LineUp,
+				k.Table.LineDown,
+				k.Table.PageUp,
+				k.Table.PageDown,
+			},
+			// More table navigation
+			{
+				k.Table.GotoTop,
+				k.Table.GotoBottom,
+			},
+			// App actions
+			{k.Enter, k.Back, k.GoForward, k.Refresh, k.Filter},
+			// File transfer
+			{k.Download, k.Upload},
+			// Bookmarks
+			{k.Bookmark, k.Bookmarks},
+			// Sorting
+			{k.Sort, k.Reverse},
 			// App controls
-			{k.Help, k.Quit},
+			{k.Retry, k.ErrorLog, k.Help, k.Quit},
+		}
+	case TaskMountsList, NodeVolumesList:
+		actions := []key.Binding{k.Back, k.GoForward, k.Refresh, k.Filter}
+		if viewState == TaskMountsList {
+			actions = append(actions, k.Volumes)
+		}
+		return [][]key.Binding{
+			// Table navigation
+			{
+				k.Table.LineUp,
+				k.Table.LineDown,
+				k.Table.PageUp,
+				k.Table.PageDown,
+			},
+			// More table navigation
+			{
+				k.Table.GotoTop,
+				k.Table.GotoBottom,
+			},
+			// App actions
+			actions,
+			// Bookmarks
+			{k.Bookmark, k.Bookmarks},
+			// Sorting
+			{k.Sort, k.Reverse},
+			// App controls
+			{k.Retry, k.ErrorLog, k.Help, k.Quit},
+		}
+	case ExecResultsList:
+		return [][]key.Binding{
+			// Table navigation
+			{
+				k.Table.LineUp,
+				k.Table.LineDown,
+				k.Table.PageUp,
+				k.Table.PageDown,
+			},
+			// More table navigation
+			{
+				k.Table.GotoTop,
+				k.Table.GotoBottom,
+			},
+			// App actions
+			{k.Back, k.GoForward, k.Refresh, k.Filter},
+			// Bookmarks
+			{k.Bookmark, k.Bookmarks},
+			// Sorting
+			{k.Sort, k.Reverse},
+			// App controls
+			{k.Retry, k.ErrorLog, k.Help, k.Quit},
+		}
+	case PortForwardsList:
+		return [][]key.Binding{
+			// Table navigation
+			{
+				k.Table.LineUp,
+				k.Table.LineDown,
+				k.Table.PageUp,
+				k.Table.PageDown,
+			},
+			// More table navigation
+			{
+				k.Table.GotoTop,
+				k.Table.GotoBottom,
+			},
+			// App actions
+			{k.Back, k.GoForward, k.Stop, k.Refresh, k.Filter},
+			// Bookmarks
+			{k.Bookmark, k.Bookmarks},
+			// Sorting
+			{k.Sort, k.Reverse},
+			// App controls
+			{k.Retry, k.ErrorLog, k.Help, k.Quit},
+		}
+	case SessionsList:
+		return [][]key.Binding{
+			// Table navigation
+			{
+				k.Table.LineUp,
+				k.Table.LineDown,
+				k.Table.PageUp,
+				k.Table.PageDown,
+			},
+			// More table navigation
+			{
+				k.Table.GotoTop,
+				k.Table.GotoBottom,
+			},
+			// App actions
+			{k.Enter, k.Back, k.GoForward, k.DetachSession, k.CloseSession, k.Filter},
+			// Displayed session
+			{k.NextSession, k.PrevSession, k.GoToSession, k.Detach, k.CopyMode},
+			// Bookmarks
+			{k.Bookmark, k.Bookmarks},
+			// Sorting
+			{k.Sort, k.Reverse},
+			// App controls
+			{k.Retry, k.ErrorLog, k.Help, k.Quit},
 		}
 	case ClusterSelection:
 		// In cluster selection, show enter and back/cancel
@@ -235,9 +651,53 @@ func (k AppKeyMap) FullHelpForView(viewState ViewState) [][]key.Binding {
 				k.Table.GotoBottom,
 			},
 			// App actions - just select and cancel
-			{k.Enter, k.Back, k.Cancel},
+			{k.Enter, k.Back, k.GoForward, k.Cancel},
+			// Sorting
+			{k.Sort, k.Reverse},
+			// App controls
+			{k.Retry, k.ErrorLog, k.Help, k.Quit},
+		}
+	case BookmarksList:
+		return [][]key.Binding{
+			// Table navigation
+			{
+				k.Table.LineUp,
+				k.Table.LineDown,
+				k.Table.PageUp,
+				k.Table.PageDown,
+			},
+			// More table navigation
+			{
+				k.Table.GotoTop,
+				k.Table.GotoBottom,
+			},
+			// App actions
+			{k.Enter, k.Back, k.GoForward, k.DeleteBookmark, k.Filter},
+			// Sorting
+			{k.Sort, k.Reverse},
+			// App controls
+			{k.Retry, k.ErrorLog, k.Help, k.Quit},
+		}
+	case ErrorLogList:
+		return [][]key.Binding{
+			// Table navigation
+			{
+				k.Table.LineUp,
+				k.Table.LineDown,
+				k.Table.PageUp,
+				k.Table.PageDown,
+			},
+			// More table navigation
+			{
+				k.Table.GotoTop,
+				k.Table.GotoBottom,
+			},
+			// App actions
+			{k.Back, k.GoForward, k.Filter},
+			// Sorting
+			{k.Sort, k.Reverse},
 			// App controls
-			{k.Help, k.Quit},
+			{k.Retry, k.Help, k.Quit},
 		}
 	default:
 		return k.FullHelp()
@@ -255,6 +715,14 @@ func (k *AppKeyMap) SetEnterHelpText(viewState ViewState) {
 		k.Enter.SetHelp("enter", "attach to container")
 	case ClusterSelection:
 		k.Enter.SetHelp("enter", "select cluster")
+	case NetworksList:
+		k.Enter.SetHelp("enter", "view attachments")
+	case ContainerFiles:
+		k.Enter.SetHelp("enter", "open directory")
+	case SessionsList:
+		k.Enter.SetHelp("enter", "open session")
+	case BookmarksList:
+		k.Enter.SetHelp("enter", "open bookmark")
 	default:
 		k.Enter.SetHelp("enter", "select")
 	}
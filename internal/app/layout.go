@@ -0,0 +1,125 @@
+package app
+
+import "github.com/charmbracelet/bubbles/table"
+
+// cellPadding is the width the table adds around the cells of each column
+const cellPadding = 2
+
+// splitLayoutWidth is the narrowest window showing the details of the selected item next to
+// the list
+const splitLayoutWidth = 140
+
+// column describes a column of a table, fitted to the width of the window by fitColumns
+type column struct {
+	title string
+	// width is the preferred width of the column, and min the narrowest it shrinks to before
+	// columns are hidden, width when unset
+	width int
+	min   int
+	// flex columns take the width left by the others, e.g. the names
+	flex bool
+	// hide orders the columns hidden when the window is too narrow, the highest first. The
+	// columns without one are always displayed.
+	hide int
+}
+
+func (c column) minWidth() int {
+	if c.min > 0 {
+		return c.min
+	}
+	return c.width
+}
+
+// fitColumns sizes the columns of a table to width: the columns shrink down to their minimum
+// width, then are hidden by priority until the others fit, and the flex columns share what is
+// left. Hidden columns have a zero width, which the table skips, so that the rows keep their
+// cells and can still be sorted by them.
+func fitColumns(width int, columns []column) []table.Column {
+	visible := make([]bool, len(columns))
+	needed := 0
+	for i, c := range columns {
+		visible[i] = true
+		needed += c.minWidth() + cellPadding
+	}
+	for needed > width {
+		hidden := -1
+		for i, c := range columns {
+			if visible[i] && c.hide > 0 && (hidden < 0 || c.hide > columns[hidden].hide) {
+				hidden = i
+			}
+		}
+		if hidden < 0 {
+			break
+		}
+		visible[hidden] = false
+		needed -= columns[hidden].minWidth() + cellPadding
+	}
+
+	widths := make([]int, len(columns))
+	left := width - needed
+	var flexes []int
+	for i, c := range columns {
+		if !visible[i] {
+			continue
+		}
+		widths[i] = c.minWidth()
+		if c.flex {
+			flexes = append(flexes, i)
+		}
+	}
+	// The fixed columns grow back to their preferred width from the left, the flex ones
+	// sharing the rest
+	for i, c := range columns {
+		if visible[i] && !c.flex && left > 0 {
+			grow := min(c.width-widths[i], left)
+			widths[i] += grow
+			left -= grow
+		}
+	}
+	for n, i := range flexes {
+		if left > 0 {
+			share := left / (len(flexes) - n)
+			widths[i] += share
+			left -= share
+		}
+	}
+	// Still too wide, even with the columns that can be hidden gone: the widest ones shrink
+	for left < 0 {
+		widest := 0
+		for i := range widths {
+			if widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= 1 {
+			break
+		}
+		widths[widest]--
+		left++
+	}
+
+	fitted := make([]table.Column, len(columns))
+	for i, c := range columns {
+		fitted[i] = table.Column{Title: c.title, Width: widths[i]}
+	}
+	return fitted
+}
+
+// contentWidth is the width of the lines of the browser window, within its margins
+func (m Model) contentWidth() int {
+	return m.width - 4
+}
+
+// tableWidth is the width of the cells of the table, next to the detail pane if displayed
+func (m Model) tableWidth() int {
+	return m.contentWidth() - m.detailPaneWidth()
+}
+
+// detailPaneWidth returns the width of the pane showing the selected service or task next to
+// the list, border included, or zero when the window is too narrow or the view has no details
+func (m Model) detailPaneWidth() int {
+	if m.width < splitLayoutWidth || (m.state != ServicesList && m.state != TaskList) {
+		return 0
+	}
+	return min(max(m.width/3, 40), 60)
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFitColumns(t *testing.T) {
+	services := []column{
+		{title: "Name", width: 20, min: 10, flex: true},
+		{title: "Image", width: 30, min: 15, hide: 2},
+		{title: "Status", width: 10},
+		{title: "Age", width: 8, hide: 1},
+	}
+	shared := []column{
+		{title: "A", width: 10, flex: true},
+		{title: "B", width: 10, flex: true},
+		{title: "C", width: 5},
+	}
+
+	tests := []struct {
+		name    string
+		width   int
+		columns []column
+		widths  []int
+	}{
+		{"flex column takes the rest", 200, services, []int{144, 30, 10, 8}},
+		{"fixed columns grow back first", 60, services, []int{10, 24, 10, 8}},
+		{"highest priority hidden first", 40, services, []int{16, 0, 10, 8}},
+		{"several columns hidden", 30, services, []int{16, 0, 10, 0}},
+		{"widest columns shrink once nothing can be hidden", 20, services, []int{8, 0, 8, 0}},
+		{"flex columns share the rest", 40, shared, []int{14, 15, 5}},
+		{"columns shrink down to a width of one", 4, shared, []int{1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fitted := fitColumns(tt.width, tt.columns)
+			widths := make([]int, len(fitted))
+			total := 0
+			for i, c := range fitted {
+				widths[i] = c.Width
+				if c.Title != tt.columns[i].title {
+					t.Errorf("Expected column %d to keep its title %q, got %q", i, tt.columns[i].title, c.Title)
+				}
+				if c.Width > 0 {
+					total += c.Width + cellPadding
+				}
+			}
+			if !slices.Equal(widths, tt.widths) {
+				t.Errorf("Expected widths %v, got %v", tt.widths, widths)
+			}
+			// Columns only overflow once they are all down to a width of one
+			if total > tt.width && slices.Max(widths) > 1 {
+				t.Errorf("Expected the columns to fit in %d, got %d", tt.width, total)
+			}
+		})
+	}
+}
@@ -1,18 +1,29 @@
 package app
 
 import (
-	"strings"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"slices"
+	"strconv"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mendes11/swarm-browser/internal/app/commands"
+	"github.com/mendes11/swarm-browser/internal/asciicast"
 	"github.com/mendes11/swarm-browser/internal/config"
 	"github.com/mendes11/swarm-browser/internal/core"
 	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/mendes11/swarm-browser/internal/query"
 )
 
 type Model struct {
@@ -26,25 +37,64 @@ type Model struct {
 	width       int
 	height      int
 
+	// What is remembered from one run to the next, e.g. the sort order of each view
+	userState *config.State
+
 	// Filter state
 	filterActive bool
 	filterInput  textinput.Model
+	filter       query.Query
+	filterErr    error
+
+	// Navigation state: the history of the views, and what each kind of view listed last
+	nav      navigation
+	loaded   map[ViewState]string
+	stacks   []models.Stack
+	services []models.Service
+	tasks    []models.Task
+
+	// Networks state
+	networks           []models.Network
+	networkAttachments []models.NetworkAttachment
+
+	// Mounts and volumes state
+	taskMounts  []models.Mount
+	nodeVolumes []models.Volume
+
+	// Resource usage of the displayed services / tasks
+	stats *statsMonitor
+
+	// Container filesystem browser
+	files *FileBrowser
+
+	// Port forwarding state
+	forwarder    *PortForwarder
+	portForwards []models.PortForward
 
-	// Navigation state
-	stacks          []models.Stack
-	selectedStack   *models.Stack
-	services        []models.Service
-	selectedService *models.Service
-	tasks           []models.Task
+	// Commands run in all the tasks of a service
+	runner *CommandRunner
+
+	// Bookmarked locations, and the one being opened while its stacks and services are listed
+	bookmarker      *Bookmarker
+	openingBookmark *config.Bookmark
 
 	// Cluster selection state
 	clustersForDisplay []commands.ClusterTableRow
-	previousState      ViewState
 	currentClusterName string
 
-	// Container session
-	containerView *ContainerView
-	containerConn core.ContainerConnection
+	// Container sessions
+	sessions  *SessionList
+	shells    *core.ShellProbe
+	attachErr error
+
+	// Last click on a row, telling double-clicks apart
+	lastClick rowClick
+
+	// Notifications of the status bar, and the log of the errors
+	notifier *Notifier
+
+	// Requests to the cluster in flight
+	requests *requestTracker
 }
 
 var _ tea.Model = Model{}
@@ -60,35 +110,75 @@ func New(conf config.Config) Model {
 		clusterInfo.Cluster = initialCluster
 	}
 
-	// Initialize keys first so we can pass the table keymap
-	keys := DefaultAppKeyMap()
+	// Initialize keys first so we can pass the table keymap. The keymap is checked at startup,
+	// falling back to the defaults here.
+	keys, err := NewAppKeyMap(conf.Keymap)
+	if err != nil {
+		log.Printf("Invalid keymap, using the default keys: %v\n", err)
+		keys = DefaultAppKeyMap()
+	}
 
 	// Initialize filter input
 	filterInput := textinput.New()
-	filterInput.Placeholder = "Type to filter..."
+	filterInput.Placeholder = "Type to filter, e.g. status:running node:worker-* or replicas<desired"
 	filterInput.Prompt = "/ "
 	filterInput.CharLimit = 100
 	filterInput.Width = 50
 
+	// The theme is checked at startup, falling back to the dark one here
+	theme, err := LoadTheme(conf.Theme, conf.Themes)
+	if err != nil {
+		log.Printf("Invalid theme, using the dark one: %v\n", err)
+		theme = DarkTheme
+	}
+	ApplyTheme(theme)
+
+	// A state file that failed to load is reported in the status bar once started
+	userState, err := config.LoadState(conf.StateFilePath)
+	if err != nil {
+		log.Printf("Failed to load the state file, starting afresh: %v\n", err)
+	}
+
+	// Start at the location of a bookmark, in its cluster
+	var openingBookmark *config.Bookmark
+	currentClusterName := conf.InitialCluster
+	if bookmark, found := userState.Bookmark(conf.OpenBookmark); found && conf.OpenBookmark != "" {
+		if cluster, found := conf.Clusters[bookmark.Cluster]; found {
+			openingBookmark = &bookmark
+			currentClusterName = bookmark.Cluster
+			clusterInfo.Cluster = cluster
+		}
+	}
+
 	return Model{
 		conf:               conf,
 		state:              Initializing,
+		nav:                navigation{current: viewFrame{state: Initializing}},
 		clusterInfo:        clusterInfo,
 		table:              newTable(keys.Table),
 		keys:               keys,
 		help:               help.New(),
 		filterInput:        filterInput,
-		currentClusterName: conf.InitialCluster,
+		userState:          userState,
+		currentClusterName: currentClusterName,
+		stats:              newStatsMonitor(),
+		forwarder:          NewPortForwarder(),
+		runner:             NewCommandRunner(),
+		bookmarker:         NewBookmarker(),
+		notifier:           NewNotifier(),
+		requests:           newRequestTracker(conf.RequestTimeout),
+		openingBookmark:    openingBookmark,
+		sessions:           newSessionList(conf.Scrollback, conf.Mouse, SessionKeys{Detach: keys.Detach, CopyMode: keys.CopyMode, NextSession: keys.NextSession, PrevSession: keys.PrevSession}),
+		shells:             core.NewShellProbe(),
 	}
 }
 
 func (m Model) Close() error {
-	// Clean up container connection if active
-	if m.containerView != nil {
-		m.containerView.RestoreTerminal()
-	}
-	if m.containerConn != nil {
-		m.containerConn.Close()
+	m.requests.Cancel()
+	m.stats.Stop()
+	// Clean up container sessions, along with the browsers of other clusters they were opened with
+	for _, session := range slices.Clone(m.sessions.All()) {
+		m.closeSession(session.ID)
 	}
 	if m.browser != nil {
 		return m.browser.Close()
@@ -99,7 +189,11 @@ func (m Model) Close() error {
 // Init implements tea.Model.
 func (m Model) Init() tea.Cmd {
 	m.clusterInfo.Status = Connecting
-	return commands.ConnectToCluster(m.clusterInfo.Cluster)
+	var stateCmd tea.Cmd
+	if err := m.userState.LoadErr(); err != nil {
+		stateCmd = m.notifier.Error("", "Failed to load the state file, the sort orders and bookmarks won't be saved", err, nil)
+	}
+	return tea.Batch(connectToCluster(m.clusterInfo.Cluster), stateCmd)
 }
 
 // Update implements tea.Model.
@@ -112,80 +206,378 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 
 		// If we're in container mode, pass resize to container view
-		if m.state == ContainerAttached && m.containerView != nil {
-			*m.containerView, cmd = m.containerView.Update(msg)
-			return m, cmd
+		// Background sessions are resized too, so they are ready to be displayed
+		var resizeCmds []tea.Cmd
+		for _, session := range m.sessions.Attached() {
+			resizeCmds = append(resizeCmds, session.View.SetSize(m.containerViewBounds()))
+		}
+		if m.state == ContainerAttached {
+			return m, tea.Batch(resizeCmds...)
 		}
+		cmd = tea.Batch(resizeCmds...)
 
-		m.table.SetWidth(m.tableWidth())
+		// The columns are fitted to the new width
+		m.help.Width = m.width
+		m.refreshKeepingSelection()
 		m.table.SetHeight(m.tableHeight())
 
-		// Update filter input width to match table width
-		m.filterInput.Width = m.tableWidth()
+		// Update filter input width to match the window
+		m.filterInput.Width = m.contentWidth()
+
+	case requestMsg:
+		cmd = m.requests.Start(msg)
+		// The breadcrumb line shows the requests in flight, even before it is displayed
+		m.table.SetHeight(m.tableHeight())
+		return m, cmd
+
+	case requestDoneMsg:
+		handled := m.requests.Done(msg.id)
+		m.table.SetHeight(m.tableHeight())
+		if !handled || msg.msg == nil {
+			return m, nil
+		}
+		return m.Update(msg.msg)
+
+	case spinner.TickMsg:
+		return m, m.requests.Tick(msg)
 
 	case commands.ClusterConnected:
+		// e.g. a connection retried from the error log while connected
+		if m.browser != nil && m.browser != msg.Browser && !m.sessions.Uses(m.browser) {
+			m.browser.Close()
+		}
 		m.browser = msg.Browser
 		m.clusterInfo = ClusterInfo{
 			Cluster:  msg.Cluster,
 			NodeInfo: msg.Info,
 			Status:   Connected,
 		}
-		return m, commands.ListStacks(m.browser)
+		return m, m.listStacks()
 
 	case commands.StacksUpdated:
-		m.state = StacksList
-
-		var selectedStack *models.Stack
-		if m.state == StacksList && m.table.Cursor() < len(m.stacks) {
-			selectedStack = &m.stacks[m.table.Cursor()]
+		if !m.openView(m.nav.current.open(StacksList)) {
+			return m, nil
 		}
 		m.stacks = msg.Stacks
-		m.showStacksTable(msg.Stacks, selectedStack)
-		return m, nil
+		m.listed()
+		m.showView()
+		return m, m.followBookmark()
 
 	case commands.ServicesUpdated:
-		m.state = ServicesList
+		frame := m.nav.current.open(ServicesList)
+		frame.stack = &msg.Stack
+		frame.service = nil
+		if !m.openView(frame) {
+			return m, nil
+		}
 		m.services = msg.Services
-		m.selectedStack = &msg.Stack
-		m.showServicesTable(msg.Services, nil)
-		return m, nil
+		m.listed()
+		m.showView()
+		if cmd := m.followBookmark(); cmd != nil {
+			return m, cmd
+		}
+		return m, m.stats.StartServices(m.browser, msg.Services)
 	case commands.TasksUpdated:
-		m.state = TaskList
+		frame := m.nav.current.open(TaskList)
+		frame.service = &msg.Service
+		if !m.openView(frame) {
+			return m, nil
+		}
 		m.tasks = msg.Tasks
-		m.selectedService = &msg.Service
-		m.showTasksTable(msg.Tasks, nil)
+		m.listed()
+		m.showView()
+		m.followBookmark()
+		return m, m.stats.StartTasks(m.browser, msg.Service, msg.Tasks)
+
+	case commands.StatsStreamStarted:
+		if !m.stats.Active(msg.StreamID) {
+			return m, nil
+		}
+		return m, m.stats.Started(msg)
+
+	case commands.StatsReceived:
+		if !m.stats.Active(msg.StreamID) {
+			return m, nil
+		}
+		return m, m.stats.Record(msg.Stats)
+
+	case commands.StatsStreamError:
+		log.Printf("Stats stream failed: %v\n", msg.Err)
+		return m, nil
+
+	case commands.StatsTicked:
+		if !m.stats.Active(msg.StreamID) {
+			return m, nil
+		}
+		m.stats.Sample()
+		// Redraw keeping the selected item, which moves when sorted by usage
+		m.refreshKeepingSelection()
+		return m, commands.TickStats(msg.StreamID)
+
+	case commands.ListStacksError:
+		return m, m.loadFailed("Failed to list the stacks", msg.Err, (*Model).listStacks)
+
+	case commands.ListServicesError:
+		return m, m.loadFailed(fmt.Sprintf("Failed to list the services of %s", msg.Stack.Name), msg.Err, func(m *Model) tea.Cmd { return m.listServices(msg.Stack) })
+
+	case commands.ListTasksError:
+		return m, m.loadFailed(fmt.Sprintf("Failed to list the tasks of %s", msg.Service.Name), msg.Err, func(m *Model) tea.Cmd { return m.listTasks(msg.Service) })
+
+	case commands.ListNetworksError:
+		return m, m.loadFailed("Failed to list the networks", msg.Err, (*Model).listNetworks)
+
+	case commands.ListNetworkAttachmentsError:
+		return m, m.loadFailed(fmt.Sprintf("Failed to list the attachments of %s", msg.Network.Name), msg.Err, func(m *Model) tea.Cmd { return m.listNetworkAttachments(msg.Network) })
+
+	case commands.ListTaskMountsError:
+		return m, m.loadFailed(fmt.Sprintf("Failed to list the mounts of %s", shortID(msg.Task.TaskID)), msg.Err, func(m *Model) tea.Cmd { return m.listTaskMounts(msg.Task) })
+
+	case commands.ListNodeVolumesError:
+		return m, m.loadFailed(fmt.Sprintf("Failed to list the volumes of %s", cmp.Or(msg.Node.Hostname, msg.Node.Host)), msg.Err, func(m *Model) tea.Cmd { return m.listNodeVolumes(msg.Node) })
+
+	case commands.InspectNodeFailed:
+		return m, m.loadFailed(fmt.Sprintf("Failed to inspect %s", cmp.Or(msg.Node.Hostname, msg.Node.Host)), msg.Err, func(m *Model) tea.Cmd { return m.inspectNode(msg.Node) })
+
+	case commands.NodeInspected:
+		if msg.Node == m.clusterInfo.Cluster.Node {
+			m.clusterInfo.NodeInfo = msg.Info
+		}
+		return m, nil
+
+	case notificationExpiredMsg:
+		m.notifier.Expire(msg.id)
+		m.table.SetHeight(m.tableHeight())
+		return m, nil
+
+	case commands.NetworksUpdated:
+		if !m.openView(m.nav.current.open(NetworksList)) {
+			return m, nil
+		}
+		m.networks = msg.Networks
+		m.listed()
+		m.showView()
+		return m, nil
+
+	case commands.NetworkAttachmentsUpdated:
+		frame := m.nav.current.open(NetworkAttachmentsList)
+		frame.network = &msg.Network
+		if !m.openView(frame) {
+			return m, nil
+		}
+		m.networkAttachments = msg.Attachments
+		m.listed()
+		m.showView()
+		return m, nil
+
+	case commands.TaskMountsUpdated:
+		frame := m.nav.current.open(TaskMountsList)
+		frame.task = &msg.Task
+		if !m.openView(frame) {
+			return m, nil
+		}
+		m.taskMounts = msg.Mounts
+		m.listed()
+		m.showView()
+		return m, nil
+
+	case commands.NodeVolumesUpdated:
+		frame := m.nav.current.open(NodeVolumesList)
+		frame.node = &msg.Node
+		if !m.openView(frame) {
+			return m, nil
+		}
+		m.nodeVolumes = msg.Volumes
+		m.listed()
+		m.showView()
+		return m, nil
+
+	case commands.ContainerDirListed:
+		// The files are only listed while they are browsed
+		if m.files == nil {
+			return m, nil
+		}
+		frame := m.nav.current.open(ContainerFiles)
+		frame.task = &msg.Task
+		frame.path = msg.Path
+		if !m.openView(frame) {
+			return m, nil
+		}
+		m.files.Path = msg.Path
+		m.files.Files = msg.Files
+		if msg.Path != "/" {
+			// The browser opens at the working directory, its parents are reached through ..
+			parent := models.FileInfo{Name: "..", Path: path.Dir(msg.Path), Mode: os.ModeDir | 0755}
+			m.files.Files = append([]models.FileInfo{parent}, msg.Files...)
+		}
+		m.showView()
+		return m, nil
+
+	case commands.ListContainerDirError:
+		m.logError(fmt.Sprintf("Failed to list %s in %s", msg.Path, shortID(msg.Task.TaskID)), msg.Err)
+		if m.files != nil {
+			m.files.SetStatus("", msg.Err)
+			m.table.SetHeight(m.tableHeight())
+		}
+		return m, nil
+
+	case commands.FileCopied:
+		if m.files != nil {
+			m.files.SetStatus(msg.Description, nil)
+			m.table.SetHeight(m.tableHeight())
+		}
+		if m.state == ContainerFiles {
+			// e.g. listing the uploaded file
+			return m, m.reloadView()
+		}
+		return m, nil
+
+	case commands.CopyFileError:
+		m.logError("Failed to copy the file", msg.Err)
+		if m.files != nil {
+			m.files.SetStatus("", msg.Err)
+			m.table.SetHeight(m.tableHeight())
+		}
+		return m, nil
+
+	case commands.PortForwardStarted:
+		m.forwarder.SetStatus(fmt.Sprintf("Forwarding %s", msg.Forward), nil)
+		m.refreshPortForwards()
+		return m, nil
+
+	case commands.PortForwardStopped:
+		m.forwarder.SetStatus(fmt.Sprintf("Stopped forwarding %s", msg.Forward.LocalAddr()), nil)
+		m.refreshPortForwards()
+		return m, nil
+
+	case commands.PortForwardError:
+		m.logError("Port forward failed", msg.Err)
+		m.forwarder.SetStatus("", msg.Err)
+		m.table.SetHeight(m.tableHeight())
+		return m, nil
+
+	case commands.ExecResultsMsg:
+		m.runner.SetResults(msg.Service, msg.CommandLine, msg.Results)
+		if m.openView(m.nav.current.open(ExecResultsList)) {
+			m.showView()
+		}
+		return m, nil
+
+	case commands.ExecFailed:
+		m.logError("Failed to run the command", msg.Err)
+		m.runner.SetStatus("", msg.Err)
+		m.table.SetHeight(m.tableHeight())
 		return m, nil
 
 	case commands.ClusterConnectionFailed:
 		m.clusterInfo.Err = msg.Err
 		m.clusterInfo.Status = Disconnected
-		return m, nil
+		m.openingBookmark = nil
+		return m, m.notifyError(fmt.Sprintf("Failed to connect to %s", msg.Cluster.Name), msg.Err, func(*Model) tea.Cmd { return connectToCluster(msg.Cluster) })
 
 	case commands.ContainerAttachedMsg:
-		// Successfully attached to container
-		m.state = ContainerAttached
-		m.containerConn = msg.Conn
-		view := NewContainerView(msg.Conn)
-		m.containerView = &view
-		return m, m.containerView.Init()
+		// Successfully attached to container, opening a new session
+		session := m.sessions.Add(m.currentClusterName, m.browser, msg.Conn, containerSessionLabel(msg), containerSessionTitle(msg))
+		session.Debug = msg.Debug
+		return m, tea.Batch(
+			session.View.Init(),
+			session.View.SetSize(m.containerViewBounds()),
+			m.showSession(session),
+		)
+
+	case commands.ContainerOutputMsg:
+		session := m.sessions.Get(msg.SessionID)
+		if session == nil || session.Detached {
+			return m, nil
+		}
+		*session.View, cmd = session.View.Update(msg)
+		if session != m.sessions.Active() && !session.Unread {
+			session.Unread = true
+			if m.state == SessionsList {
+				m.refreshCurrentView()
+			}
+		}
+		return m, cmd
+
+	case commands.ContainerDetachedMsg:
+		if msg.SessionID == 0 {
+			// Attaching failed
+			m.logError("Failed to open a session", msg.Err)
+			m.attachErr = msg.Err
+			m.table.SetHeight(m.tableHeight())
+			return m, nil
+		}
+		session := m.sessions.Get(msg.SessionID)
+		if session == nil || session.Detached {
+			return m, nil
+		}
+		// The session ended, e.g. the shell exited, or its connection failed
+		var notify tea.Cmd
+		if msg.Err != nil {
+			notify = m.notifyError(fmt.Sprintf("Session %s ended", session.Label), msg.Err, nil)
+		}
+		active := m.sessions.Active()
+		m.closeSession(msg.SessionID)
+		if active != nil && active.ID == msg.SessionID {
+			return m, tea.Batch(notify, m.leaveSession())
+		}
+		if m.state == SessionsList {
+			m.refreshCurrentView()
+		}
+		return m, notify
+
+	case sessionReattachedMsg:
+		if msg.err != nil {
+			return m, m.notifyError("Failed to re-attach the session", msg.err, nil)
+		}
+		session, err := m.sessions.Reattach(msg.id, msg.conn)
+		if err != nil {
+			// e.g. closed meanwhile
+			log.Printf("Failed to re-attach session: %v\n", err)
+			msg.conn.Close()
+			return m, nil
+		}
+		return m, tea.Batch(
+			session.View.Init(),
+			session.View.SetSize(m.containerViewBounds()),
+			m.showSession(session),
+		)
 
 	case ExitContainerViewMsg:
-		// Clean exit from container
-		m.state = ServicesList
-		m.containerConn.Close()
-		m.containerView = nil
-		m.containerConn = nil
-		return m, nil
+		// Back to the view the session was displayed from, keeping it in background
+		if m.state != ContainerAttached {
+			return m, nil
+		}
+		return m, m.leaveSession()
 
 	case commands.ClustersListed:
+		if m.state != ClusterSelection {
+			return m, nil
+		}
 		m.clustersForDisplay = msg.Clusters
-		m.showClustersTable(msg.Clusters, msg.CurrentCluster)
+		m.showView()
 		return m, nil
 
+	case tea.MouseMsg:
+		if session := m.sessions.Active(); m.state == ContainerAttached && session != nil {
+			return m, m.handleSessionMouse(session, msg)
+		}
+		return m, m.handleMouse(msg)
+
 	case tea.KeyMsg:
-		// If we're in container mode, pass all keys to the container view
-		if m.state == ContainerAttached && m.containerView != nil {
-			*m.containerView, cmd = m.containerView.Update(msg)
+		// If we're in container mode, pass all keys but the session switching ones to the container view
+		if session := m.sessions.Active(); m.state == ContainerAttached && session != nil {
+			switch {
+			case key.Matches(msg, m.keys.NextSession):
+				return m, m.showSession(m.sessions.Cycle(1))
+			case key.Matches(msg, m.keys.PrevSession):
+				return m, m.showSession(m.sessions.Cycle(-1))
+			case key.Matches(msg, m.keys.GoToSession):
+				if target := m.sessions.At(slices.Index(m.keys.GoToSession.Keys(), msg.String())); target != nil {
+					return m, m.showSession(target)
+				}
+				return m, nil
+			}
+			*session.View, cmd = session.View.Update(msg)
 			return m, cmd
 		}
 
@@ -212,7 +604,131 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			default:
 				// Pass key to text input and apply filter as user types
 				m.filterInput, cmd = m.filterInput.Update(msg)
+				m.updateFilter()
 				m.refreshCurrentView()
+				m.table.SetHeight(m.tableHeight())
+				return m, cmd
+			}
+		}
+
+		// Handle download / upload prompts of the file browser
+		if m.state == ContainerFiles && m.files.Prompting() {
+			switch {
+			case key.Matches(msg, m.keys.Enter):
+				mode, value := m.files.ClosePrompt()
+				m.table.SetHeight(m.tableHeight())
+				if value == "" || m.browser == nil {
+					return m, nil
+				}
+				switch mode {
+				case downloadFilePrompt:
+					if file, found := selectedItem(&m, m.files.Files, fileKey); found {
+						m.files.SetStatus("Downloading...", nil)
+						task := m.files.Task
+						return m, m.withBrowser(fmt.Sprintf("downloading %s", file.Path), false, func(ctx context.Context, browser core.ClusterBrowser) tea.Cmd {
+							return commands.DownloadFromContainer(ctx, browser, task, file.Path, value)
+						})
+					}
+				case uploadFilePrompt:
+					m.files.SetStatus("Uploading...", nil)
+					task, dir := m.files.Task, m.files.Path
+					return m, m.withBrowser(fmt.Sprintf("uploading %s", value), false, func(ctx context.Context, browser core.ClusterBrowser) tea.Cmd {
+						return commands.UploadToContainer(ctx, browser, task, value, dir)
+					})
+				}
+				return m, nil
+
+			case key.Matches(msg, m.keys.Cancel):
+				m.files.ClosePrompt()
+				m.table.SetHeight(m.tableHeight())
+				return m, nil
+
+			default:
+				m.files.input, cmd = m.files.input.Update(msg)
+				return m, cmd
+			}
+		}
+
+		// Handle the port forward prompt
+		if m.forwarder.Prompting() {
+			switch {
+			case key.Matches(msg, m.keys.Enter):
+				service, task, value := m.forwarder.ClosePrompt()
+				m.table.SetHeight(m.tableHeight())
+				if value == "" || m.browser == nil {
+					return m, nil
+				}
+				localPort, remotePort, err := core.ParsePortMapping(value)
+				if err != nil {
+					m.forwarder.SetStatus("", err)
+					m.table.SetHeight(m.tableHeight())
+					return m, nil
+				}
+				label := fmt.Sprintf("forwarding port %d to %d", localPort, remotePort)
+				return m, m.withBrowser(label, true, func(ctx context.Context, browser core.ClusterBrowser) tea.Cmd {
+					if task != nil {
+						return commands.StartPortForward(ctx, browser, *task, localPort, remotePort)
+					}
+					return commands.StartServicePortForward(ctx, browser, *service, localPort, remotePort)
+				})
+
+			case key.Matches(msg, m.keys.Cancel):
+				m.forwarder.ClosePrompt()
+				m.table.SetHeight(m.tableHeight())
+				return m, nil
+
+			default:
+				m.forwarder.input, cmd = m.forwarder.input.Update(msg)
+				return m, cmd
+			}
+		}
+
+		// Handle the prompt of the command run in all tasks
+		if m.runner.Prompting() {
+			switch {
+			case key.Matches(msg, m.keys.Enter):
+				service, commandLine := m.runner.ClosePrompt()
+				if commandLine == "" || m.browser == nil {
+					m.table.SetHeight(m.tableHeight())
+					return m, nil
+				}
+				m.runner.SetStatus(fmt.Sprintf("Running `%s` in the tasks of %s...", commandLine, service.Name), nil)
+				m.table.SetHeight(m.tableHeight())
+				return m, m.execInServiceTasks(*service, commandLine)
+
+			case key.Matches(msg, m.keys.Cancel):
+				m.runner.ClosePrompt()
+				m.table.SetHeight(m.tableHeight())
+				return m, nil
+
+			default:
+				m.runner.input, cmd = m.runner.input.Update(msg)
+				return m, cmd
+			}
+		}
+
+		// Handle the prompt naming a bookmark
+		if m.bookmarker.Prompting() {
+			switch {
+			case key.Matches(msg, m.keys.Enter):
+				location, name := m.bookmarker.ClosePrompt()
+				if name != "" {
+					location.Name = name
+					m.saveBookmark(*location)
+					if m.state == BookmarksList {
+						m.refreshCurrentView()
+					}
+				}
+				m.table.SetHeight(m.tableHeight())
+				return m, nil
+
+			case key.Matches(msg, m.keys.Cancel):
+				m.bookmarker.ClosePrompt()
+				m.table.SetHeight(m.tableHeight())
+				return m, nil
+
+			default:
+				m.bookmarker.input, cmd = m.bookmarker.input.Update(msg)
 				return m, cmd
 			}
 		}
@@ -228,139 +744,256 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case key.Matches(msg, m.keys.Refresh):
 			switch m.state {
-			case StacksList:
+			case SessionsList, BookmarksList, ErrorLogList:
+				m.refreshKeepingSelection()
+			case ExecResultsList:
+				// Run the command again
 				if m.browser != nil {
-					return m, commands.ListStacks(m.browser)
+					m.runner.SetStatus(fmt.Sprintf("Running `%s` in the tasks of %s...", m.runner.CommandLine, m.runner.Service.Name), nil)
+					m.table.SetHeight(m.tableHeight())
+					return m, m.execInServiceTasks(m.runner.Service, m.runner.CommandLine)
 				}
+			default:
+				return m, m.reloadView()
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Enter):
+			return m, m.openSelected()
+
+		case key.Matches(msg, m.keys.Back):
+			return m, m.goBack()
+
+		case key.Matches(msg, m.keys.GoForward):
+			return m, m.goForward()
+
+		case key.Matches(msg, m.keys.Connect):
+			// Connect to container
+			m.attachErr = nil
+			m.table.SetHeight(m.tableHeight())
+			switch m.state {
 			case ServicesList:
-				if m.browser != nil && m.selectedStack != nil {
-					return m, commands.ListServices(m.browser, *m.selectedStack)
+				if selectedService, found := selectedItem(&m, m.services, serviceKey); found && m.browser != nil {
+					opts := m.sessionOptions(selectedService)
+					return m, m.withBrowser(fmt.Sprintf("attaching to %s", selectedService.Name), true, func(ctx context.Context, browser core.ClusterBrowser) tea.Cmd {
+						return commands.AttachToService(ctx, browser, selectedService, opts)
+					})
 				}
 			case TaskList:
-				if m.browser != nil && m.selectedService != nil {
-					return m, commands.ListTasks(m.browser, *m.selectedService)
+				if selectedTask, found := selectedItem(&m, m.tasks, taskKey); found && m.browser != nil && m.nav.current.service != nil {
+					opts := m.sessionOptions(*m.nav.current.service)
+					return m, m.withBrowser(fmt.Sprintf("attaching to %s", shortID(selectedTask.TaskID)), true, func(ctx context.Context, browser core.ClusterBrowser) tea.Cmd {
+						return commands.AttachToTask(ctx, browser, selectedTask, opts)
+					})
 				}
 			}
 			return m, nil
 
-		case key.Matches(msg, m.keys.Enter):
-			switch m.state {
-			case ClusterSelection:
-				// Get selected cluster and connect
-				cursor := m.table.Cursor()
-				if cursor >= 0 && cursor < len(m.clustersForDisplay) {
-					selectedCluster := m.clustersForDisplay[cursor]
-					// If it's the same cluster, just go back
-					if selectedCluster.Name == m.currentClusterName {
-						m.state = m.previousState
-						// Restore the appropriate table
-						switch m.previousState {
-						case StacksList:
-							m.showStacksTable(m.stacks, m.selectedStack)
-						case ServicesList:
-							m.showServicesTable(m.services, m.selectedService)
-						case TaskList:
-							m.showTasksTable(m.tasks, nil)
-						}
-						return m, nil
-					}
+		case key.Matches(msg, m.keys.Debug) && m.state == TaskList:
+			if selectedTask, found := selectedItem(&m, m.tasks, taskKey); found && m.browser != nil && m.nav.current.service != nil {
+				m.attachErr = nil
+				m.table.SetHeight(m.tableHeight())
+				image, opts := m.conf.DebugImage, m.sessionOptions(*m.nav.current.service)
+				return m, m.withBrowser(fmt.Sprintf("starting a debug sidecar for %s", shortID(selectedTask.TaskID)), false, func(ctx context.Context, browser core.ClusterBrowser) tea.Cmd {
+					return commands.DebugTask(ctx, browser, selectedTask, image, opts)
+				})
+			}
+			return m, nil
 
-					// Different cluster - disconnect and reconnect
-					if m.browser != nil {
-						m.browser.Close()
-						m.browser = nil
-					}
-					// Clear navigation state
-					m.stacks = nil
-					m.selectedStack = nil
-					m.services = nil
-					m.selectedService = nil
-					m.tasks = nil
-					// Update current cluster
-					m.currentClusterName = selectedCluster.Name
-					m.clusterInfo.Cluster = m.conf.Clusters[selectedCluster.Name]
-					m.clusterInfo.Status = Connecting
-					m.state = Initializing
-					return m, commands.ConnectToCluster(m.conf.Clusters[selectedCluster.Name])
-				}
+		case key.Matches(msg, m.keys.Cluster):
+			// Switch cluster - not allowed in container view
+			if m.state != ContainerAttached && m.state != ClusterSelection {
+				m.openView(m.nav.current.open(ClusterSelection))
+				return m, m.reloadView()
+			}
+			return m, nil
 
-			case StacksList:
-				// Get selected stack and navigate to services
-				cursor := m.table.Cursor()
-				if cursor >= 0 && cursor < len(m.stacks) && m.browser != nil {
-					selectedStack := m.stacks[cursor]
-					m.clearFilter()
-					return m, commands.ListServices(m.browser, selectedStack)
+		case key.Matches(msg, m.keys.Networks):
+			// Networks are listed cluster-wide, so they are reachable from the stacks list
+			if m.state == StacksList && m.browser != nil {
+				return m, m.listNetworks()
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Mounts):
+			if m.state == TaskList && m.browser != nil {
+				if selectedTask, found := selectedItem(&m, m.tasks, taskKey); found {
+					return m, m.listTaskMounts(selectedTask)
 				}
-			case ServicesList:
-				cursor := m.table.Cursor()
-				if cursor >= 0 && cursor < len(m.services) && m.browser != nil {
-					selectedService := m.services[cursor]
-					m.clearFilter()
-					return m, commands.ListTasks(m.browser, selectedService)
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Files):
+			if m.state == TaskList && m.browser != nil {
+				if selectedTask, found := selectedItem(&m, m.tasks, taskKey); found {
+					m.files = NewFileBrowser(selectedTask)
+					return m, m.listContainerDir(selectedTask, m.files.Path)
 				}
 			}
 			return m, nil
 
-		case key.Matches(msg, m.keys.Back):
+		case key.Matches(msg, m.keys.Download) && m.state == ContainerFiles:
+			if len(m.files.Files) > 0 {
+				m.files.PromptDownload()
+				m.table.SetHeight(m.tableHeight())
+				return m, textinput.Blink
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Upload):
+			if m.state == ContainerFiles {
+				m.files.PromptUpload()
+				m.table.SetHeight(m.tableHeight())
+				return m, textinput.Blink
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Volumes):
+			// Jump to the volumes of the node running the selected task
+			if m.browser == nil {
+				return m, nil
+			}
 			switch m.state {
-			case ClusterSelection:
-				// Go back to previous view without changing cluster
-				m.state = m.previousState
-				m.clearFilter()
-				// Restore the appropriate table
-				switch m.previousState {
-				case StacksList:
-					m.showStacksTable(m.stacks, m.selectedStack)
-				case ServicesList:
-					m.showServicesTable(m.services, m.selectedService)
-				case TaskList:
-					m.showTasksTable(m.tasks, nil)
+			case TaskList:
+				if selectedTask, found := selectedItem(&m, m.tasks, taskKey); found {
+					return m, m.listNodeVolumes(selectedTask.Node)
 				}
+			case TaskMountsList:
+				if task := m.nav.current.task; task != nil {
+					return m, m.listNodeVolumes(task.Node)
+				}
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Cancel):
+			// Dismiss the port forward, command, bookmark and attach status lines, stop opening
+			// a bookmark, and cancel the requests in flight
+			m.forwarder.SetStatus("", nil)
+			m.runner.SetStatus("", nil)
+			m.bookmarker.SetStatus("", nil)
+			m.openingBookmark = nil
+			m.attachErr = nil
+			m.notifier.Dismiss()
+			cmd = m.cancelRequests()
+			m.table.SetHeight(m.tableHeight())
+			return m, cmd
+
+		case key.Matches(msg, m.keys.Exec) && m.state == ServicesList:
+			if selectedService, found := selectedItem(&m, m.services, serviceKey); found && m.browser != nil {
+				m.runner.Prompt(selectedService)
+				m.table.SetHeight(m.tableHeight())
+				return m, textinput.Blink
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Forward):
+			if m.browser == nil {
 				return m, nil
+			}
+			switch m.state {
 			case ServicesList:
-				// Go back to stacks list
-				m.state = StacksList
-				m.clearFilter()
-				m.showStacksTable(m.stacks, m.selectedStack)
-				m.selectedStack = nil
-				return m, commands.ListStacks(m.browser)
+				if selectedService, found := selectedItem(&m, m.services, serviceKey); found {
+					m.forwarder.PromptService(selectedService)
+					m.table.SetHeight(m.tableHeight())
+					return m, textinput.Blink
+				}
 			case TaskList:
-				m.state = ServicesList
-				m.clearFilter()
-				m.showServicesTable(m.services, m.selectedService)
-				m.selectedService = nil
+				if selectedTask, found := selectedItem(&m, m.tasks, taskKey); found {
+					m.forwarder.PromptTask(selectedTask)
+					m.table.SetHeight(m.tableHeight())
+					return m, textinput.Blink
+				}
 			}
-			return m, commands.ListServices(m.browser, *m.selectedStack)
+			return m, nil
 
-		case key.Matches(msg, m.keys.Connect):
-			// Connect to container
+		case key.Matches(msg, m.keys.Forwards):
 			switch m.state {
-			case ServicesList:
-				cursor := m.table.Cursor()
-				if cursor >= 0 && cursor < len(m.services) && m.browser != nil {
-					selectedService := m.services[cursor]
-					return m, commands.AttachToService(m.browser, selectedService)
+			case StacksList, ServicesList, TaskList, NetworksList, NetworkAttachmentsList, TaskMountsList, NodeVolumesList, ContainerFiles:
+				if m.browser != nil {
+					m.openView(m.nav.current.open(PortForwardsList))
+					m.refreshPortForwards()
 				}
-			case TaskList:
-				cursor := m.table.Cursor()
-				if cursor >= 0 && cursor < len(m.services) && m.browser != nil {
-					selectedTask := m.tasks[cursor]
-					return m, commands.AttachToTask(m.browser, selectedTask)
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Stop) && m.state == PortForwardsList:
+			if forward, found := selectedItem(&m, m.portForwards, forwardKey); found {
+				return m, m.withBrowser(fmt.Sprintf("stopping the forward of %s", forward.LocalAddr()), true, func(ctx context.Context, browser core.ClusterBrowser) tea.Cmd {
+					return commands.StopPortForward(ctx, browser, forward)
+				})
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Sessions):
+			switch m.state {
+			case StacksList, ServicesList, TaskList, NetworksList, NetworkAttachmentsList, TaskMountsList, NodeVolumesList, ContainerFiles, PortForwardsList:
+				m.openView(m.nav.current.open(SessionsList))
+				m.showView()
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.DetachSession) && m.state == SessionsList:
+			if session := m.selectedSession(); session != nil && session.Debug {
+				// Debug sidecars don't outlive their session
+				m.closeSession(session.ID)
+				m.refreshCurrentView()
+			} else if session != nil && !session.Detached {
+				if err := m.sessions.Detach(session.ID); err != nil {
+					log.Printf("Failed to detach session: %v\n", err)
 				}
+				m.refreshCurrentView()
 			}
 			return m, nil
 
-		case key.Matches(msg, m.keys.Cluster):
-			// Switch cluster - not allowed in container view
-			if m.state != ContainerAttached {
-				m.previousState = m.state
-				m.state = ClusterSelection
-				m.clearFilter()
-				return m, commands.ListClusters(m.conf.Clusters, m.currentClusterName)
+		case key.Matches(msg, m.keys.CloseSession) && m.state == SessionsList:
+			if session := m.selectedSession(); session != nil {
+				m.closeSession(session.ID)
+				m.refreshCurrentView()
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Bookmark):
+			switch m.state {
+			case StacksList, ServicesList, TaskList, NetworksList, NetworkAttachmentsList, TaskMountsList, NodeVolumesList, ContainerFiles, PortForwardsList, SessionsList, ExecResultsList:
+				if m.currentClusterName != "" {
+					m.bookmarker.Prompt(m.currentLocation())
+					m.table.SetHeight(m.tableHeight())
+					return m, textinput.Blink
+				}
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Bookmarks):
+			switch m.state {
+			case StacksList, ServicesList, TaskList, NetworksList, NetworkAttachmentsList, TaskMountsList, NodeVolumesList, ContainerFiles, PortForwardsList, SessionsList, ExecResultsList:
+				m.openView(m.nav.current.open(BookmarksList))
+				m.showView()
 			}
 			return m, nil
 
+		case key.Matches(msg, m.keys.DeleteBookmark) && m.state == BookmarksList:
+			if bookmark, found := m.selectedBookmark(); found {
+				m.deleteBookmark(bookmark.Name)
+				m.refreshCurrentView()
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.ErrorLog) && m.state != ErrorLogList:
+			if m.openView(m.nav.current.open(ErrorLogList)) {
+				m.notifier.MarkRead()
+				m.showView()
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Retry):
+			return m, m.retry()
+
+		case key.Matches(msg, m.keys.Sort) && m.state != ContainerAttached && m.state != Initializing:
+			return m, m.cycleSortColumn()
+
+		case key.Matches(msg, m.keys.Reverse) && m.state != ContainerAttached && m.state != Initializing:
+			return m, m.reverseSortOrder()
+
 		case key.Matches(msg, m.keys.Filter):
 			// Enter filter mode
 			m.filterActive = true
@@ -370,10 +1003,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, textinput.Blink
 		}
 	}
-	if m.containerView != nil {
-		*m.containerView, cmd = m.containerView.Update(msg)
+	if session := m.sessions.Active(); m.state == ContainerAttached && session != nil {
+		*session.View, cmd = session.View.Update(msg)
 		return m, cmd
 	}
+	if cmd != nil {
+		// e.g. resizing background sessions
+		var tableCmd tea.Cmd
+		m.table, tableCmd = m.table.Update(msg)
+		return m, tea.Batch(cmd, tableCmd)
+	}
 	m.table, cmd = m.table.Update(msg)
 	return m, cmd
 }
@@ -381,35 +1020,93 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // View implements tea.Model.
 func (m Model) View() string {
 	// If we're in container mode, show the container view
-	if m.state == ContainerAttached && m.containerView != nil {
-		return m.containerView.View()
+	header := ClusterInfoView(m.clusterInfo)
+	if session := m.sessions.Active(); m.state == ContainerAttached && session != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, header, m.sessions.TabsView(m.width), session.View.View())
 	}
 
-	header := ClusterInfoView(m.clusterInfo)
+	sections := m.sections()
+	views := make([]string, len(sections))
+	for i, section := range sections {
+		views[i] = section.view
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, views...)
+}
+
+// viewSection is a part of the browser window, stacked under the previous one
+type viewSection struct {
+	kind sectionKind
+	view string
+}
+
+type sectionKind int
 
+const (
+	headerSection sectionKind = iota
+	breadcrumbSection
+	tableSection
+	detailSection // Output of the selected command result
+	statusSection // Prompts, status lines, filter and help
+)
+
+// sections returns the parts of the browser window, from top to bottom
+func (m Model) sections() []viewSection {
 	// Create contextual keymap for help display
 	contextualKeys := NewContextualKeyMap(&m.keys, m.state)
-	helpView := m.help.View(contextualKeys)
+	// The help truncates its items to the window, sometimes keeping one too many
+	helpView := lipgloss.NewStyle().MaxWidth(m.width).Render(m.help.View(contextualKeys))
+
+	filterView := m.filterView()
+
+	sections := []viewSection{{headerSection, ClusterInfoView(m.clusterInfo)}}
+
+	if breadcrumbView := m.breadcrumbView(); breadcrumbView != "" {
+		sections = append(sections, viewSection{breadcrumbSection, breadcrumbView})
+	}
+
+	sections = append(sections, viewSection{tableSection, m.tableSectionView()})
+
+	if m.state == ContainerFiles && m.files != nil {
+		sections = append(sections, viewSection{statusSection, m.files.View()})
+	}
 
-	// Build filter view
-	filterView := ""
-	if m.filterActive || m.filterInput.Value() != "" {
-		filterView = m.filterInput.View()
+	if forwarderView := m.forwarder.View(); forwarderView != "" {
+		sections = append(sections, viewSection{statusSection, forwarderView})
 	}
 
-	// Join all sections vertically
-	sections := []string{
-		header,
-		TableStyle.Render(m.table.View()),
+	if bookmarkerView := m.bookmarker.View(); bookmarkerView != "" {
+		sections = append(sections, viewSection{statusSection, bookmarkerView})
+	}
+
+	if m.state == ExecResultsList {
+		if result, ok := m.selectedExecResult(); ok {
+			sections = append(sections, viewSection{detailSection, m.runner.DetailView(result, m.width)})
+		}
+	}
+
+	if runnerView := m.runner.View(); runnerView != "" {
+		sections = append(sections, viewSection{statusSection, runnerView})
+	}
+
+	if attachView := m.attachErrorView(); attachView != "" {
+		sections = append(sections, viewSection{statusSection, attachView})
+	}
+
+	if m.state == ErrorLogList {
+		if logged, ok := m.selectedError(); ok {
+			sections = append(sections, viewSection{detailSection, errorDetailView(logged, m.width, m.keys)})
+		}
 	}
 
 	if filterView != "" {
-		sections = append(sections, filterView)
+		sections = append(sections, viewSection{statusSection, filterView})
 	}
 
-	sections = append(sections, helpView)
+	if statusBar := m.notifier.View(m.width, m.keys); statusBar != "" {
+		sections = append(sections, viewSection{statusSection, statusBar})
+	}
 
-	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+	return append(sections, viewSection{statusSection, helpView})
 }
 
 func (m Model) renderAppHeader() string {
@@ -419,18 +1116,63 @@ func (m Model) renderAppHeader() string {
 func (m Model) tableHeight() int {
 	// Calculate available height by subtracting header and help text
 	headerHeight := lipgloss.Height(m.renderAppHeader())
+	if breadcrumbView := m.breadcrumbView(); breadcrumbView != "" {
+		headerHeight += lipgloss.Height(breadcrumbView)
+	}
 	contextualKeys := NewContextualKeyMap(&m.keys, m.state)
 	helpHeight := lipgloss.Height(m.help.View(contextualKeys))
 
 	// Account for filter input if active or has text
 	filterHeight := 0
-	if m.filterActive || m.filterInput.Value() != "" {
-		filterHeight = lipgloss.Height(m.filterInput.View())
+	if filterView := m.filterView(); filterView != "" {
+		filterHeight = lipgloss.Height(filterView)
+	}
+
+	// Account for the file browser location, prompt and status lines
+	filesHeight := 0
+	if m.state == ContainerFiles && m.files != nil {
+		filesHeight = lipgloss.Height(m.files.View())
+	}
+
+	// Account for the port forward prompt and status lines
+	forwarderHeight := 0
+	if forwarderView := m.forwarder.View(); forwarderView != "" {
+		forwarderHeight = lipgloss.Height(forwarderView)
+	}
+
+	// Account for the bookmark prompt and status lines
+	bookmarkerHeight := 0
+	if bookmarkerView := m.bookmarker.View(); bookmarkerView != "" {
+		bookmarkerHeight = lipgloss.Height(bookmarkerView)
+	}
+
+	// Account for the command prompt, status lines and output pane
+	runnerHeight := 0
+	if runnerView := m.runner.View(); runnerView != "" {
+		runnerHeight = lipgloss.Height(runnerView)
+	}
+	if m.state == ExecResultsList && len(m.runner.Results) > 0 {
+		runnerHeight += execDetailHeight
+	}
+
+	// Account for the attach error line
+	attachHeight := 0
+	if attachView := m.attachErrorView(); attachView != "" {
+		attachHeight = lipgloss.Height(attachView)
+	}
+
+	// Account for the status bar, and the pane of the selected error in the error log
+	notifierHeight := 0
+	if statusBar := m.notifier.View(m.width, m.keys); statusBar != "" {
+		notifierHeight = lipgloss.Height(statusBar)
+	}
+	if m.state == ErrorLogList && len(m.notifier.Errors()) > 0 {
+		notifierHeight += errorDetailHeight
 	}
 
 	padding := 4 // Some padding for borders and spacing
 
-	availableHeight := m.height - headerHeight - helpHeight - filterHeight - padding
+	availableHeight := m.height - headerHeight - helpHeight - filterHeight - filesHeight - forwarderHeight - bookmarkerHeight - runnerHeight - attachHeight - notifierHeight - padding
 
 	// Ensure we don't return negative height
 	if availableHeight < 1 {
@@ -439,101 +1181,217 @@ func (m Model) tableHeight() int {
 	return availableHeight
 }
 
-func (m Model) tableWidth() int {
-	return m.width - 4
+// containerViewBounds returns the window row where the container view starts, below the
+// header and the session tabs, and its size
+func (m Model) containerViewBounds() (top, width, height int) {
+	top = lipgloss.Height(ClusterInfoView(m.clusterInfo)) + 1
+	return top, m.width, m.height - top
 }
 
-// refreshCurrentView refreshes the current view with the filter applied
-func (m *Model) refreshCurrentView() {
-	filterText := m.filterInput.Value()
+// attachErrorView renders the reason the last container session couldn't be opened, if any
+func (m Model) attachErrorView() string {
+	if m.attachErr == nil {
+		return ""
+	}
+	message := fmt.Sprintf("Error: %v", m.attachErr)
+	if errors.Is(m.attachErr, core.ErrNoShell) {
+		message += fmt.Sprintf(". Press %s on the task to debug it with a sidecar", m.keys.Debug.Help().Key)
+	}
+	return lipgloss.NewStyle().Foreground(ColorError).Width(m.width).Render(message)
+}
 
+// sessionOptions configures the sessions opened in the containers of service
+func (m *Model) sessionOptions(service models.Service) commands.SessionOptions {
+	return commands.SessionOptions{
+		Multiplexer: m.conf.Multiplexer,
+		RecordDir:   m.conf.RecordDir,
+		RecordInput: m.conf.RecordInput,
+		Shells:      m.shells,
+		Metadata: asciicast.Metadata{
+			Cluster: m.currentClusterName,
+			Stack:   service.Stack.Name,
+			Service: service.Name,
+		},
+	}
+}
+
+// openSelected opens the item under the cursor, e.g. the services of the selected stack
+func (m *Model) openSelected() tea.Cmd {
 	switch m.state {
+	case SessionsList:
+		session := m.selectedSession()
+		if session != nil && session.Detached {
+			return m.reattachSession(session)
+		}
+		return m.showSession(session)
+	case BookmarksList:
+		if bookmark, found := m.selectedBookmark(); found {
+			return m.openBookmark(bookmark)
+		}
+	case ClusterSelection:
+		// Get selected cluster and connect
+		if selectedCluster, found := selectedItem(m, m.clustersForDisplay, clusterKey); found {
+			// If it's the same cluster, just go back
+			if selectedCluster.Name == m.currentClusterName && m.browser != nil {
+				return m.goBack()
+			}
+
+			// Different cluster - disconnect and reconnect
+			return m.switchCluster(selectedCluster.Name)
+		}
+
 	case StacksList:
-		stacks := m.stacks
-		if filterText != "" {
-			stacks = m.filterStacks(filterText)
+		// Get selected stack and navigate to services
+		if selectedStack, found := selectedItem(m, m.stacks, stackKey); found && m.browser != nil {
+			return m.listServices(selectedStack)
 		}
-		m.showStacksTable(stacks, m.selectedStack)
 	case ServicesList:
-		services := m.services
-		if filterText != "" {
-			services = m.filterServices(filterText)
+		if selectedService, found := selectedItem(m, m.services, serviceKey); found && m.browser != nil {
+			return m.listTasks(selectedService)
 		}
-		m.showServicesTable(services, m.selectedService)
-	case TaskList:
-		tasks := m.tasks
-		if filterText != "" {
-			tasks = m.filterTasks(filterText)
+	case NetworksList:
+		if selectedNetwork, found := selectedItem(m, m.networks, networkKey); found && m.browser != nil {
+			return m.listNetworkAttachments(selectedNetwork)
 		}
-		m.showTasksTable(tasks, nil)
-	case ClusterSelection:
-		clusters := m.clustersForDisplay
-		if filterText != "" {
-			clusters = m.filterClusters(filterText)
+	case ContainerFiles:
+		if selectedFile, found := selectedItem(m, m.files.Files, fileKey); found && m.browser != nil {
+			if selectedFile.IsDir() {
+				return m.listContainerDir(m.files.Task, selectedFile.Path)
+			}
 		}
-		m.showClustersTable(clusters, m.currentClusterName)
 	}
+	return nil
 }
 
-// filterStacks filters stacks by name (case-insensitive)
-func (m *Model) filterStacks(filterText string) []models.Stack {
-	filterLower := strings.ToLower(filterText)
-	filtered := make([]models.Stack, 0)
-
-	for _, stack := range m.stacks {
-		if strings.Contains(strings.ToLower(stack.Name), filterLower) {
-			filtered = append(filtered, stack)
-		}
+// selectedExecResult returns the result under the cursor of the command results list
+func (m *Model) selectedExecResult() (models.ExecResult, bool) {
+	row := m.table.SelectedRow()
+	if row == nil {
+		return models.ExecResult{}, false
 	}
-
-	return filtered
+	return m.runner.Result(row[0])
 }
 
-// filterServices filters services by name or ID (case-insensitive)
-func (m *Model) filterServices(filterText string) []models.Service {
-	filterLower := strings.ToLower(filterText)
-	filtered := make([]models.Service, 0)
+// selectedSession returns the session under the cursor of the sessions list
+func (m *Model) selectedSession() *ContainerSession {
+	row := m.table.SelectedRow()
+	if row == nil {
+		return nil
+	}
+	id, err := strconv.Atoi(row[0])
+	if err != nil {
+		return nil
+	}
+	return m.sessions.Get(id)
+}
 
-	for _, service := range m.services {
-		if strings.Contains(strings.ToLower(service.Name), filterLower) ||
-			strings.Contains(strings.ToLower(service.ID), filterLower) {
-			filtered = append(filtered, service)
-		}
+// showSession displays a container session, over the view it is opened from
+func (m *Model) showSession(session *ContainerSession) tea.Cmd {
+	if session == nil {
+		return nil
+	}
+	if m.state != ContainerAttached {
+		m.nav.back = append(m.nav.back, m.leaveFrame())
+		m.nav.current = m.nav.current.open(ContainerAttached)
+		m.state = ContainerAttached
+		m.stats.Stop()
 	}
+	m.sessions.Activate(session)
+	return session.View.Show()
+}
 
-	return filtered
+// leaveSession goes back to the view the sessions were displayed from, which isn't
+// a view to go forward to
+func (m *Model) leaveSession() tea.Cmd {
+	m.sessions.Deactivate()
+	// Take the mouse back from the session
+	return tea.Batch(browserMouseMode(m.conf.Mouse), m.restoreFrame(popFrame(&m.nav.back)))
 }
 
-// filterTasks filters tasks by ID, container ID, status, or node (case-insensitive)
-func (m *Model) filterTasks(filterText string) []models.Task {
-	filterLower := strings.ToLower(filterText)
-	filtered := make([]models.Task, 0)
+// closeSession ends a container session. The browser it was opened with is closed
+// when it belonged to another cluster and no other session uses it.
+func (m *Model) closeSession(id int) {
+	session := m.sessions.Remove(id)
+	if session == nil {
+		return
+	}
+	if session.browser != m.browser && !m.sessions.Uses(session.browser) {
+		session.browser.Close()
+	}
+}
 
-	for _, task := range m.tasks {
-		if strings.Contains(strings.ToLower(task.TaskID), filterLower) ||
-			strings.Contains(strings.ToLower(task.ContainerID), filterLower) ||
-			strings.Contains(strings.ToLower(string(task.Status)), filterLower) ||
-			strings.Contains(strings.ToLower(task.Node.Host), filterLower) {
-			filtered = append(filtered, task)
+// switchCluster disconnects from the current cluster and connects to another one. The browser
+// is kept open while sessions opened with it are alive.
+func (m *Model) switchCluster(name string) tea.Cmd {
+	if m.browser != nil {
+		if !m.sessions.Uses(m.browser) {
+			m.browser.Close()
 		}
+		m.browser = nil
 	}
-
-	return filtered
+	m.stats.Stop()
+	// Clear navigation state, the history leading to views of the other cluster
+	m.resetNavigation()
+	m.stacks = nil
+	m.services = nil
+	m.tasks = nil
+	m.networks = nil
+	m.networkAttachments = nil
+	m.taskMounts = nil
+	m.nodeVolumes = nil
+	m.files = nil
+	m.portForwards = nil
+	// Update current cluster
+	m.currentClusterName = name
+	m.clusterInfo.Cluster = m.conf.Clusters[name]
+	m.clusterInfo.Status = Connecting
+	return connectToCluster(m.conf.Clusters[name])
 }
 
-// filterClusters filters clusters by name or host (case-insensitive)
-func (m *Model) filterClusters(filterText string) []commands.ClusterTableRow {
-	filterLower := strings.ToLower(filterText)
-	filtered := make([]commands.ClusterTableRow, 0)
-
-	for _, cluster := range m.clustersForDisplay {
-		if strings.Contains(strings.ToLower(cluster.Name), filterLower) ||
-			strings.Contains(strings.ToLower(cluster.Host), filterLower) {
-			filtered = append(filtered, cluster)
-		}
+// refreshPortForwards reloads the active forwards, redrawing the table when they are displayed
+func (m *Model) refreshPortForwards() {
+	if m.browser != nil {
+		m.portForwards = m.browser.ListPortForwards()
+	}
+	if m.state == PortForwardsList {
+		m.refreshKeepingSelection()
+	} else {
+		m.table.SetHeight(m.tableHeight())
 	}
+}
 
-	return filtered
+// refreshCurrentView refreshes the current view with the filter applied
+func (m *Model) refreshCurrentView() {
+	switch m.state {
+	case StacksList:
+		m.showStacksTable(query.Filter(m.filter, m.stacks, query.StackItem))
+	case ServicesList:
+		m.showServicesTable(query.Filter(m.filter, m.services, m.serviceItem))
+	case TaskList:
+		m.showTasksTable(query.Filter(m.filter, m.tasks, m.taskItem))
+	case NetworksList:
+		m.showNetworksTable(query.Filter(m.filter, m.networks, networkItem))
+	case NetworkAttachmentsList:
+		m.showNetworkAttachmentsTable(query.Filter(m.filter, m.networkAttachments, attachmentItem))
+	case TaskMountsList:
+		m.showTaskMountsTable(query.Filter(m.filter, m.taskMounts, mountItem))
+	case NodeVolumesList:
+		m.showNodeVolumesTable(query.Filter(m.filter, m.nodeVolumes, volumeItem))
+	case ContainerFiles:
+		m.showFilesTable(query.Filter(m.filter, m.files.Files, fileItem))
+	case PortForwardsList:
+		m.showPortForwardsTable(query.Filter(m.filter, m.portForwards, forwardItem))
+	case SessionsList:
+		m.showSessionsTable(query.Filter(m.filter, m.sessions.All(), m.sessionItem))
+	case ExecResultsList:
+		m.showExecResultsTable(query.Filter(m.filter, m.runner.Results, execResultItem))
+	case ClusterSelection:
+		m.showClustersTable(query.Filter(m.filter, m.clustersForDisplay, clusterItem), m.currentClusterName)
+	case BookmarksList:
+		m.showBookmarksTable(query.Filter(m.filter, m.userState.Bookmarks, bookmarkItem))
+	case ErrorLogList:
+		m.showErrorLogTable(query.Filter(m.filter, m.notifier.Errors(), errorItem))
+	}
 }
 
 // clearFilter clears the filter input and resets filter state
@@ -541,4 +1399,6 @@ func (m *Model) clearFilter() {
 	m.filterActive = false
 	m.filterInput.SetValue("")
 	m.filterInput.Blur()
+	m.filter = query.Query{}
+	m.filterErr = nil
 }
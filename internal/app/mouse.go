@@ -0,0 +1,158 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// mouseWheelLines is the number of lines, or rows of the table, scrolled by a notch of the
+// mouse wheel
+const mouseWheelLines = 3
+
+// doubleClickTime is the longest delay between the clicks of a double-click
+const doubleClickTime = 400 * time.Millisecond
+
+// rowClick is the last click on a row of the table, telling double-clicks apart
+type rowClick struct {
+	key string
+	at  time.Time
+}
+
+// handleMouse selects and opens the rows of the table on click and double-click, scrolls the
+// table and the output pane with the wheel, and returns to the views of the breadcrumb on click
+func (m *Model) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	if msg.Action != tea.MouseActionPress || m.state == Initializing || m.prompting() {
+		return nil
+	}
+	section, y := m.sectionAt(msg.Y)
+	switch section {
+	case breadcrumbSection:
+		if msg.Button == tea.MouseButtonLeft {
+			if index, found := m.crumbAt(msg.X); found {
+				return m.goBackTo(index)
+			}
+		}
+
+	case tableSection:
+		if msg.X >= lipgloss.Width(TableStyle.Render(m.table.View())) {
+			// On the detail pane
+			return nil
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.table.MoveUp(mouseWheelLines)
+		case tea.MouseButtonWheelDown:
+			m.table.MoveDown(mouseWheelLines)
+		case tea.MouseButtonLeft:
+			row, found := m.rowAt(y)
+			if !found {
+				return nil
+			}
+			moveCursor(&m.table, row)
+			key := m.selectedKey()
+			doubleClick := m.lastClick.key == key && time.Since(m.lastClick.at) < doubleClickTime
+			if doubleClick {
+				m.lastClick = rowClick{}
+				return m.openSelected()
+			}
+			m.lastClick = rowClick{key: key, at: time.Now()}
+		}
+
+	case detailSection:
+		if result, found := m.selectedExecResult(); found {
+			switch msg.Button {
+			case tea.MouseButtonWheelUp:
+				m.runner.Scroll(result, -mouseWheelLines)
+			case tea.MouseButtonWheelDown:
+				m.runner.Scroll(result, mouseWheelLines)
+			}
+		}
+	}
+	return nil
+}
+
+// handleSessionMouse switches sessions on a click on their tab, passing the other events to
+// the displayed session
+func (m *Model) handleSessionMouse(session *ContainerSession, msg tea.MouseMsg) tea.Cmd {
+	top, _, _ := m.containerViewBounds()
+	if msg.Y == top-1 {
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			if target := m.sessions.TabAt(msg.X); target != nil && target != session {
+				return m.showSession(target)
+			}
+		}
+		return nil
+	}
+	var cmd tea.Cmd
+	*session.View, cmd = session.View.Update(msg)
+	return cmd
+}
+
+// prompting reports whether a prompt takes the keys, which the mouse leaves alone
+func (m *Model) prompting() bool {
+	return m.filterActive || m.forwarder.Prompting() || m.runner.Prompting() || m.bookmarker.Prompting() ||
+		(m.state == ContainerFiles && m.files != nil && m.files.Prompting())
+}
+
+// sectionAt returns the section of the window displayed at row y, and the row within it
+func (m *Model) sectionAt(y int) (sectionKind, int) {
+	top := 0
+	for _, section := range m.sections() {
+		height := lipgloss.Height(section.view)
+		if y >= top && y < top+height {
+			return section.kind, y - top
+		}
+		top += height
+	}
+	return statusSection, 0
+}
+
+// rowAt returns the index of the table row displayed at row y of the table section
+func (m *Model) rowAt(y int) (int, bool) {
+	first, found := firstVisibleRow(m.table)
+	if !found {
+		return 0, false
+	}
+	// Under the border and the column headers
+	headerHeight := lipgloss.Height(m.table.View()) - m.table.Height()
+	line := y - TableStyle.GetBorderTopSize() - headerHeight
+	if line < 0 || line >= m.table.Height() {
+		return 0, false
+	}
+	row := first + line
+	return row, row < len(m.table.Rows())
+}
+
+// cursorMarker marks the selected row in the rendering of firstVisibleRow
+const cursorMarker = "\uE000"
+
+// firstVisibleRow returns the index of the first row displayed by the table. The table doesn't
+// expose its scroll position, so a copy of it renders the selected row with a marker: the rows
+// displayed above it are the ones before the cursor.
+func firstVisibleRow(t table.Model) (int, bool) {
+	styles := tableStyles()
+	styles.Selected = styles.Selected.SetString(cursorMarker)
+	t.SetStyles(styles)
+	lines := strings.Split(t.View(), "\n")
+	rows := lines[max(len(lines)-t.Height(), 0):]
+	for line, row := range rows {
+		if strings.Contains(row, cursorMarker) {
+			return max(t.Cursor()-line, 0), true
+		}
+	}
+	return 0, false
+}
+
+// moveCursor selects a displayed row, moving the cursor rather than setting it so that the
+// table keeps its scroll position
+func moveCursor(t *table.Model, row int) {
+	if delta := row - t.Cursor(); delta > 0 {
+		t.MoveDown(delta)
+	} else if delta < 0 {
+		t.MoveUp(-delta)
+	}
+}
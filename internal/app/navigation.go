@@ -0,0 +1,461 @@
+package app
+
+import (
+	"cmp"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mendes11/swarm-browser/internal/app/commands"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+// viewFrame is a view of the navigation: what it displays, and the row under the cursor and
+// the filter it was left with, restored when coming back to it
+type viewFrame struct {
+	state ViewState
+
+	// What the view displays, e.g. the stack of a services list. The views opened from it
+	// inherit them, so the tasks of a service know their stack.
+	stack   *models.Stack
+	service *models.Service
+	network *models.Network
+	task    *models.Task
+	node    *models.Node
+	path    string // Directory of the container files
+
+	// The first cell of the row under the cursor, and the filter typed in the view
+	cursor string
+	filter string
+}
+
+// open returns the frame of a view opened from this one
+func (f viewFrame) open(state ViewState) viewFrame {
+	f.state = state
+	f.path = ""
+	f.cursor = ""
+	f.filter = ""
+	return f
+}
+
+// subject identifies what the view displays among the views of its kind, e.g. the stack of
+// a services list. Views of a kind that always display the same items have none.
+func (f viewFrame) subject() string {
+	switch {
+	case f.state == ServicesList && f.stack != nil:
+		return f.stack.Name
+	case f.state == TaskList && f.service != nil:
+		return f.service.ID
+	case f.state == NetworkAttachmentsList && f.network != nil:
+		return f.network.ID
+	case f.state == TaskMountsList && f.task != nil:
+		return f.task.TaskID
+	case f.state == NodeVolumesList && f.node != nil:
+		return f.node.Host + "/" + f.node.Hostname
+	case f.state == ContainerFiles && f.task != nil:
+		return f.task.TaskID + ":" + f.path
+	}
+	return ""
+}
+
+// kind names the views of the state in the breadcrumb
+func (f viewFrame) kind() string {
+	switch f.state {
+	case StacksList:
+		return "stacks"
+	case ServicesList:
+		return "services"
+	case TaskList:
+		return "tasks"
+	case NetworksList:
+		return "networks"
+	case NetworkAttachmentsList:
+		return "attachments"
+	case TaskMountsList:
+		return "mounts"
+	case NodeVolumesList:
+		return "volumes"
+	case ContainerFiles:
+		return "files"
+	case PortForwardsList:
+		return "port forwards"
+	case SessionsList:
+		return "sessions"
+	case ExecResultsList:
+		return "results"
+	case ClusterSelection:
+		return "clusters"
+	case BookmarksList:
+		return "bookmarks"
+	case ErrorLogList:
+		return "errors"
+	}
+	return strings.ToLower(f.state.String())
+}
+
+// crumb names the frame in the breadcrumb after what it displays, e.g. the stack of a services
+// list, or else after its kind
+func (f viewFrame) crumb(cluster string) string {
+	switch {
+	case f.state == StacksList && cluster != "":
+		return cluster
+	case f.state == ServicesList && f.stack != nil:
+		return f.stack.Name
+	case f.state == TaskList && f.service != nil:
+		return f.service.Name
+	case f.state == NetworkAttachmentsList && f.network != nil:
+		return f.network.Name
+	case f.state == TaskMountsList && f.task != nil:
+		return shortID(f.task.TaskID)
+	case f.state == NodeVolumesList && f.node != nil:
+		return cmp.Or(f.node.Hostname, f.node.Host)
+	case f.state == ContainerFiles && f.path != "/":
+		return path.Base(f.path)
+	}
+	return f.kind()
+}
+
+// shortID shortens a Docker ID the way the docker CLI does
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// navigation is the history of the views: the frames of the views opened on the way to the
+// current one, returned to with back, and those left with back, returned to with forward
+type navigation struct {
+	current viewFrame
+	back    []viewFrame
+	forward []viewFrame
+}
+
+// keyColumn is the column identifying the rows of a view, the first one but in the clusters
+// list, whose first column marks the current cluster
+func keyColumn(state ViewState) int {
+	if state == ClusterSelection {
+		return 1
+	}
+	return 0
+}
+
+// selectedKey returns the cell identifying the row under the cursor, if any
+func (m *Model) selectedKey() string {
+	row := m.table.SelectedRow()
+	if column := keyColumn(m.state); column < len(row) {
+		return row[column]
+	}
+	return ""
+}
+
+// selectRow moves the cursor to the row identified by key, if it is displayed
+func (m *Model) selectRow(key string) {
+	if key == "" {
+		return
+	}
+	column := keyColumn(m.state)
+	for i, row := range m.table.Rows() {
+		if column < len(row) && row[column] == key {
+			m.table.SetCursor(i)
+			return
+		}
+	}
+}
+
+// selectedItem returns the item of the row under the cursor. The rows may be sorted and
+// filtered, so the item is found by the first cell of its row.
+func selectedItem[T any](m *Model, items []T, key func(T) string) (T, bool) {
+	var item T
+	selected := m.selectedKey()
+	if selected == "" {
+		return item, false
+	}
+	index := slices.IndexFunc(items, func(item T) bool { return key(item) == selected })
+	if index < 0 {
+		return item, false
+	}
+	return items[index], true
+}
+
+// The cells identifying the items of each view
+func stackKey(stack models.Stack) string                 { return stack.Name }
+func serviceKey(service models.Service) string           { return service.ID }
+func taskKey(task models.Task) string                    { return task.TaskID }
+func networkKey(network models.Network) string           { return network.Name }
+func forwardKey(forward models.PortForward) string       { return forward.LocalAddr() }
+func clusterKey(cluster commands.ClusterTableRow) string { return cluster.Name }
+func errorKey(logged notification) string                { return strconv.Itoa(logged.id) }
+
+// fileKey names a file the way the files list shows it, e.g. with a slash after directories
+func fileKey(file models.FileInfo) string {
+	switch {
+	case file.IsDir():
+		return file.Name + "/"
+	case file.LinkTarget != "":
+		return file.Name + " -> " + file.LinkTarget
+	}
+	return file.Name
+}
+
+// leaveFrame returns the current view as a frame to return to, with its cursor and filter
+func (m *Model) leaveFrame() viewFrame {
+	frame := m.nav.current
+	frame.state = m.state
+	frame.cursor = m.selectedKey()
+	frame.filter = m.filterInput.Value()
+	return frame
+}
+
+// openView navigates to a view, keeping the current one in the history, usually once its items
+// are listed. Opening the view being displayed again, e.g. when it is refreshed, keeps its cursor
+// and filter. Views aren't opened over a container session, reporting false.
+func (m *Model) openView(frame viewFrame) bool {
+	switch {
+	case m.state == ContainerAttached:
+		return false
+	case m.state == frame.state && m.nav.current.subject() == frame.subject():
+		frame.cursor = cmp.Or(m.selectedKey(), m.nav.current.cursor)
+		m.nav.current = frame
+		return true
+	case m.state != Initializing:
+		m.nav.back = append(m.nav.back, m.leaveFrame())
+	}
+	m.nav.forward = nil
+	m.enterFrame(frame)
+	return true
+}
+
+// enterFrame makes a frame the current view, with its filter typed in. The views still
+// loading are left for good.
+func (m *Model) enterFrame(frame viewFrame) {
+	m.nav.current = frame
+	m.state = frame.state
+	m.requests.CancelLoads()
+	m.stats.Stop()
+	m.clearFilter()
+	if frame.filter != "" {
+		m.filterInput.SetValue(frame.filter)
+		m.updateFilter()
+	}
+	if frame.state != ContainerFiles {
+		m.files = nil
+	}
+}
+
+// showView draws the current view, with the cursor on the row it was left on
+func (m *Model) showView() {
+	m.refreshCurrentView()
+	m.selectRow(m.nav.current.cursor)
+}
+
+// goBack returns to the previous view of the history
+func (m *Model) goBack() tea.Cmd {
+	return m.goBackTo(len(m.nav.back) - 1)
+}
+
+// goBackTo returns to the view at the given position of the back history, the views left on
+// the way can be returned to with forward
+func (m *Model) goBackTo(index int) tea.Cmd {
+	if index < 0 || index >= len(m.nav.back) {
+		return nil
+	}
+	m.nav.forward = append(m.nav.forward, m.leaveFrame())
+	for len(m.nav.back) > index+1 {
+		m.nav.forward = append(m.nav.forward, popFrame(&m.nav.back))
+	}
+	return m.restoreFrame(popFrame(&m.nav.back))
+}
+
+// goForward returns to the view left with back
+func (m *Model) goForward() tea.Cmd {
+	if len(m.nav.forward) == 0 {
+		return nil
+	}
+	m.nav.back = append(m.nav.back, m.leaveFrame())
+	return m.restoreFrame(popFrame(&m.nav.forward))
+}
+
+func popFrame(frames *[]viewFrame) viewFrame {
+	frame := (*frames)[len(*frames)-1]
+	*frames = (*frames)[:len(*frames)-1]
+	return frame
+}
+
+// restoreFrame displays a view of the history again. Its items are shown right away when they
+// are still the ones listed last, and are listed again to bring them up to date.
+func (m *Model) restoreFrame(frame viewFrame) tea.Cmd {
+	m.enterFrame(frame)
+	if m.loaded[frame.state] != frame.subject() {
+		m.forgetItems(frame.state)
+	}
+	if frame.state == ContainerFiles && frame.task != nil {
+		m.files = NewFileBrowser(*frame.task)
+		m.files.Path = frame.path
+	}
+	m.showView()
+	return m.reloadView()
+}
+
+// forgetItems drops the items listed for another view of the kind of state, so that they
+// aren't displayed as the ones of the restored view
+func (m *Model) forgetItems(state ViewState) {
+	switch state {
+	case ServicesList:
+		m.services = nil
+	case TaskList:
+		m.tasks = nil
+	case NetworkAttachmentsList:
+		m.networkAttachments = nil
+	case TaskMountsList:
+		m.taskMounts = nil
+	case NodeVolumesList:
+		m.nodeVolumes = nil
+	}
+}
+
+// listed records the view the items of its kind were listed for, which ends the failed load
+// displayed in the status bar, if any
+func (m *Model) listed() {
+	if m.loaded == nil {
+		m.loaded = make(map[ViewState]string)
+	}
+	m.loaded[m.state] = m.nav.current.subject()
+	m.notifier.Loaded()
+}
+
+// resetNavigation forgets the history, e.g. when switching to another cluster
+func (m *Model) resetNavigation() {
+	m.nav = navigation{current: viewFrame{state: Initializing}}
+	m.loaded = nil
+	m.state = Initializing
+}
+
+// reloadView lists the items of the current view again
+func (m *Model) reloadView() tea.Cmd {
+	frame := m.nav.current
+	switch {
+	case m.state == ClusterSelection:
+		return commands.ListClusters(m.conf.Clusters, m.currentClusterName)
+	case m.state == PortForwardsList:
+		m.refreshPortForwards()
+		return nil
+	case m.browser == nil:
+		return nil
+	case m.state == StacksList:
+		return m.listStacks()
+	case m.state == ServicesList && frame.stack != nil:
+		return m.listServices(*frame.stack)
+	case m.state == TaskList && frame.service != nil:
+		return m.listTasks(*frame.service)
+	case m.state == NetworksList:
+		return m.listNetworks()
+	case m.state == NetworkAttachmentsList && frame.network != nil:
+		return m.listNetworkAttachments(*frame.network)
+	case m.state == TaskMountsList && frame.task != nil:
+		return m.listTaskMounts(*frame.task)
+	case m.state == NodeVolumesList && frame.node != nil:
+		return m.listNodeVolumes(*frame.node)
+	case m.state == ContainerFiles && m.files != nil:
+		return m.listContainerDir(m.files.Task, m.files.Path)
+	}
+	return nil
+}
+
+// breadcrumb returns the names of the views of the history up to the current one, followed by
+// the kind of the current view when it is named after what it displays, e.g.
+// prod > backend > backend_api > tasks
+func (m *Model) breadcrumb() []string {
+	var crumbs []string
+	for _, frame := range m.nav.back {
+		crumbs = append(crumbs, frame.crumb(m.currentClusterName))
+	}
+	current := m.nav.current
+	crumbs = append(crumbs, current.crumb(m.currentClusterName))
+	if crumb := crumbs[len(crumbs)-1]; crumb != current.kind() && current.state != ContainerFiles {
+		crumbs = append(crumbs, current.kind())
+	}
+	return crumbs
+}
+
+// breadcrumbSeparator separates the views of the breadcrumb
+const breadcrumbSeparator = " > "
+
+// loadingGap separates the breadcrumb from the requests in flight shown after it
+const loadingGap = "   "
+
+// breadcrumbLoadingView renders the requests in flight shown after the breadcrumb, on at most
+// half of its line
+func (m Model) breadcrumbLoadingView() string {
+	return m.loadingView(m.contentWidth()/2 - lipgloss.Width(loadingGap))
+}
+
+// visibleCrumbs returns the views of the breadcrumb that fit on a line, next to the requests in
+// flight, and the number of views elided at its start
+func (m Model) visibleCrumbs() ([]string, int) {
+	crumbs := m.breadcrumb()
+	width := m.contentWidth()
+	if loading := m.breadcrumbLoadingView(); loading != "" {
+		width -= lipgloss.Width(loadingGap + loading)
+	}
+	elided := 0
+	for len(crumbs) > 1 && lipgloss.Width(strings.Join(crumbs, breadcrumbSeparator)) > width {
+		// Room for the ellipsis
+		if elided == 0 {
+			width -= lipgloss.Width("…" + breadcrumbSeparator)
+		}
+		crumbs = crumbs[1:]
+		elided++
+	}
+	return crumbs, elided
+}
+
+// breadcrumbView renders the breadcrumb on a line, eliding the views at its start when it
+// doesn't fit, followed by the requests in flight. Until connected, the line only shows them.
+func (m Model) breadcrumbView() string {
+	loading := m.breadcrumbLoadingView()
+	switch {
+	case m.state == ContainerAttached:
+		return ""
+	case m.state == Initializing && loading == "":
+		return ""
+	case m.state == Initializing:
+		return AppHeaderStyle.Render(loading)
+	}
+	crumbs, elided := m.visibleCrumbs()
+	parts := make([]string, len(crumbs))
+	for i, crumb := range crumbs {
+		parts[i] = breadcrumbStyle.Render(crumb)
+	}
+	parts[len(parts)-1] = breadcrumbCurrentStyle.Render(crumbs[len(crumbs)-1])
+	if elided > 0 {
+		parts = append([]string{breadcrumbStyle.Render("…")}, parts...)
+	}
+	line := strings.Join(parts, breadcrumbStyle.Render(breadcrumbSeparator))
+	if loading != "" {
+		line += loadingGap + loading
+	}
+	return AppHeaderStyle.Render(line)
+}
+
+// crumbAt returns the position in the back history of the view displayed at column x of the
+// breadcrumb, or false when x is not on a view of the history
+func (m Model) crumbAt(x int) (int, bool) {
+	crumbs, elided := m.visibleCrumbs()
+	start := AppHeaderStyle.GetPaddingLeft()
+	if elided > 0 {
+		start += lipgloss.Width("…" + breadcrumbSeparator)
+	}
+	for i, crumb := range crumbs {
+		end := start + lipgloss.Width(crumb)
+		if x >= start && x < end {
+			index := elided + i
+			return index, index < len(m.nav.back)
+		}
+		start = end + lipgloss.Width(breadcrumbSeparator)
+	}
+	return 0, false
+}
@@ -0,0 +1,297 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// notificationTimeout is how long a notification stays in the status bar. Failed loads stay
+// until they are retried, as their view is left empty or outdated.
+const notificationTimeout = 5 * time.Second
+
+// errorLogSize is the number of errors kept in the error log
+const errorLogSize = 100
+
+// notification is a message of the status bar, an error when err is set
+type notification struct {
+	id      int
+	at      time.Time
+	cluster string
+	message string
+	err     error
+	// Loads again what failed to load, on the cluster of the notification
+	retry retryAction
+}
+
+// retryAction returns the command loading again what failed to load. It is resolved when
+// retried, with the browser of the model at that time rather than the one that failed.
+type retryAction func(m *Model) tea.Cmd
+
+// notificationExpiredMsg is sent when a notification is to leave the status bar
+type notificationExpiredMsg struct {
+	id int
+}
+
+// Notifier displays notifications in the status bar, keeping the errors in a log
+type Notifier struct {
+	current *notification
+	errors  []notification // Oldest first
+	nextID  int
+	// Errors logged since the error log was last displayed
+	unread int
+}
+
+// NewNotifier creates an empty notifier
+func NewNotifier() *Notifier {
+	return &Notifier{nextID: 1}
+}
+
+// Info displays a message in the status bar until it expires
+func (n *Notifier) Info(message string) tea.Cmd {
+	return n.show(n.add("", message, nil, nil))
+}
+
+// Error displays an error in the status bar and logs it. An error with a retry command stays
+// displayed until it is retried or dismissed.
+func (n *Notifier) Error(cluster, message string, err error, retry retryAction) tea.Cmd {
+	notification := n.add(cluster, message, err, retry)
+	n.log(notification)
+	if retry != nil {
+		n.current = &notification
+		return nil
+	}
+	return n.show(notification)
+}
+
+// Log logs an error already displayed elsewhere, e.g. below the prompt it comes from
+func (n *Notifier) Log(cluster, message string, err error) {
+	n.log(n.add(cluster, message, err, nil))
+}
+
+func (n *Notifier) add(cluster, message string, err error, retry retryAction) notification {
+	notification := notification{id: n.nextID, at: time.Now(), cluster: cluster, message: message, err: err, retry: retry}
+	n.nextID++
+	return notification
+}
+
+func (n *Notifier) log(notification notification) {
+	n.errors = append(n.errors, notification)
+	if len(n.errors) > errorLogSize {
+		n.errors = n.errors[len(n.errors)-errorLogSize:]
+	}
+	n.unread++
+}
+
+func (n *Notifier) show(notification notification) tea.Cmd {
+	n.current = &notification
+	return tea.Tick(notificationTimeout, func(time.Time) tea.Msg {
+		return notificationExpiredMsg{id: notification.id}
+	})
+}
+
+// Expire removes a notification from the status bar, unless another one replaced it
+func (n *Notifier) Expire(id int) {
+	if n.current != nil && n.current.id == id {
+		n.current = nil
+	}
+}
+
+// Dismiss removes the displayed notification from the status bar
+func (n *Notifier) Dismiss() {
+	n.current = nil
+}
+
+// Loaded removes a failed load from the status bar once a view loads, e.g. when retried
+func (n *Notifier) Loaded() {
+	if n.current != nil && n.current.retry != nil {
+		n.current = nil
+	}
+}
+
+// Retry returns the action loading again what failed to load in the displayed notification
+func (n *Notifier) Retry(cluster string) retryAction {
+	if n.current == nil || n.current.retry == nil || n.current.cluster != cluster {
+		return nil
+	}
+	retry := n.current.retry
+	n.current = nil
+	return retry
+}
+
+// Errors returns the logged errors, oldest first
+func (n *Notifier) Errors() []notification {
+	return n.errors
+}
+
+// LoggedError returns the logged error with the given ID
+func (n *Notifier) LoggedError(id int) (notification, bool) {
+	for _, logged := range n.errors {
+		if logged.id == id {
+			return logged, true
+		}
+	}
+	return notification{}, false
+}
+
+// MarkRead is called when the error log is displayed
+func (n *Notifier) MarkRead() {
+	n.unread = 0
+}
+
+// View renders the status bar: the displayed notification and the count of unread errors
+func (n *Notifier) View(width int, keys AppKeyMap) string {
+	parts := []string{}
+	if current := n.current; current != nil {
+		if current.err == nil {
+			parts = append(parts, notificationInfoStyle.Render(current.message))
+		} else {
+			parts = append(parts, notificationErrorStyle.Render(fmt.Sprintf("%s: %s", current.message, rootCause(current.err))))
+			if current.retry != nil {
+				parts = append(parts, notificationHintStyle.Render(fmt.Sprintf("%s retry", keys.Retry.Help().Key)))
+			}
+		}
+	}
+	if n.unread > 0 {
+		count := "1 error"
+		if n.unread > 1 {
+			count = fmt.Sprintf("%d errors", n.unread)
+		}
+		parts = append(parts, notificationHintStyle.Render(fmt.Sprintf("%s • %s error log", count, keys.ErrorLog.Help().Key)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(strings.Join(parts, notificationHintStyle.Render(" • ")))
+}
+
+// errorChain returns the messages of the errors wrapped by err, outermost first, without the
+// messages of the errors they wrap, e.g. "failed to list services", "connection refused"
+func errorChain(err error) []string {
+	var chain []string
+	for err != nil {
+		message := err.Error()
+		next := errors.Unwrap(err)
+		if next != nil {
+			// Errors adding a stack trace rather than a message
+			if message == next.Error() {
+				err = next
+				continue
+			}
+			message = strings.TrimSuffix(message, ": "+next.Error())
+		}
+		chain = append(chain, message)
+		err = next
+	}
+	return chain
+}
+
+// rootCause returns the message of the innermost error wrapped by err
+func rootCause(err error) string {
+	chain := errorChain(err)
+	if len(chain) == 0 {
+		return ""
+	}
+	return chain[len(chain)-1]
+}
+
+// errorDetailHeight is the height of the pane showing the chain of the selected error
+const errorDetailHeight = 8
+
+// errorDetailView renders a logged error in a pane of the given width, with the errors it
+// wraps below it
+func errorDetailView(logged notification, width int, keys AppKeyMap) string {
+	heading := fmt.Sprintf("%s • %s", logged.at.Format(time.DateTime), logged.message)
+	if logged.cluster != "" {
+		heading += fmt.Sprintf(" • %s", logged.cluster)
+	}
+	if logged.retry != nil {
+		heading += fmt.Sprintf(" • %s retry", keys.Retry.Help().Key)
+	}
+	style := notificationErrorStyle.Width(width)
+	lines := []string{}
+	for i, message := range errorChain(logged.err) {
+		if i > 0 {
+			message = strings.Repeat("  ", i-1) + "└ " + message
+		}
+		lines = append(lines, strings.Split(style.Render(message), "\n")...)
+	}
+	if len(lines) > errorDetailHeight-1 {
+		lines = lines[:errorDetailHeight-1]
+	}
+	for len(lines) < errorDetailHeight-1 {
+		lines = append(lines, "")
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(lipgloss.JoinVertical(lipgloss.Left, append([]string{errorHeadingStyle.Render(heading)}, lines...)...))
+}
+
+// notifyError displays an error in the status bar and logs it
+func (m *Model) notifyError(message string, err error, retry retryAction) tea.Cmd {
+	log.Printf("%s: %v\n", message, err)
+	cmd := m.notifier.Error(m.currentClusterName, message, err, retry)
+	m.errorLogged()
+	return cmd
+}
+
+// logError logs an error displayed by the view it comes from
+func (m *Model) logError(message string, err error) {
+	log.Printf("%s: %v\n", message, err)
+	m.notifier.Log(m.currentClusterName, message, err)
+	m.errorLogged()
+}
+
+func (m *Model) errorLogged() {
+	if m.state == ErrorLogList {
+		m.notifier.MarkRead()
+		m.refreshKeepingSelection()
+	}
+	m.table.SetHeight(m.tableHeight())
+}
+
+// loadFailed reports a list that failed to load, which retry lists again, and stops opening
+// a bookmark
+func (m *Model) loadFailed(message string, err error, retry retryAction) tea.Cmd {
+	m.openingBookmark = nil
+	if m.browser == nil {
+		// Disconnected in the meantime
+		return m.notifyError(message, err, nil)
+	}
+	return m.notifyError(message, err, func(m *Model) tea.Cmd {
+		if m.browser == nil {
+			return nil
+		}
+		return retry(m)
+	})
+}
+
+// retry loads again what failed to load: the selected error in the error log, the displayed
+// one otherwise. Errors of another cluster than the current one aren't retried.
+func (m *Model) retry() tea.Cmd {
+	var retry retryAction
+	if m.state == ErrorLogList {
+		if logged, found := m.selectedError(); found && logged.cluster == m.currentClusterName {
+			retry = logged.retry
+		}
+	} else {
+		retry = m.notifier.Retry(m.currentClusterName)
+	}
+	var cmd tea.Cmd
+	if retry != nil {
+		cmd = retry(m)
+	}
+	if cmd != nil && m.browser == nil {
+		m.clusterInfo.Status = Connecting
+	}
+	m.table.SetHeight(m.tableHeight())
+	return cmd
+}
+
+// selectedError returns the error under the cursor of the error log
+func (m *Model) selectedError() (notification, bool) {
+	return selectedItem(m, m.notifier.Errors(), errorKey)
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+// PortForwarder holds the port forward prompt and the result of the last forward operation
+type PortForwarder struct {
+	// Target of the prompted forward, either a service or a task
+	service *models.Service
+	task    *models.Task
+
+	input  textinput.Model
+	status string
+	err    error
+}
+
+// NewPortForwarder creates the port forward prompt
+func NewPortForwarder() *PortForwarder {
+	input := textinput.New()
+	input.Placeholder = "8080:80"
+	input.CharLimit = 11
+	input.Width = 20
+	return &PortForwarder{input: input}
+}
+
+// Prompting reports whether the user is typing a port mapping
+func (p *PortForwarder) Prompting() bool {
+	return p.service != nil || p.task != nil
+}
+
+// PromptService asks for the ports to forward to a running task of the service
+func (p *PortForwarder) PromptService(service models.Service) {
+	p.service, p.task = &service, nil
+	p.prompt(service.Name)
+}
+
+// PromptTask asks for the ports to forward to the task container
+func (p *PortForwarder) PromptTask(task models.Task) {
+	p.service, p.task = nil, &task
+	p.prompt(task.TaskID)
+}
+
+func (p *PortForwarder) prompt(target string) {
+	p.input.Prompt = fmt.Sprintf("Forward to %s (local:remote): ", target)
+	p.input.SetValue("")
+	p.input.Focus()
+	p.SetStatus("", nil)
+}
+
+// ClosePrompt hides the prompt, returning the prompted target and the typed mapping
+func (p *PortForwarder) ClosePrompt() (*models.Service, *models.Task, string) {
+	service, task, value := p.service, p.task, p.input.Value()
+	p.service, p.task = nil, nil
+	p.input.Blur()
+	return service, task, value
+}
+
+// SetStatus displays the result of the last operation
+func (p *PortForwarder) SetStatus(status string, err error) {
+	p.status = status
+	p.err = err
+}
+
+// View renders the prompt and status lines, if any
+func (p *PortForwarder) View() string {
+	lines := []string{}
+	if p.Prompting() {
+		lines = append(lines, p.input.View())
+	}
+	if p.err != nil {
+		lines = append(lines, lipgloss.NewStyle().Foreground(ColorError).Render(fmt.Sprintf("Error: %v", p.err)))
+	} else if p.status != "" {
+		lines = append(lines, lipgloss.NewStyle().Foreground(ColorSuccess).Render(p.status))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
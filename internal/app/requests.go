@@ -0,0 +1,291 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mendes11/swarm-browser/internal/app/commands"
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+// requestMsg starts a request to the cluster. Requests are started through a message, rather
+// than right away, so that their commands can be kept and run again, e.g. to retry a load.
+type requestMsg struct {
+	// What the request does, e.g. "listing the services of backend"
+	label string
+	// Loads list the items of a view, their results are dropped once cancelled
+	load bool
+	// Whether the request is cancelled after the configured timeout
+	timeout bool
+	run     func(ctx context.Context) tea.Cmd
+}
+
+// requestDoneMsg carries the result of a request
+type requestDoneMsg struct {
+	id  int
+	msg tea.Msg
+}
+
+// loadRequest returns the command listing the items of a view, cancelled when another view is
+// opened or listed in the meantime
+func loadRequest(label string, run func(ctx context.Context) tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		return requestMsg{label: label, load: true, timeout: true, run: run}
+	}
+}
+
+// actionRequest returns the command of an action, e.g. opening a session. Cancelled actions
+// still report how they ended, e.g. with the session they opened meanwhile.
+func actionRequest(label string, timeout bool, run func(ctx context.Context) tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		return requestMsg{label: label, timeout: timeout, run: run}
+	}
+}
+
+// request is a request in flight
+type request struct {
+	label     string
+	load      bool
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+// requestTracker runs the requests to the cluster with a timeout, showing a spinner while they
+// are in flight, and cancels them on demand
+type requestTracker struct {
+	timeout  time.Duration
+	nextID   int
+	inFlight map[int]*request
+	spinner  spinner.Model
+}
+
+// newRequestTracker creates a tracker cancelling the requests after timeout, zero disabling it
+func newRequestTracker(timeout time.Duration) *requestTracker {
+	return &requestTracker{
+		timeout:  timeout,
+		nextID:   1,
+		inFlight: make(map[int]*request),
+		spinner:  spinner.New(spinner.WithSpinner(spinner.MiniDot)),
+	}
+}
+
+// Start runs a request, cancelling the loads it supersedes
+func (t *requestTracker) Start(msg requestMsg) tea.Cmd {
+	if msg.load {
+		t.CancelLoads()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if msg.timeout && t.timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), t.timeout)
+	}
+	cmd := msg.run(ctx)
+	if cmd == nil {
+		cancel()
+		return nil
+	}
+	idle := !t.Busy()
+	id := t.nextID
+	t.nextID++
+	t.inFlight[id] = &request{label: msg.label, load: msg.load, cancel: cancel}
+	done := func() tea.Msg {
+		return requestDoneMsg{id: id, msg: cmd()}
+	}
+	if idle {
+		return tea.Batch(done, t.spinner.Tick)
+	}
+	return done
+}
+
+// Done ends a request, reporting whether its result is to be handled. The results of the
+// cancelled loads are dropped.
+func (t *requestTracker) Done(id int) bool {
+	req, found := t.inFlight[id]
+	if !found {
+		return false
+	}
+	delete(t.inFlight, id)
+	req.cancel()
+	return !req.load || !req.cancelled
+}
+
+// CancelLoads cancels the loads in flight, e.g. when navigating to another view
+func (t *requestTracker) CancelLoads() {
+	for id, req := range t.inFlight {
+		if req.load {
+			req.cancel()
+			delete(t.inFlight, id)
+		}
+	}
+}
+
+// Cancel cancels all the requests in flight, returning a message describing them, empty when
+// there were none
+func (t *requestTracker) Cancel() string {
+	active := t.active()
+	for id, req := range t.inFlight {
+		req.cancel()
+		req.cancelled = true
+		if req.load {
+			delete(t.inFlight, id)
+		}
+	}
+	switch len(active) {
+	case 0:
+		return ""
+	case 1:
+		return "Cancelled " + active[0].label
+	}
+	return fmt.Sprintf("Cancelled %d requests", len(active))
+}
+
+// Busy reports whether requests are in flight
+func (t *requestTracker) Busy() bool {
+	return len(t.active()) > 0
+}
+
+// active returns the requests in flight that weren't cancelled, oldest first
+func (t *requestTracker) active() []*request {
+	var active []*request
+	for id := 1; id < t.nextID; id++ {
+		if req, found := t.inFlight[id]; found && !req.cancelled {
+			active = append(active, req)
+		}
+	}
+	return active
+}
+
+// Tick animates the spinner while requests are in flight
+func (t *requestTracker) Tick(msg spinner.TickMsg) tea.Cmd {
+	if !t.Busy() {
+		return nil
+	}
+	var cmd tea.Cmd
+	t.spinner, cmd = t.spinner.Update(msg)
+	return cmd
+}
+
+// View renders the spinner along with the latest request in flight, empty when there are none
+func (t *requestTracker) View(keys AppKeyMap) string {
+	active := t.active()
+	if len(active) == 0 {
+		return ""
+	}
+	label := active[len(active)-1].label
+	label = strings.ToUpper(label[:1]) + label[1:] + "…"
+	if len(active) > 1 {
+		label += fmt.Sprintf(" (+%d)", len(active)-1)
+	}
+	return loadingStyle.Render(t.spinner.View()+" "+label) +
+		notificationHintStyle.Render(fmt.Sprintf(" • %s cancel", keys.Cancel.Help().Key))
+}
+
+// loadingView renders the requests in flight on at most width cells
+func (m Model) loadingView(width int) string {
+	view := m.requests.View(m.keys)
+	if view == "" || width <= 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(view)
+}
+
+// cancelRequests cancels the requests in flight, telling which ones in the status bar
+func (m *Model) cancelRequests() tea.Cmd {
+	if message := m.requests.Cancel(); message != "" {
+		return m.notifier.Info(message)
+	}
+	return nil
+}
+
+// The requests listing the items of the views, and inspecting the nodes
+
+func (m *Model) listStacks() tea.Cmd {
+	browser := m.browser
+	return loadRequest("listing the stacks", func(ctx context.Context) tea.Cmd {
+		return commands.ListStacks(ctx, browser)
+	})
+}
+
+func (m *Model) listServices(stack models.Stack) tea.Cmd {
+	browser := m.browser
+	return loadRequest(fmt.Sprintf("listing the services of %s", stack.Name), func(ctx context.Context) tea.Cmd {
+		return commands.ListServices(ctx, browser, stack)
+	})
+}
+
+func (m *Model) listTasks(service models.Service) tea.Cmd {
+	browser := m.browser
+	return loadRequest(fmt.Sprintf("listing the tasks of %s", service.Name), func(ctx context.Context) tea.Cmd {
+		return commands.ListTasks(ctx, browser, service)
+	})
+}
+
+func (m *Model) listNetworks() tea.Cmd {
+	browser := m.browser
+	return loadRequest("listing the networks", func(ctx context.Context) tea.Cmd {
+		return commands.ListNetworks(ctx, browser)
+	})
+}
+
+func (m *Model) listNetworkAttachments(network models.Network) tea.Cmd {
+	browser := m.browser
+	return loadRequest(fmt.Sprintf("listing the attachments of %s", network.Name), func(ctx context.Context) tea.Cmd {
+		return commands.ListNetworkAttachments(ctx, browser, network)
+	})
+}
+
+func (m *Model) listTaskMounts(task models.Task) tea.Cmd {
+	browser := m.browser
+	return loadRequest(fmt.Sprintf("listing the mounts of %s", shortID(task.TaskID)), func(ctx context.Context) tea.Cmd {
+		return commands.ListTaskMounts(ctx, browser, task)
+	})
+}
+
+func (m *Model) listNodeVolumes(node models.Node) tea.Cmd {
+	browser := m.browser
+	return loadRequest(fmt.Sprintf("listing the volumes of %s", cmp.Or(node.Hostname, node.Host)), func(ctx context.Context) tea.Cmd {
+		return commands.ListNodeVolumes(ctx, browser, node)
+	})
+}
+
+func (m *Model) listContainerDir(task models.Task, dirPath string) tea.Cmd {
+	browser := m.browser
+	return loadRequest(fmt.Sprintf("listing %s in %s", cmp.Or(dirPath, "the working directory"), shortID(task.TaskID)), func(ctx context.Context) tea.Cmd {
+		return commands.ListContainerDir(ctx, browser, task, dirPath)
+	})
+}
+
+func (m *Model) inspectNode(node models.Node) tea.Cmd {
+	browser := m.browser
+	return actionRequest(fmt.Sprintf("inspecting %s", cmp.Or(node.Hostname, node.Host)), true, func(ctx context.Context) tea.Cmd {
+		return commands.InspectNode(ctx, browser, node)
+	})
+}
+
+// connectToCluster connects to a cluster, which can be cancelled while its node is inspected
+func connectToCluster(cluster models.Cluster) tea.Cmd {
+	return actionRequest(fmt.Sprintf("connecting to %s", cluster.Name), true, func(ctx context.Context) tea.Cmd {
+		return commands.ConnectToCluster(ctx, cluster)
+	})
+}
+
+// withBrowser returns the command of an action run with the current browser
+func (m *Model) withBrowser(label string, timeout bool, run func(ctx context.Context, browser core.ClusterBrowser) tea.Cmd) tea.Cmd {
+	browser := m.browser
+	return actionRequest(label, timeout, func(ctx context.Context) tea.Cmd {
+		return run(ctx, browser)
+	})
+}
+
+// execInServiceTasks runs a command line in the tasks of a service, for as long as it takes
+func (m *Model) execInServiceTasks(service models.Service, commandLine string) tea.Cmd {
+	return m.withBrowser(fmt.Sprintf("running `%s` in the tasks of %s", commandLine, service.Name), false, func(ctx context.Context, browser core.ClusterBrowser) tea.Cmd {
+		return commands.ExecInServiceTasks(ctx, browser, service, commandLine)
+	})
+}
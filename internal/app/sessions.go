@@ -0,0 +1,305 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/pkg/errors"
+)
+
+// ContainerSession is an open exec session in a task container.
+// Sessions keep running, and buffering their output, while they are not displayed.
+//
+// A detached session has no tab nor view: its exec keeps running in the browser,
+// which buffers the output until the session is re-attached.
+type ContainerSession struct {
+	ID      int
+	Cluster string
+	Label   string
+	Title   string
+	View    *ContainerView // nil while detached
+	// Unread is set when a session receives output while it isn't displayed
+	Unread bool
+	// Debug sessions run in a sidecar, removed when they end rather than detached
+	Debug bool
+
+	// Detached sessions are kept by the browser under their exec session ID
+	Detached bool
+	ExecID   int
+
+	// The browser the session was opened with, kept open until the session ends
+	browser core.ClusterBrowser
+}
+
+// SessionList holds the open container sessions, in the order they were opened
+type SessionList struct {
+	sessions []*ContainerSession
+	active   *ContainerSession // The displayed session, nil while browsing
+	nextID   int
+	// Lines of output kept in the scrollback of each session
+	scrollback int
+	keys       SessionKeys
+	// Whether the browser reports the mouse, given back by the sessions capturing it
+	mouse bool
+}
+
+func newSessionList(scrollback int, mouse bool, keys SessionKeys) *SessionList {
+	return &SessionList{nextID: 1, scrollback: scrollback, mouse: mouse, keys: keys}
+}
+
+// newView creates the view of a session connection
+func (l *SessionList) newView(id int, conn core.ContainerConnection, title string) *ContainerView {
+	view := NewContainerView(id, conn, title, l.scrollback, l.keys)
+	view.browserMouse = l.mouse
+	return &view
+}
+
+// Add opens a session for an attached container connection, returning it
+func (l *SessionList) Add(cluster string, browser core.ClusterBrowser, conn core.ContainerConnection, label, title string) *ContainerSession {
+	id := l.nextID
+	l.nextID++
+	session := &ContainerSession{
+		ID:      id,
+		Cluster: cluster,
+		Label:   label,
+		Title:   title,
+		View:    l.newView(id, conn, title),
+		browser: browser,
+	}
+	l.sessions = append(l.sessions, session)
+	return session
+}
+
+// Get returns the session with the given ID, or nil when it was already closed
+func (l *SessionList) Get(id int) *ContainerSession {
+	for _, session := range l.sessions {
+		if session.ID == id {
+			return session
+		}
+	}
+	return nil
+}
+
+// Remove closes the session and removes it from the list
+func (l *SessionList) Remove(id int) *ContainerSession {
+	for i, session := range l.sessions {
+		if session.ID == id {
+			if session.Detached {
+				session.browser.KillSession(session.ExecID)
+			} else {
+				session.View.Close()
+			}
+			l.sessions = append(l.sessions[:i], l.sessions[i+1:]...)
+			if l.active == session {
+				l.active = nil
+			}
+			return session
+		}
+	}
+	return nil
+}
+
+// All returns the open sessions, including the detached ones
+func (l *SessionList) All() []*ContainerSession {
+	return l.sessions
+}
+
+// Attached returns the sessions with a tab
+func (l *SessionList) Attached() []*ContainerSession {
+	attached := make([]*ContainerSession, 0, len(l.sessions))
+	for _, session := range l.sessions {
+		if !session.Detached {
+			attached = append(attached, session)
+		}
+	}
+	return attached
+}
+
+// Detach closes the tab of a session, leaving its exec running in the browser
+func (l *SessionList) Detach(id int) error {
+	session := l.Get(id)
+	if session == nil || session.Detached {
+		return errors.Errorf("session %d is not attached", id)
+	}
+	info, err := session.browser.DetachSession(session.View.conn)
+	if err != nil {
+		return errors.Wrap(err, "failed to detach session")
+	}
+	if l.active == session {
+		l.active = nil
+	}
+	session.Detached = true
+	session.ExecID = info.ID
+	session.View = nil
+	session.Unread = false
+	return nil
+}
+
+// sessionReattachedMsg is sent once the exec of a detached session is attached to again
+type sessionReattachedMsg struct {
+	id   int
+	conn core.ContainerConnection
+	err  error
+}
+
+// reattachSession attaches to the exec of a detached session again, in the background
+func (m *Model) reattachSession(session *ContainerSession) tea.Cmd {
+	id, execID, browser := session.ID, session.ExecID, session.browser
+	return actionRequest(fmt.Sprintf("re-attaching to %s", session.Label), true, func(ctx context.Context) tea.Cmd {
+		return func() tea.Msg {
+			conn, err := browser.ReattachSession(ctx, execID)
+			if err != nil {
+				return sessionReattachedMsg{id: id, err: errors.Wrap(err, "failed to re-attach session")}
+			}
+			return sessionReattachedMsg{id: id, conn: conn}
+		}
+	})
+}
+
+// Reattach opens a tab for a detached session again on its new connection, replaying the
+// output buffered meanwhile
+func (l *SessionList) Reattach(id int, conn core.ContainerConnection) (*ContainerSession, error) {
+	session := l.Get(id)
+	if session == nil || !session.Detached {
+		return nil, errors.Errorf("session %d is not detached", id)
+	}
+	// A new ID tells the messages of the view used before detaching apart
+	session.ID = l.nextID
+	l.nextID++
+	session.View = l.newView(session.ID, conn, session.Title)
+	session.Detached = false
+	return session, nil
+}
+
+// ExecInfo returns the state of a detached session kept by its browser
+func (l *SessionList) ExecInfo(session *ContainerSession) (models.ExecSession, bool) {
+	if !session.Detached {
+		return models.ExecSession{}, false
+	}
+	for _, info := range session.browser.ListDetachedSessions() {
+		if info.ID == session.ExecID {
+			return info, true
+		}
+	}
+	return models.ExecSession{}, false
+}
+
+// Len returns the number of open sessions
+func (l *SessionList) Len() int {
+	return len(l.sessions)
+}
+
+// Active returns the displayed session, if any
+func (l *SessionList) Active() *ContainerSession {
+	return l.active
+}
+
+// Activate displays the session, marking its output as read
+func (l *SessionList) Activate(session *ContainerSession) {
+	if l.active != nil && l.active != session && l.active.View != nil {
+		l.active.View.Hide()
+	}
+	l.active = session
+	session.Unread = false
+}
+
+// Deactivate hides the displayed session, which keeps running in background
+func (l *SessionList) Deactivate() {
+	if l.active != nil && l.active.View != nil {
+		l.active.View.Hide()
+	}
+	l.active = nil
+}
+
+// Cycle returns the tab offset positions away from the active one, wrapping around
+func (l *SessionList) Cycle(offset int) *ContainerSession {
+	attached := l.Attached()
+	if len(attached) == 0 {
+		return nil
+	}
+	index := 0
+	for i, session := range attached {
+		if session == l.active {
+			index = i
+		}
+	}
+	index = ((index+offset)%len(attached) + len(attached)) % len(attached)
+	return attached[index]
+}
+
+// At returns the session of the tab at the given position, or nil when out of range
+func (l *SessionList) At(index int) *ContainerSession {
+	attached := l.Attached()
+	if index < 0 || index >= len(attached) {
+		return nil
+	}
+	return attached[index]
+}
+
+// Uses reports whether any session was opened with the browser
+func (l *SessionList) Uses(browser core.ClusterBrowser) bool {
+	for _, session := range l.sessions {
+		if session.browser == browser {
+			return true
+		}
+	}
+	return false
+}
+
+// UnreadCount returns the number of sessions with unread output
+func (l *SessionList) UnreadCount() int {
+	count := 0
+	for _, session := range l.sessions {
+		if session.Unread {
+			count++
+		}
+	}
+	return count
+}
+
+// TabsView renders a tab per session, highlighting the active one and marking unread activity
+func (l *SessionList) TabsView(width int) string {
+	return lipgloss.NewStyle().MaxWidth(width).Render(strings.Join(l.tabs(), sessionTabSeparator))
+}
+
+// TabAt returns the session of the tab displayed at column x, or nil when there is none
+func (l *SessionList) TabAt(x int) *ContainerSession {
+	start := 0
+	for i, tab := range l.tabs() {
+		end := start + lipgloss.Width(tab)
+		if x >= start && x < end {
+			return l.At(i)
+		}
+		start = end + lipgloss.Width(sessionTabSeparator)
+	}
+	return nil
+}
+
+const sessionTabSeparator = " "
+
+// tabs renders the tabs of the attached sessions
+func (l *SessionList) tabs() []string {
+	attached := l.Attached()
+	tabs := make([]string, 0, len(attached))
+	for i, session := range attached {
+		label := fmt.Sprintf("%d %s", i+1, session.Label)
+		if session.Cluster != "" {
+			label = fmt.Sprintf("%s@%s", label, session.Cluster)
+		}
+		if session == l.active {
+			tabs = append(tabs, sessionActiveTabStyle.Render(label))
+			continue
+		}
+		if session.Unread {
+			tabs = append(tabs, sessionUnreadTabStyle.Render(label+" ●"))
+			continue
+		}
+		tabs = append(tabs, sessionTabStyle.Render(label))
+	}
+	return tabs
+}
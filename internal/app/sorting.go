@@ -0,0 +1,229 @@
+package app
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mendes11/swarm-browser/internal/config"
+)
+
+// unsortableColumns can't be sorted on, since their cells mean nothing as text
+var unsortableColumns = map[string]bool{
+	"":            true, // e.g. the current cluster arrow
+	"CPU History": true,
+}
+
+// sortRows sorts the rows of the current view by its sort column, moving the items they display
+// along, and shows them in the table. The items are usually the model slice the cursor indexes.
+// cursor is the row to select, as an index before sorting.
+func sortRows[T any](m *Model, rows []table.Row, items []T, cursor int) {
+	order := make([]int, len(rows))
+	for i := range order {
+		order[i] = i
+	}
+
+	columns := m.table.Columns()
+	if sort, found := m.sortOrder(); found {
+		if column := slices.IndexFunc(columns, func(c table.Column) bool { return c.Title == sort.Column }); column >= 0 {
+			slices.SortStableFunc(order, func(a, b int) int {
+				result := compareCells(rows[a][column], rows[b][column])
+				if sort.Descending {
+					return -result
+				}
+				return result
+			})
+			columns[column].Title += sortIndicator(sort.Descending)
+			m.table.SetColumns(columns)
+		}
+	}
+
+	sortedRows := make([]table.Row, len(rows))
+	unsorted := slices.Clone(items)
+	sortedCursor := 0
+	for i, j := range order {
+		sortedRows[i] = rows[j]
+		items[i] = unsorted[j]
+		if j == cursor {
+			sortedCursor = i
+		}
+	}
+	m.table.SetRows(sortedRows)
+	m.table.SetCursor(sortedCursor)
+}
+
+func sortIndicator(descending bool) string {
+	if descending {
+		return " ▼"
+	}
+	return " ▲"
+}
+
+// sortOrder returns the sort order chosen for the current view, if any
+func (m *Model) sortOrder() (config.SortOrder, bool) {
+	return m.userState.SortOrder(m.state.String())
+}
+
+// cycleSortColumn sorts the current view by its next displayed column, back to the default
+// order after the last one
+func (m *Model) cycleSortColumn() tea.Cmd {
+	return m.setSortOrder(m.nextSortOrder())
+}
+
+// nextSortOrder returns the sort order by the displayed column following the current sort
+// column, the default order after the last one
+func (m *Model) nextSortOrder() config.SortOrder {
+	sort, _ := m.sortOrder()
+	var titles []string
+	for _, column := range m.table.Columns() {
+		if column.Width <= 0 {
+			// Hidden on narrow windows
+			continue
+		}
+		title := strings.TrimSuffix(strings.TrimSuffix(column.Title, sortIndicator(false)), sortIndicator(true))
+		if !unsortableColumns[title] {
+			titles = append(titles, title)
+		}
+	}
+	next := slices.Index(titles, sort.Column) + 1
+	if next >= len(titles) {
+		return config.SortOrder{}
+	}
+	return config.SortOrder{Column: titles[next], Descending: sort.Descending}
+}
+
+// reverseSortOrder toggles the sort direction of the current view, sorting by its first
+// column if it wasn't sorted yet
+func (m *Model) reverseSortOrder() tea.Cmd {
+	sort, found := m.sortOrder()
+	if !found {
+		sort = m.nextSortOrder()
+		if sort == (config.SortOrder{}) {
+			return nil
+		}
+	}
+	sort.Descending = !sort.Descending
+	return m.setSortOrder(sort)
+}
+
+// setSortOrder changes the sort order of the current view and saves it for the next runs,
+// keeping the cursor on the selected item
+func (m *Model) setSortOrder(sort config.SortOrder) tea.Cmd {
+	m.userState.SetSortOrder(m.state.String(), sort)
+	m.refreshKeepingSelection()
+	if err := m.userState.Save(m.conf.StateFilePath); err != nil {
+		return m.notifyError("Failed to save the sort order", err, nil)
+	}
+	return nil
+}
+
+// refreshKeepingSelection refreshes the current view, keeping the cursor on the selected item
+// when the rows move, e.g. as they are sorted by resource usage
+func (m *Model) refreshKeepingSelection() {
+	selected := m.selectedKey()
+	m.refreshCurrentView()
+	m.selectRow(selected)
+}
+
+// compareCells compares two cells by the value they display, so that e.g. sizes, percentages
+// and replicas sort as numbers. Cells without a value ("-" or empty) come last.
+func compareCells(a, b string) int {
+	valueA, numberA := cellValue(a)
+	valueB, numberB := cellValue(b)
+	switch {
+	case numberA && numberB:
+		return cmp.Compare(valueA, valueB)
+	case numberA != numberB:
+		// Numbers before text, e.g. the sizes of volumes before the unknown ones
+		if numberA {
+			return -1
+		}
+		return 1
+	}
+	blankA, blankB := a == "" || a == "-", b == "" || b == "-"
+	if blankA != blankB {
+		if blankA {
+			return 1
+		}
+		return -1
+	}
+	return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// byteUnits are the units of formatBytes and of the memory usage
+var byteUnits = map[string]float64{
+	"B":   1,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+	"PiB": 1 << 50,
+	"EiB": 1 << 60,
+}
+
+// cellValue parses the number a cell displays: plain numbers, percentages, sizes, durations and
+// ratios such as replicas (running/desired), which compare by health. Only the first part of
+// cells like "12MiB / 1GiB" is considered.
+func cellValue(cell string) (float64, bool) {
+	cell, _, _ = strings.Cut(strings.TrimSpace(cell), " ")
+	// e.g. the output buffered by detached sessions
+	cell = strings.TrimPrefix(cell, "+")
+	if cell == "" {
+		return 0, false
+	}
+	if numerator, denominator, found := strings.Cut(cell, "/"); found {
+		running, err1 := strconv.ParseFloat(numerator, 64)
+		desired, err2 := strconv.ParseFloat(denominator, 64)
+		if err1 != nil || err2 != nil {
+			return 0, false
+		}
+		if desired == 0 {
+			// Nothing to run is healthy
+			return 1, true
+		}
+		return running / desired, true
+	}
+	if value, err := strconv.ParseFloat(strings.TrimSuffix(cell, "%"), 64); err == nil {
+		return value, true
+	}
+	// Sizes, e.g. 1.5MiB
+	end := strings.IndexFunc(cell, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if end > 0 {
+		if unit, found := byteUnits[cell[end:]]; found {
+			if value, err := strconv.ParseFloat(cell[:end], 64); err == nil {
+				return value * unit, true
+			}
+		}
+	}
+	if duration, err := time.ParseDuration(cell); err == nil {
+		return float64(duration), true
+	}
+	if days, found := strings.CutSuffix(cell, "d"); found {
+		if value, err := strconv.Atoi(days); err == nil {
+			return float64(time.Duration(value) * 24 * time.Hour), true
+		}
+	}
+	return 0, false
+}
+
+// formatAge renders how long ago something happened, in its largest unit (e.g. 5m, 3h, 2d)
+func formatAge(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return strconv.Itoa(int(age.Seconds())) + "s"
+	case age < time.Hour:
+		return strconv.Itoa(int(age.Minutes())) + "m"
+	case age < 24*time.Hour:
+		return strconv.Itoa(int(age.Hours())) + "h"
+	default:
+		return strconv.Itoa(int(age.Hours()/24)) + "d"
+	}
+}
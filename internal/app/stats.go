@@ -0,0 +1,184 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mendes11/swarm-browser/internal/app/commands"
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+// statsHistorySize is the number of samples kept for the sparklines
+const statsHistorySize = 30
+
+var sparklineLevels = []rune("▁▂▃▄▅▆▇█")
+
+// statsMonitor keeps the resource usage of the tasks displayed in the services and tasks views.
+//
+// Only one stream is active at a time. Every new stream gets a new ID, so messages
+// from streams that were already stopped are ignored.
+type statsMonitor struct {
+	streamID       int
+	cancel         context.CancelFunc
+	statsCh        <-chan models.ContainerStats
+	latest         map[string]models.ContainerStats // Keyed by task ID
+	serviceOf      map[string]string                // Maps task IDs to service IDs
+	taskHistory    map[string][]float64
+	serviceHistory map[string][]float64
+}
+
+func newStatsMonitor() *statsMonitor {
+	return &statsMonitor{}
+}
+
+// StartTasks starts streaming the stats of the tasks of a service
+func (s *statsMonitor) StartTasks(browser core.ClusterBrowser, service models.Service, tasks []models.Task) tea.Cmd {
+	ctx := s.reset()
+	return commands.StreamTaskStats(ctx, browser, s.streamID, service, tasks)
+}
+
+// StartServices starts streaming the stats of every task of the given services
+func (s *statsMonitor) StartServices(browser core.ClusterBrowser, services []models.Service) tea.Cmd {
+	ctx := s.reset()
+	return commands.StreamServicesStats(ctx, browser, s.streamID, services)
+}
+
+// Stop cancels the active stream, if any
+func (s *statsMonitor) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	s.statsCh = nil
+	s.streamID++
+}
+
+// Active reports whether the message stream ID belongs to the active stream
+func (s *statsMonitor) Active(streamID int) bool {
+	return s.cancel != nil && streamID == s.streamID
+}
+
+func (s *statsMonitor) reset() context.Context {
+	s.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.latest = make(map[string]models.ContainerStats)
+	s.serviceOf = make(map[string]string)
+	s.taskHistory = make(map[string][]float64)
+	s.serviceHistory = make(map[string][]float64)
+	return ctx
+}
+
+// Started records the opened stream, returning the command that waits for its first sample
+func (s *statsMonitor) Started(msg commands.StatsStreamStarted) tea.Cmd {
+	s.statsCh = msg.Stats
+	s.serviceOf = msg.ServiceOf
+	return tea.Batch(
+		commands.WaitForStats(s.streamID, s.statsCh),
+		commands.TickStats(s.streamID),
+	)
+}
+
+// Record stores the latest sample of a task, returning the command that waits for the next one
+func (s *statsMonitor) Record(stats models.ContainerStats) tea.Cmd {
+	s.latest[stats.TaskID] = stats
+	return commands.WaitForStats(s.streamID, s.statsCh)
+}
+
+// Sample appends the latest values to the tasks and services histories
+func (s *statsMonitor) Sample() {
+	serviceCPU := make(map[string]float64)
+	for taskID, stats := range s.latest {
+		s.taskHistory[taskID] = appendHistory(s.taskHistory[taskID], stats.CPUPercent)
+		if serviceID, exists := s.serviceOf[taskID]; exists {
+			serviceCPU[serviceID] += stats.CPUPercent
+		}
+	}
+	for serviceID, cpu := range serviceCPU {
+		s.serviceHistory[serviceID] = appendHistory(s.serviceHistory[serviceID], cpu)
+	}
+}
+
+// Task returns the latest sample of a task
+func (s *statsMonitor) Task(taskID string) (models.ContainerStats, bool) {
+	stats, exists := s.latest[taskID]
+	return stats, exists
+}
+
+// Service aggregates the latest samples of every task of a service
+func (s *statsMonitor) Service(serviceID string) (models.ContainerStats, bool) {
+	aggregate := models.ContainerStats{}
+	found := false
+	for taskID, stats := range s.latest {
+		if s.serviceOf[taskID] != serviceID {
+			continue
+		}
+		found = true
+		aggregate.CPUPercent += stats.CPUPercent
+		aggregate.MemoryUsage += stats.MemoryUsage
+		aggregate.MemoryLimit += stats.MemoryLimit
+		aggregate.NetRx += stats.NetRx
+		aggregate.NetTx += stats.NetTx
+		aggregate.BlockRead += stats.BlockRead
+		aggregate.BlockWrite += stats.BlockWrite
+	}
+	return aggregate, found
+}
+
+func (s *statsMonitor) TaskSparkline(taskID string, width int) string {
+	return sparkline(s.taskHistory[taskID], width)
+}
+
+func (s *statsMonitor) ServiceSparkline(serviceID string, width int) string {
+	return sparkline(s.serviceHistory[serviceID], width)
+}
+
+func appendHistory(history []float64, value float64) []float64 {
+	history = append(history, value)
+	if len(history) > statsHistorySize {
+		history = history[len(history)-statsHistorySize:]
+	}
+	return history
+}
+
+// sparkline renders the last width CPU values, scaled to 100% or to the highest value when above it
+func sparkline(values []float64, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+	highest := 100.0
+	for _, value := range values {
+		highest = max(highest, value)
+	}
+	var sb strings.Builder
+	for _, value := range values {
+		level := int(value / highest * float64(len(sparklineLevels)-1))
+		level = min(max(level, 0), len(sparklineLevels)-1)
+		sb.WriteRune(sparklineLevels[level])
+	}
+	return sb.String()
+}
+
+func formatCPU(stats models.ContainerStats, found bool) string {
+	if !found {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", stats.CPUPercent)
+}
+
+func formatMemory(stats models.ContainerStats, found bool) string {
+	if !found {
+		return "-"
+	}
+	return fmt.Sprintf("%s / %s", formatBytes(int64(stats.MemoryUsage)), formatBytes(int64(stats.MemoryLimit)))
+}
+
+func formatIO(in, out uint64, found bool) string {
+	if !found {
+		return "-"
+	}
+	return fmt.Sprintf("%s / %s", formatBytes(int64(in)), formatBytes(int64(out)))
+}
@@ -2,15 +2,74 @@ package app
 
 import "github.com/charmbracelet/lipgloss"
 
-var LabelStyle = lipgloss.NewStyle().Foreground(ColorLabel).Bold(true)
-var TextStyle = lipgloss.NewStyle().Foreground(ColorTextBody)
-var DisconnectedStyle = lipgloss.NewStyle().Foreground(ColorStatusStopped)
-var ConnectedStyle = lipgloss.NewStyle().Foreground(ColorStatusRunning)
-var ConnectingStyle = lipgloss.NewStyle().Foreground(ColorStatusPending)
+// Styles of the browser, built from the color palette by applyStyles
+var (
+	LabelStyle        lipgloss.Style
+	TextStyle         lipgloss.Style
+	DisconnectedStyle lipgloss.Style
+	ConnectedStyle    lipgloss.Style
+	ConnectingStyle   lipgloss.Style
+	TableStyle        lipgloss.Style
 
-var TableStyle = lipgloss.NewStyle().
-	BorderStyle(lipgloss.NormalBorder()).
-	BorderForeground(lipgloss.Color("240"))
+	// Navigation
+	breadcrumbStyle        lipgloss.Style
+	breadcrumbCurrentStyle lipgloss.Style
+	loadingStyle           lipgloss.Style
+
+	// Details of the selected item
+	detailHeadingStyle lipgloss.Style
+
+	// Container sessions
+	sessionTabStyle       lipgloss.Style
+	sessionActiveTabStyle lipgloss.Style
+	sessionUnreadTabStyle lipgloss.Style
+	containerStatusStyle  lipgloss.Style
+
+	// Command results
+	execStderrStyle  lipgloss.Style
+	execHeadingStyle lipgloss.Style
+
+	// Status bar and error log
+	notificationInfoStyle  lipgloss.Style
+	notificationErrorStyle lipgloss.Style
+	notificationHintStyle  lipgloss.Style
+	errorHeadingStyle      lipgloss.Style
+)
 
 var AppHeaderStyle = lipgloss.NewStyle().
 	PaddingLeft(2)
+
+// applyStyles builds the styles from the current color palette
+func applyStyles() {
+	LabelStyle = lipgloss.NewStyle().Foreground(ColorLabel).Bold(true)
+	TextStyle = lipgloss.NewStyle().Foreground(ColorTextBody)
+	DisconnectedStyle = lipgloss.NewStyle().Foreground(ColorStatusStopped)
+	ConnectedStyle = lipgloss.NewStyle().Foreground(ColorStatusRunning)
+	ConnectingStyle = lipgloss.NewStyle().Foreground(ColorStatusPending)
+
+	TableStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(ColorTableBorder)
+
+	breadcrumbStyle = lipgloss.NewStyle().Foreground(ColorTextSecondary)
+	breadcrumbCurrentStyle = lipgloss.NewStyle().Foreground(ColorTitle).Bold(true)
+	loadingStyle = lipgloss.NewStyle().Foreground(ColorStatusPending)
+
+	detailHeadingStyle = lipgloss.NewStyle().Foreground(ColorTitle).Bold(true)
+
+	sessionTabStyle = lipgloss.NewStyle().Foreground(ColorTextSecondary).Background(ColorBgPanel).Padding(0, 1)
+	sessionActiveTabStyle = lipgloss.NewStyle().Foreground(ColorTextOnPrimary).Background(ColorPrimary).Bold(true).Padding(0, 1)
+	sessionUnreadTabStyle = sessionTabStyle.Foreground(ColorWarning)
+	containerStatusStyle = lipgloss.NewStyle().
+		Foreground(ColorHelpText).
+		Background(ColorBgSubtle).
+		PaddingLeft(1)
+
+	execStderrStyle = lipgloss.NewStyle().Foreground(ColorError)
+	execHeadingStyle = lipgloss.NewStyle().Foreground(ColorTextSecondary).Bold(true)
+
+	notificationInfoStyle = lipgloss.NewStyle().Foreground(ColorSuccess)
+	notificationErrorStyle = lipgloss.NewStyle().Foreground(ColorError)
+	notificationHintStyle = lipgloss.NewStyle().Foreground(ColorTextSecondary)
+	errorHeadingStyle = lipgloss.NewStyle().Foreground(ColorTextSecondary).Bold(true)
+}
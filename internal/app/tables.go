@@ -2,96 +2,94 @@ package app
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mendes11/swarm-browser/internal/app/commands"
+	"github.com/mendes11/swarm-browser/internal/config"
 	"github.com/mendes11/swarm-browser/internal/core/models"
 )
 
-func (m *Model) showStacksTable(stacks []models.Stack, selectedStack *models.Stack) {
+func (m *Model) showStacksTable(stacks []models.Stack) {
 	rows := make([]table.Row, len(stacks))
-	cursor := 0
 	for i, stack := range stacks {
 		rows[i] = []string{stack.Name}
-		if selectedStack != nil && stack == *selectedStack {
-			cursor = i
-		}
 	}
 
 	m.table = newTable(m.keys.Table)
 	m.table.SetHeight(m.tableHeight())
 	m.table.SetWidth(m.tableWidth())
 
-	m.table.SetColumns([]table.Column{{Title: "Name", Width: m.table.Width()}})
-	m.table.SetRows(rows)
-	m.table.SetCursor(cursor)
+	m.table.SetColumns(fitColumns(m.table.Width(), []column{{title: "Name", flex: true}}))
+	sortRows(m, rows, stacks, 0)
 }
 
-func (m *Model) showServicesTable(services []models.Service, selectedService *models.Service) {
+func (m *Model) showServicesTable(services []models.Service) {
+	sparklineWidth := 12
 	rows := make([]table.Row, len(services))
-	cursor := 0
 	for i, service := range services {
+		stats, found := m.stats.Service(service.ID)
 		rows[i] = []string{
 			service.ID,
 			service.Name,
 			fmt.Sprintf("%d/%d", service.RunningTasks, service.DesiredTasks),
-		}
-		if selectedService != nil && *selectedService == service {
-			cursor = i
+			formatCPU(stats, found),
+			m.stats.ServiceSparkline(service.ID, sparklineWidth),
+			formatMemory(stats, found),
 		}
 	}
 	m.table = newTable(m.keys.Table)
 	m.table.SetWidth(m.tableWidth())
 	m.table.SetHeight(m.tableHeight())
-	// Calculate column widths based on table width
-	tableWidth := m.table.Width()
-	idWidth := 20
-	replicasWidth := 12
-	nameWidth := tableWidth - idWidth - replicasWidth - 4 // Account for borders
-
-	m.table.SetColumns([]table.Column{
-		{Title: "ID", Width: idWidth},
-		{Title: "Name", Width: nameWidth},
-		{Title: "Replicas", Width: replicasWidth},
-	})
-	m.table.SetRows(rows)
-	m.table.SetCursor(cursor)
-}
-
-func (m *Model) showTasksTable(tasks []models.Task, selectedTask *models.Task) {
+	m.table.SetColumns(fitColumns(m.table.Width(), []column{
+		{title: "ID", width: 20, min: 12},
+		{title: "Name", min: 24, flex: true},
+		{title: "Replicas", width: 12, min: 8},
+		{title: "CPU", width: 8, hide: 2},
+		{title: "CPU History", width: sparklineWidth, hide: 4},
+		{title: "Memory", width: 20, min: 10, hide: 3},
+	}))
+	sortRows(m, rows, services, 0)
+}
+
+func (m *Model) showTasksTable(tasks []models.Task) {
+	sparklineWidth := 12
 	rows := make([]table.Row, len(tasks))
-	cursor := 0
 	for i, task := range tasks {
+		stats, found := m.stats.Task(task.TaskID)
 		rows[i] = []string{
 			task.TaskID,
 			task.ContainerID,
 			fmt.Sprintf("%s", task.Status),
 			task.Node.Host,
-		}
-		if selectedTask != nil && *selectedTask == task {
-			cursor = i
+			formatCPU(stats, found),
+			m.stats.TaskSparkline(task.TaskID, sparklineWidth),
+			formatMemory(stats, found),
+			formatIO(stats.NetRx, stats.NetTx, found),
+			formatIO(stats.BlockRead, stats.BlockWrite, found),
+			formatAge(task.CreatedAt),
 		}
 	}
 
 	m.table = newTable(m.keys.Table)
 	m.table.SetWidth(m.tableWidth())
 	m.table.SetHeight(m.tableHeight())
-	// Calculate column widths based on table width
-	tableWidth := m.table.Width()
-	idWidth := 20
-	containerIdWidth := 20
-	statusWidth := 12
-	nodeWidth := tableWidth - idWidth - containerIdWidth - statusWidth - 4 // Account for borders
-
-	m.table.SetColumns([]table.Column{
-		{Title: "ID", Width: idWidth},
-		{Title: "ContainerID", Width: containerIdWidth},
-		{Title: "Status", Width: statusWidth},
-		{Title: "Node", Width: nodeWidth},
-	})
-	m.table.SetRows(rows)
-	m.table.SetCursor(cursor)
+	m.table.SetColumns(fitColumns(m.table.Width(), []column{
+		{title: "ID", width: 14, min: 12},
+		{title: "ContainerID", width: 14, hide: 7},
+		{title: "Status", width: 10, min: 8},
+		{title: "Node", min: 10, flex: true},
+		{title: "CPU", width: 8, hide: 4},
+		{title: "CPU History", width: sparklineWidth, hide: 8},
+		{title: "Memory", width: 20, min: 10, hide: 5},
+		{title: "Net I/O", width: 20, min: 12, hide: 9},
+		{title: "Block I/O", width: 20, min: 12, hide: 10},
+		{title: "Age", width: 6, hide: 6},
+	}))
+	sortRows(m, rows, tasks, 0)
 }
 
 func (m *Model) showClustersTable(clusters []commands.ClusterTableRow, currentClusterName string) {
@@ -115,22 +113,324 @@ func (m *Model) showClustersTable(clusters []commands.ClusterTableRow, currentCl
 	m.table = newTable(m.keys.Table)
 	m.table.SetWidth(m.tableWidth())
 	m.table.SetHeight(m.tableHeight())
+	m.table.SetColumns(fitColumns(m.table.Width(), []column{
+		{title: "", width: 3}, // Status column (arrow for current)
+		{title: "Name", width: 20, min: 10},
+		{title: "Nodes", width: 7, hide: 1},
+		{title: "Host", min: 10, flex: true, hide: 2},
+	}))
+	sortRows(m, rows, clusters, cursor)
+}
+
+func (m *Model) showNetworksTable(networks []models.Network) {
+	rows := make([]table.Row, len(networks))
+	for i, network := range networks {
+		rows[i] = []string{
+			network.Name,
+			network.Driver,
+			network.Scope,
+			network.SubnetsString(),
+			yesNo(network.Attachable),
+			yesNo(network.Ingress),
+		}
+	}
+
+	m.table = newTable(m.keys.Table)
+	m.table.SetWidth(m.tableWidth())
+	m.table.SetHeight(m.tableHeight())
+	m.table.SetColumns(fitColumns(m.table.Width(), []column{
+		{title: "Name", min: 16, flex: true},
+		{title: "Driver", width: 10, min: 8},
+		{title: "Scope", width: 8, hide: 2},
+		{title: "Subnet", width: 20, min: 14, hide: 1},
+		{title: "Attachable", width: 10, hide: 4},
+		{title: "Ingress", width: 8, hide: 3},
+	}))
+	sortRows(m, rows, networks, 0)
+}
+
+func (m *Model) showNetworkAttachmentsTable(attachments []models.NetworkAttachment) {
+	rows := make([]table.Row, len(attachments))
+	for i, attachment := range attachments {
+		taskID := attachment.TaskID
+		if taskID == "" {
+			taskID = "VIP"
+		}
+		rows[i] = []string{
+			attachment.Service.Name,
+			taskID,
+			attachment.Node.Hostname,
+			attachment.Address,
+		}
+	}
+
+	m.table = newTable(m.keys.Table)
+	m.table.SetWidth(m.tableWidth())
+	m.table.SetHeight(m.tableHeight())
+	m.table.SetColumns(fitColumns(m.table.Width(), []column{
+		{title: "Service", min: 16, flex: true},
+		{title: "Task", width: 28, min: 12},
+		{title: "Node", width: 20, min: 10, hide: 2},
+		{title: "Address", width: 20, min: 14, hide: 1},
+	}))
+	sortRows(m, rows, attachments, 0)
+}
+
+func (m *Model) showTaskMountsTable(mounts []models.Mount) {
+	rows := make([]table.Row, len(mounts))
+	for i, mount := range mounts {
+		mode := "ro"
+		if mount.RW {
+			mode = "rw"
+		}
+		rows[i] = []string{
+			mount.Type,
+			mount.Name,
+			mount.Destination,
+			mount.Driver,
+			mode,
+			formatBytes(mount.Size),
+		}
+	}
+
+	m.table = newTable(m.keys.Table)
+	m.table.SetWidth(m.tableWidth())
+	m.table.SetHeight(m.tableHeight())
+	m.table.SetColumns(fitColumns(m.table.Width(), []column{
+		{title: "Type", width: 8, hide: 3},
+		{title: "Volume", width: 30, min: 12},
+		{title: "Destination", min: 16, flex: true},
+		{title: "Driver", width: 10, hide: 4},
+		{title: "Mode", width: 5, hide: 1},
+		{title: "Size", width: 10, hide: 2},
+	}))
+	sortRows(m, rows, mounts, 0)
+}
+
+func (m *Model) showNodeVolumesTable(volumes []models.Volume) {
+	rows := make([]table.Row, len(volumes))
+	for i, volume := range volumes {
+		refCount := "-"
+		if volume.RefCount >= 0 {
+			refCount = fmt.Sprintf("%d", volume.RefCount)
+		}
+		rows[i] = []string{
+			volume.Name,
+			volume.Driver,
+			volume.Scope,
+			refCount,
+			formatBytes(volume.Size),
+		}
+	}
+
+	m.table = newTable(m.keys.Table)
+	m.table.SetWidth(m.tableWidth())
+	m.table.SetHeight(m.tableHeight())
+	m.table.SetColumns(fitColumns(m.table.Width(), []column{
+		{title: "Name", min: 16, flex: true},
+		{title: "Driver", width: 10, hide: 3},
+		{title: "Scope", width: 8, hide: 4},
+		{title: "Used", width: 6, hide: 1},
+		{title: "Size", width: 10, hide: 2},
+	}))
+	sortRows(m, rows, volumes, 0)
+}
+
+func (m *Model) showFilesTable(files []models.FileInfo) {
+	rows := make([]table.Row, len(files))
+	for i, file := range files {
+		rows[i] = []string{
+			fileKey(file),
+			formatBytes(file.Size),
+			file.Mode.String(),
+			file.ModTime.Format("2006-01-02 15:04"),
+		}
+	}
+
+	m.table = newTable(m.keys.Table)
+	m.table.SetWidth(m.tableWidth())
+	m.table.SetHeight(m.tableHeight())
+	m.table.SetColumns(fitColumns(m.table.Width(), []column{
+		{title: "Name", min: 16, flex: true},
+		{title: "Size", width: 10, hide: 1},
+		{title: "Mode", width: 12, hide: 3},
+		{title: "Modified", width: 16, hide: 2},
+	}))
+	sortRows(m, rows, files, 0)
+}
+
+func (m *Model) showPortForwardsTable(forwards []models.PortForward) {
+	rows := make([]table.Row, len(forwards))
+	for i, forward := range forwards {
+		rows[i] = []string{
+			forward.LocalAddr(),
+			fmt.Sprintf("%s:%d", forward.RemoteAddr, forward.RemotePort),
+			forward.Task.TaskID,
+			forward.Task.Node.Hostname,
+			forward.StartedAt.Format("15:04:05"),
+		}
+	}
+
+	m.table = newTable(m.keys.Table)
+	m.table.SetWidth(m.tableWidth())
+	m.table.SetHeight(m.tableHeight())
+	m.table.SetColumns(fitColumns(m.table.Width(), []column{
+		{title: "Local", width: 16},
+		{title: "Remote", width: 22, min: 14},
+		{title: "Task", width: 26, min: 12, hide: 2},
+		{title: "Node", min: 10, flex: true, hide: 3},
+		{title: "Since", width: 10, hide: 1},
+	}))
+	sortRows(m, rows, forwards, 0)
+}
 
-	// Calculate column widths based on table width
-	tableWidth := m.table.Width()
-	statusWidth := 3
-	nameWidth := 20
-	nodesWidth := 7
-	hostWidth := tableWidth - statusWidth - nameWidth - nodesWidth - 4 // Account for borders
+func (m *Model) showExecResultsTable(results []models.ExecResult) {
+	rows := make([]table.Row, len(results))
+	for i, result := range results {
+		exitCode := strconv.Itoa(result.ExitCode)
+		if result.Err != nil {
+			exitCode = "error"
+		}
+		rows[i] = []string{
+			result.Task.TaskID,
+			result.Task.Node.Hostname,
+			exitCode,
+			result.Duration.Round(time.Millisecond).String(),
+			firstOutputLine(result),
+		}
+	}
 
-	m.table.SetColumns([]table.Column{
-		{Title: "", Width: statusWidth}, // Status column (arrow for current)
-		{Title: "Name", Width: nameWidth},
-		{Title: "Nodes", Width: nodesWidth},
-		{Title: "Host", Width: hostWidth},
-	})
-	m.table.SetRows(rows)
-	m.table.SetCursor(cursor)
+	m.table = newTable(m.keys.Table)
+	m.table.SetWidth(m.tableWidth())
+	m.table.SetHeight(m.tableHeight())
+	m.table.SetColumns(fitColumns(m.table.Width(), []column{
+		{title: "Task", width: 26, min: 12},
+		{title: "Node", width: 20, min: 10, hide: 2},
+		{title: "Exit", width: 6},
+		{title: "Duration", width: 10, hide: 1},
+		{title: "Output", min: 10, flex: true},
+	}))
+	sortRows(m, rows, results, 0)
+}
+
+func (m *Model) showSessionsTable(sessions []*ContainerSession) {
+	rows := make([]table.Row, len(sessions))
+	for i, session := range sessions {
+		state := "attached"
+		activity := ""
+		if session.Unread {
+			activity = "●"
+		}
+		if info, ok := m.sessions.ExecInfo(session); ok {
+			state = "detached"
+			if info.Exited {
+				state = "exited"
+			}
+			if info.Buffered > 0 {
+				activity = fmt.Sprintf("+%s", formatBytes(int64(info.Buffered)))
+			}
+		}
+		rows[i] = []string{
+			strconv.Itoa(session.ID),
+			session.Label,
+			session.Cluster,
+			state,
+			activity,
+			session.Title,
+		}
+	}
+
+	m.table = newTable(m.keys.Table)
+	m.table.SetWidth(m.tableWidth())
+	m.table.SetHeight(m.tableHeight())
+	m.table.SetColumns(fitColumns(m.table.Width(), []column{
+		{title: "#", width: 4},
+		{title: "Session", width: 24, min: 12},
+		{title: "Cluster", width: 16, min: 10, hide: 2},
+		{title: "State", width: 10},
+		{title: "Unread", width: 10, hide: 1},
+		{title: "Target", min: 10, flex: true, hide: 3},
+	}))
+	sortRows(m, rows, slices.Clone(sessions), 0)
+}
+
+func (m *Model) showBookmarksTable(bookmarks []config.Bookmark) {
+	rows := make([]table.Row, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		rows[i] = []string{
+			bookmark.Name,
+			bookmark.Cluster,
+			bookmark.Stack,
+			bookmark.Service,
+			bookmark.Filter,
+		}
+	}
+
+	m.table = newTable(m.keys.Table)
+	m.table.SetWidth(m.tableWidth())
+	m.table.SetHeight(m.tableHeight())
+	m.table.SetColumns(fitColumns(m.table.Width(), []column{
+		{title: "Name", width: 20, min: 12},
+		{title: "Cluster", width: 16, min: 10, hide: 3},
+		{title: "Stack", width: 20, min: 10, hide: 2},
+		{title: "Service", width: 28, min: 12, hide: 1},
+		{title: "Filter", min: 10, flex: true, hide: 4},
+	}))
+	// Sorted on a copy, the bookmarks being kept in the order they were added
+	sortRows(m, rows, slices.Clone(bookmarks), 0)
+}
+
+func (m *Model) showErrorLogTable(errors []notification) {
+	rows := make([]table.Row, len(errors))
+	for i, logged := range errors {
+		rows[i] = []string{
+			strconv.Itoa(logged.id),
+			logged.at.Format(time.DateTime),
+			logged.cluster,
+			logged.message,
+			rootCause(logged.err),
+		}
+	}
+
+	m.table = newTable(m.keys.Table)
+	m.table.SetWidth(m.tableWidth())
+	m.table.SetHeight(m.tableHeight())
+	m.table.SetColumns(fitColumns(m.table.Width(), []column{
+		{title: "#", width: 4},
+		{title: "Time", width: 19, hide: 2},
+		{title: "Cluster", width: 16, min: 10, hide: 1},
+		{title: "Error", width: 40, min: 20},
+		{title: "Cause", min: 10, flex: true, hide: 3},
+	}))
+	// The newest errors first, on a copy of the log
+	errors = slices.Clone(errors)
+	slices.Reverse(errors)
+	slices.Reverse(rows)
+	sortRows(m, rows, errors, 0)
+}
+
+// formatBytes renders a size in bytes using binary units, or "-" when unknown
+func formatBytes(size int64) string {
+	if size < 0 {
+		return "-"
+	}
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
 }
 
 func newTable(keyMap table.KeyMap) table.Model {
@@ -138,16 +438,21 @@ func newTable(keyMap table.KeyMap) table.Model {
 		table.WithFocused(true),
 		table.WithKeyMap(keyMap),
 	)
+	t.SetStyles(tableStyles())
+	return t
+}
+
+// tableStyles returns the styles of the tables, with a border under the column headers
+func tableStyles() table.Styles {
 	s := table.DefaultStyles()
 	s.Header = s.Header.
 		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("240")).
+		BorderForeground(ColorTableBorder).
 		BorderBottom(true).
 		Bold(false)
 	s.Selected = s.Selected.
-		Foreground(lipgloss.Color("229")).
-		Background(lipgloss.Color("57")).
+		Foreground(ColorTableSelected).
+		Background(ColorTableSelectedBg).
 		Bold(false)
-	t.SetStyles(s)
-	return t
+	return s
 }
@@ -1,22 +1,116 @@
 package app
 
 import (
+	"fmt"
+
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mendes11/swarm-browser/internal/terminal"
 )
 
-// KeyToBytes converts a Bubbletea KeyMsg to the actual bytes that should be sent to a terminal
-func KeyToBytes(msg tea.KeyMsg) []byte {
-	// Special handling for detach key
-	if msg.String() == "ctrl+\\" || msg.String() == "ctrl+backslash" {
-		return nil // Signal to detach, don't send to terminal
+// EncodeKey converts a key to terminal bytes according to the modes requested by the
+// application: cursor keys in application mode (DECCKM) and bracketed paste.
+func EncodeKey(msg tea.KeyMsg, appCursorKeys bool, bracketedPaste bool) []byte {
+	if msg.Paste && bracketedPaste {
+		data := []byte("\x1b[200~")
+		data = append(data, string(msg.Runes)...)
+		return append(data, "\x1b[201~"...)
+	}
+	if appCursorKeys && !msg.Alt {
+		switch msg.Type {
+		case tea.KeyUp:
+			return []byte{0x1b, 'O', 'A'}
+		case tea.KeyDown:
+			return []byte{0x1b, 'O', 'B'}
+		case tea.KeyRight:
+			return []byte{0x1b, 'O', 'C'}
+		case tea.KeyLeft:
+			return []byte{0x1b, 'O', 'D'}
+		case tea.KeyHome:
+			return []byte{0x1b, 'O', 'H'}
+		case tea.KeyEnd:
+			return []byte{0x1b, 'O', 'F'}
+		}
+	}
+	data := KeyToBytes(msg)
+	if msg.Alt && msg.Type != tea.KeyRunes && len(data) > 0 {
+		// Alt combinations of special keys are prefixed with ESC
+		return append([]byte{0x1b}, data...)
+	}
+	return data
+}
+
+// MouseToBytes encodes a mouse event at the given terminal cell for the tracking mode
+// requested by the application, using the SGR (1006) or the legacy X10 format.
+// It returns nil for events the mode doesn't report.
+func MouseToBytes(msg tea.MouseMsg, x, y int, mode terminal.MouseMode, sgr bool) []byte {
+	var button int
+	switch msg.Button {
+	case tea.MouseButtonLeft:
+		button = 0
+	case tea.MouseButtonMiddle:
+		button = 1
+	case tea.MouseButtonRight:
+		button = 2
+	case tea.MouseButtonNone:
+		button = 3
+	case tea.MouseButtonWheelUp:
+		button = 64
+	case tea.MouseButtonWheelDown:
+		button = 65
+	case tea.MouseButtonWheelLeft:
+		button = 66
+	case tea.MouseButtonWheelRight:
+		button = 67
+	default:
+		return nil
+	}
+
+	switch msg.Action {
+	case tea.MouseActionMotion:
+		if mode == terminal.MouseClick || (mode == terminal.MouseDrag && msg.Button == tea.MouseButtonNone) {
+			return nil
+		}
+		button += 32
+	case tea.MouseActionRelease:
+		if !sgr {
+			// X10 doesn't tell which button was released
+			button = 3
+		}
+	}
+	if msg.Shift {
+		button += 4
+	}
+	if msg.Alt {
+		button += 8
+	}
+	if msg.Ctrl {
+		button += 16
+	}
+
+	if sgr {
+		final := 'M'
+		if msg.Action == tea.MouseActionRelease {
+			final = 'm'
+		}
+		return []byte(fmt.Sprintf("\x1b[<%d;%d;%d%c", button, x+1, y+1, final))
+	}
+	// X10 coordinates are limited to a single byte
+	if x > 222 || y > 222 {
+		return nil
 	}
+	return []byte{0x1b, '[', 'M', byte(32 + button), byte(33 + x), byte(33 + y)}
+}
 
+// KeyToBytes converts a Bubbletea KeyMsg to the actual bytes that should be sent to a terminal
+func KeyToBytes(msg tea.KeyMsg) []byte {
 	// Map tea.KeyType to the corresponding terminal sequence
 	switch msg.Type {
 	case tea.KeyEnter: // Also handles tea.KeyCtrlM (same value)
 		return []byte{'\r'} // Carriage return
 	case tea.KeyTab: // Also handles tea.KeyCtrlI (same value)
 		return []byte{'\t'}
+	case tea.KeyShiftTab:
+		return []byte{0x1b, '[', 'Z'}
 	case tea.KeyBackspace:
 		return []byte{0x7f} // DEL character (127)
 	case tea.KeyEscape: // Also handles tea.KeyCtrlOpenBracket (same value)
@@ -33,6 +127,22 @@ func KeyToBytes(msg tea.KeyMsg) []byte {
 		return []byte{0x1b, '[', 'C'}
 	case tea.KeyLeft:
 		return []byte{0x1b, '[', 'D'}
+	case tea.KeyShiftUp:
+		return []byte("\x1b[1;2A")
+	case tea.KeyShiftDown:
+		return []byte("\x1b[1;2B")
+	case tea.KeyShiftRight:
+		return []byte("\x1b[1;2C")
+	case tea.KeyShiftLeft:
+		return []byte("\x1b[1;2D")
+	case tea.KeyCtrlUp:
+		return []byte("\x1b[1;5A")
+	case tea.KeyCtrlDown:
+		return []byte("\x1b[1;5B")
+	case tea.KeyCtrlRight:
+		return []byte("\x1b[1;5C")
+	case tea.KeyCtrlLeft:
+		return []byte("\x1b[1;5D")
 
 	// Control sequences
 	case tea.KeyCtrlA:
@@ -84,7 +194,7 @@ func KeyToBytes(msg tea.KeyMsg) []byte {
 	case tea.KeyCtrlZ:
 		return []byte{0x1a}
 	case tea.KeyCtrlBackslash: // Ctrl+\
-		return nil // Signal to detach
+		return []byte{0x1c}
 	case tea.KeyCtrlCloseBracket: // Ctrl+]
 		return []byte{0x1d}
 	case tea.KeyCtrlCaret: // Ctrl+^
@@ -0,0 +1,308 @@
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mendes11/swarm-browser/internal/config"
+)
+
+// Theme is a color palette of the browser, applied with ApplyTheme
+type Theme struct {
+	// Dark themes are meant for terminals with a dark background
+	Dark bool
+
+	Primary       lipgloss.Color
+	Secondary     lipgloss.Color
+	Accent        lipgloss.Color
+	TextOnPrimary lipgloss.Color
+
+	TextBody      lipgloss.Color
+	TextSecondary lipgloss.Color
+	TextMuted     lipgloss.Color
+	TextHighlight lipgloss.Color
+
+	Title       lipgloss.Color
+	Label       lipgloss.Color
+	Border      lipgloss.Color
+	BorderFocus lipgloss.Color
+
+	Selected   lipgloss.Color
+	SelectedBg lipgloss.Color
+	Hover      lipgloss.Color
+	Disabled   lipgloss.Color
+
+	HelpKey  lipgloss.Color
+	HelpText lipgloss.Color
+	Info     lipgloss.Color
+	Success  lipgloss.Color
+	Warning  lipgloss.Color
+	Error    lipgloss.Color
+
+	StatusRunning lipgloss.Color
+	StatusStopped lipgloss.Color
+	StatusError   lipgloss.Color
+	StatusPending lipgloss.Color
+
+	BgSubtle lipgloss.Color
+	BgPanel  lipgloss.Color
+
+	TableBorder     lipgloss.Color
+	TableSelected   lipgloss.Color
+	TableSelectedBg lipgloss.Color
+}
+
+// DarkTheme is tuned for dark terminals
+var DarkTheme = Theme{
+	Dark:            true,
+	Primary:         lipgloss.Color("#00A7E1"), // Docker blue
+	Secondary:       lipgloss.Color("#00D4FF"), // Bright cyan
+	Accent:          lipgloss.Color("#FF6B6B"), // Coral red
+	TextOnPrimary:   lipgloss.Color("#FFFFFF"), // White
+	TextBody:        lipgloss.Color("#FFFFFF"), // White
+	TextSecondary:   lipgloss.Color("#A0A0A0"), // Gray
+	TextMuted:       lipgloss.Color("#666666"), // Dim gray
+	TextHighlight:   lipgloss.Color("#00FFA3"), // Bright green
+	Title:           lipgloss.Color("#FFD700"), // Gold
+	Label:           lipgloss.Color("#87CEEB"), // Sky blue
+	Border:          lipgloss.Color("#4A5568"), // Slate gray
+	BorderFocus:     lipgloss.Color("#00D4FF"), // Bright cyan
+	Selected:        lipgloss.Color("#00FFA3"), // Bright green
+	SelectedBg:      lipgloss.Color("#1A4D2E"), // Dark green
+	Hover:           lipgloss.Color("#FFB84D"), // Orange
+	Disabled:        lipgloss.Color("#4A4A4A"), // Dark gray
+	HelpKey:         lipgloss.Color("#7C3AED"), // Purple
+	HelpText:        lipgloss.Color("#9CA3AF"), // Light gray
+	Info:            lipgloss.Color("#60A5FA"), // Blue
+	Success:         lipgloss.Color("#34D399"), // Green
+	Warning:         lipgloss.Color("#FBBF24"), // Amber
+	Error:           lipgloss.Color("#EF4444"), // Red
+	StatusRunning:   lipgloss.Color("#10B981"), // Green
+	StatusStopped:   lipgloss.Color("#6B7280"), // Gray
+	StatusError:     lipgloss.Color("#DC2626"), // Red
+	StatusPending:   lipgloss.Color("#F59E0B"), // Orange
+	BgSubtle:        lipgloss.Color("#1F2937"), // Dark blue-gray
+	BgPanel:         lipgloss.Color("#111827"), // Darker blue-gray
+	TableBorder:     lipgloss.Color("240"),     // ANSI gray
+	TableSelected:   lipgloss.Color("229"),     // ANSI light yellow
+	TableSelectedBg: lipgloss.Color("57"),      // ANSI purple
+}
+
+// LightTheme is tuned for light terminals, with darker text and softer backgrounds
+var LightTheme = Theme{
+	Dark:            false,
+	Primary:         lipgloss.Color("#0077B6"), // Deep Docker blue
+	Secondary:       lipgloss.Color("#0369A1"), // Dark cyan
+	Accent:          lipgloss.Color("#C53030"), // Dark red
+	TextOnPrimary:   lipgloss.Color("#FFFFFF"), // White
+	TextBody:        lipgloss.Color("#1A1A1A"), // Near black
+	TextSecondary:   lipgloss.Color("#4A5568"), // Slate gray
+	TextMuted:       lipgloss.Color("#718096"), // Gray
+	TextHighlight:   lipgloss.Color("#047857"), // Dark green
+	Title:           lipgloss.Color("#92400E"), // Brown
+	Label:           lipgloss.Color("#1E40AF"), // Navy
+	Border:          lipgloss.Color("#A0AEC0"), // Light slate
+	BorderFocus:     lipgloss.Color("#0369A1"), // Dark cyan
+	Selected:        lipgloss.Color("#047857"), // Dark green
+	SelectedBg:      lipgloss.Color("#D1FAE5"), // Pale green
+	Hover:           lipgloss.Color("#C05621"), // Dark orange
+	Disabled:        lipgloss.Color("#CBD5E0"), // Light gray
+	HelpKey:         lipgloss.Color("#6D28D9"), // Purple
+	HelpText:        lipgloss.Color("#4B5563"), // Dark gray
+	Info:            lipgloss.Color("#1D4ED8"), // Blue
+	Success:         lipgloss.Color("#047857"), // Dark green
+	Warning:         lipgloss.Color("#B45309"), // Dark amber
+	Error:           lipgloss.Color("#B91C1C"), // Dark red
+	StatusRunning:   lipgloss.Color("#047857"), // Dark green
+	StatusStopped:   lipgloss.Color("#6B7280"), // Gray
+	StatusError:     lipgloss.Color("#B91C1C"), // Dark red
+	StatusPending:   lipgloss.Color("#B45309"), // Dark amber
+	BgSubtle:        lipgloss.Color("#E5E7EB"), // Light gray
+	BgPanel:         lipgloss.Color("#F3F4F6"), // Lighter gray
+	TableBorder:     lipgloss.Color("#A0AEC0"), // Light slate
+	TableSelected:   lipgloss.Color("#FFFFFF"), // White
+	TableSelectedBg: lipgloss.Color("#0077B6"), // Deep Docker blue
+}
+
+// HighContrastTheme uses saturated colors over black, for low vision or washed out screens
+var HighContrastTheme = Theme{
+	Dark:            true,
+	Primary:         lipgloss.Color("#0000FF"), // Blue
+	Secondary:       lipgloss.Color("#00FFFF"), // Cyan
+	Accent:          lipgloss.Color("#FF5555"), // Red
+	TextOnPrimary:   lipgloss.Color("#FFFFFF"), // White
+	TextBody:        lipgloss.Color("#FFFFFF"), // White
+	TextSecondary:   lipgloss.Color("#FFFFFF"), // White
+	TextMuted:       lipgloss.Color("#D0D0D0"), // Light gray
+	TextHighlight:   lipgloss.Color("#00FF00"), // Green
+	Title:           lipgloss.Color("#FFFF00"), // Yellow
+	Label:           lipgloss.Color("#00FFFF"), // Cyan
+	Border:          lipgloss.Color("#FFFFFF"), // White
+	BorderFocus:     lipgloss.Color("#FFFF00"), // Yellow
+	Selected:        lipgloss.Color("#000000"), // Black
+	SelectedBg:      lipgloss.Color("#FFFF00"), // Yellow
+	Hover:           lipgloss.Color("#FFFF00"), // Yellow
+	Disabled:        lipgloss.Color("#808080"), // Gray
+	HelpKey:         lipgloss.Color("#FFFF00"), // Yellow
+	HelpText:        lipgloss.Color("#FFFFFF"), // White
+	Info:            lipgloss.Color("#00FFFF"), // Cyan
+	Success:         lipgloss.Color("#00FF00"), // Green
+	Warning:         lipgloss.Color("#FFFF00"), // Yellow
+	Error:           lipgloss.Color("#FF5555"), // Red
+	StatusRunning:   lipgloss.Color("#00FF00"), // Green
+	StatusStopped:   lipgloss.Color("#D0D0D0"), // Light gray
+	StatusError:     lipgloss.Color("#FF5555"), // Red
+	StatusPending:   lipgloss.Color("#FFFF00"), // Yellow
+	BgSubtle:        lipgloss.Color("#000000"), // Black
+	BgPanel:         lipgloss.Color("#000000"), // Black
+	TableBorder:     lipgloss.Color("#FFFFFF"), // White
+	TableSelected:   lipgloss.Color("#000000"), // Black
+	TableSelectedBg: lipgloss.Color("#FFFF00"), // Yellow
+}
+
+// builtinThemes are the themes shipped with the browser, by name
+var builtinThemes = map[string]Theme{
+	"dark":          DarkTheme,
+	"light":         LightTheme,
+	"high-contrast": HighContrastTheme,
+}
+
+// The dark theme until one is applied, leaving the terminal background to be detected
+func init() {
+	setPalette(DarkTheme)
+}
+
+// LoadTheme returns the theme with the given name: a built-in theme, one of the user-defined
+// themes, or auto (or an empty name) for the dark or light theme after the terminal background
+func LoadTheme(name string, custom map[string]config.Theme) (Theme, error) {
+	if name == "" || name == "auto" {
+		if lipgloss.HasDarkBackground() {
+			return DarkTheme, nil
+		}
+		return LightTheme, nil
+	}
+	if theme, found := builtinThemes[name]; found {
+		return theme, nil
+	}
+	userTheme, found := custom[name]
+	if !found {
+		names := slices.Concat([]string{"auto"}, slices.Sorted(maps.Keys(builtinThemes)), slices.Sorted(maps.Keys(custom)))
+		return Theme{}, fmt.Errorf("unknown theme %q, expected one of %s", name, strings.Join(names, ", "))
+	}
+
+	base := cmp.Or(userTheme.Base, "dark")
+	theme, found := builtinThemes[base]
+	if !found {
+		return Theme{}, fmt.Errorf("theme %s: unknown base theme %q, expected one of %s", name, base, strings.Join(slices.Sorted(maps.Keys(builtinThemes)), ", "))
+	}
+	colors := theme.colors()
+	for colorName, value := range userTheme.Colors {
+		color, found := colors[colorName]
+		if !found {
+			return Theme{}, fmt.Errorf("theme %s: unknown color %q, expected one of %s", name, colorName, strings.Join(slices.Sorted(maps.Keys(colors)), ", "))
+		}
+		if !validColor(value) {
+			return Theme{}, fmt.Errorf("theme %s: invalid %s color %q, expected #rgb, #rrggbb or an ANSI color from 0 to 255", name, colorName, value)
+		}
+		*color = lipgloss.Color(value)
+	}
+	return theme, nil
+}
+
+// colors returns the colors of the theme by the names used in user-defined themes
+func (t *Theme) colors() map[string]*lipgloss.Color {
+	return map[string]*lipgloss.Color{
+		"primary":           &t.Primary,
+		"secondary":         &t.Secondary,
+		"accent":            &t.Accent,
+		"text-on-primary":   &t.TextOnPrimary,
+		"text":              &t.TextBody,
+		"text-secondary":    &t.TextSecondary,
+		"text-muted":        &t.TextMuted,
+		"text-highlight":    &t.TextHighlight,
+		"title":             &t.Title,
+		"label":             &t.Label,
+		"border":            &t.Border,
+		"border-focus":      &t.BorderFocus,
+		"selected":          &t.Selected,
+		"selected-bg":       &t.SelectedBg,
+		"hover":             &t.Hover,
+		"disabled":          &t.Disabled,
+		"help-key":          &t.HelpKey,
+		"help-text":         &t.HelpText,
+		"info":              &t.Info,
+		"success":           &t.Success,
+		"warning":           &t.Warning,
+		"error":             &t.Error,
+		"status-running":    &t.StatusRunning,
+		"status-stopped":    &t.StatusStopped,
+		"status-error":      &t.StatusError,
+		"status-pending":    &t.StatusPending,
+		"bg-subtle":         &t.BgSubtle,
+		"bg-panel":          &t.BgPanel,
+		"table-border":      &t.TableBorder,
+		"table-selected":    &t.TableSelected,
+		"table-selected-bg": &t.TableSelectedBg,
+	}
+}
+
+// hexColor matches the #rgb and #rrggbb colors
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validColor reports whether a color is a hex color or an ANSI color
+func validColor(value string) bool {
+	if hexColor.MatchString(value) {
+		return true
+	}
+	ansi, err := strconv.Atoi(value)
+	return err == nil && ansi >= 0 && ansi <= 255
+}
+
+// ApplyTheme sets the color palette and rebuilds the styles from it. Adaptive colors, e.g.
+// the ones of the help, follow the background of the theme.
+func ApplyTheme(theme Theme) {
+	lipgloss.SetHasDarkBackground(theme.Dark)
+	setPalette(theme)
+}
+
+// setPalette sets the colors of the theme and rebuilds the styles
+func setPalette(theme Theme) {
+	ColorPrimary = theme.Primary
+	ColorSecondary = theme.Secondary
+	ColorAccent = theme.Accent
+	ColorTextOnPrimary = theme.TextOnPrimary
+	ColorTextBody = theme.TextBody
+	ColorTextSecondary = theme.TextSecondary
+	ColorTextMuted = theme.TextMuted
+	ColorTextHighlight = theme.TextHighlight
+	ColorTitle = theme.Title
+	ColorLabel = theme.Label
+	ColorBorder = theme.Border
+	ColorBorderFocus = theme.BorderFocus
+	ColorSelected = theme.Selected
+	ColorSelectedBg = theme.SelectedBg
+	ColorHover = theme.Hover
+	ColorDisabled = theme.Disabled
+	ColorHelpKey = theme.HelpKey
+	ColorHelpText = theme.HelpText
+	ColorInfo = theme.Info
+	ColorSuccess = theme.Success
+	ColorWarning = theme.Warning
+	ColorError = theme.Error
+	ColorStatusRunning = theme.StatusRunning
+	ColorStatusStopped = theme.StatusStopped
+	ColorStatusError = theme.StatusError
+	ColorStatusPending = theme.StatusPending
+	ColorBgSubtle = theme.BgSubtle
+	ColorBgPanel = theme.BgPanel
+	ColorTableBorder = theme.TableBorder
+	ColorTableSelected = theme.TableSelected
+	ColorTableSelectedBg = theme.TableSelectedBg
+	applyStyles()
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mendes11/swarm-browser/internal/config"
+)
+
+func TestLoadTheme(t *testing.T) {
+	custom := map[string]config.Theme{
+		"ocean":    {Colors: map[string]string{"primary": "#123456", "table-border": "33"}},
+		"paper":    {Base: "light", Colors: map[string]string{"title": "#abc"}},
+		"bare":     {},
+		"unknown":  {Base: "solarized"},
+		"typo":     {Colors: map[string]string{"primery": "#123456"}},
+		"bad-hex":  {Colors: map[string]string{"primary": "#12345"}},
+		"bad-ansi": {Colors: map[string]string{"primary": "256"}},
+		"named":    {Colors: map[string]string{"primary": "blue"}},
+	}
+
+	tests := []struct {
+		name string
+		// check is run on the loaded theme
+		check func(t *testing.T, theme Theme)
+		err   string
+	}{
+		{name: "light", check: func(t *testing.T, theme Theme) {
+			if theme != LightTheme {
+				t.Error("Expected the built-in light theme")
+			}
+		}},
+		{name: "ocean", check: func(t *testing.T, theme Theme) {
+			if theme.Primary != "#123456" || theme.TableBorder != "33" {
+				t.Errorf("Expected the colors of the theme, got %s and %s", theme.Primary, theme.TableBorder)
+			}
+			// The others come from the dark theme
+			if !theme.Dark || theme.Title != DarkTheme.Title {
+				t.Errorf("Expected the other colors of the dark theme, got title %s", theme.Title)
+			}
+		}},
+		{name: "paper", check: func(t *testing.T, theme Theme) {
+			if theme.Title != "#abc" {
+				t.Errorf("Expected the short hex title color, got %s", theme.Title)
+			}
+			if theme.Dark || theme.Primary != LightTheme.Primary {
+				t.Errorf("Expected the other colors of the light theme, got primary %s", theme.Primary)
+			}
+		}},
+		{name: "bare", check: func(t *testing.T, theme Theme) {
+			if theme != DarkTheme {
+				t.Error("Expected a theme without colors to be the dark theme")
+			}
+		}},
+		{name: "missing", err: `unknown theme "missing", expected one of auto, dark, high-contrast, light, bad-ansi`},
+		{name: "unknown", err: `unknown base theme "solarized"`},
+		{name: "typo", err: `unknown color "primery"`},
+		{name: "bad-hex", err: `invalid primary color "#12345", expected #rgb, #rrggbb or an ANSI color`},
+		{name: "bad-ansi", err: `invalid primary color "256"`},
+		{name: "named", err: `invalid primary color "blue"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theme, err := LoadTheme(tt.name, custom)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadTheme failed: %v", err)
+			}
+			tt.check(t, theme)
+		})
+	}
+}
+
+func TestLoadThemeDoesNotChangeBuiltins(t *testing.T) {
+	custom := map[string]config.Theme{"ocean": {Colors: map[string]string{"primary": "#123456"}}}
+	if _, err := LoadTheme("ocean", custom); err != nil {
+		t.Fatalf("LoadTheme failed: %v", err)
+	}
+	if DarkTheme.Primary != lipgloss.Color("#00A7E1") || builtinThemes["dark"].Primary != lipgloss.Color("#00A7E1") {
+		t.Error("Expected the dark theme to keep its colors")
+	}
+}
+
+func TestValidColor(t *testing.T) {
+	tests := map[string]bool{
+		"#abc":     true,
+		"#A1B2C3":  true,
+		"0":        true,
+		"255":      true,
+		"#ab":      false,
+		"#abcd":    false,
+		"#abcdeg":  false,
+		"abc":      false,
+		"256":      false,
+		"-1":       false,
+		"":         false,
+		"#1234567": false,
+	}
+	for value, valid := range tests {
+		if got := validColor(value); got != valid {
+			t.Errorf("validColor(%q) = %v, expected %v", value, got, valid)
+		}
+	}
+}
@@ -9,6 +9,16 @@ const (
 	TaskList
 	ContainerAttached
 	ClusterSelection
+	NetworksList
+	NetworkAttachmentsList
+	TaskMountsList
+	NodeVolumesList
+	ContainerFiles
+	PortForwardsList
+	SessionsList
+	ExecResultsList
+	BookmarksList
+	ErrorLogList
 )
 
 func (v ViewState) String() string {
@@ -25,6 +35,26 @@ func (v ViewState) String() string {
 		return "Container Attached"
 	case ClusterSelection:
 		return "Cluster Selection"
+	case NetworksList:
+		return "Networks List"
+	case NetworkAttachmentsList:
+		return "Network Attachments List"
+	case TaskMountsList:
+		return "Task Mounts List"
+	case NodeVolumesList:
+		return "Node Volumes List"
+	case ContainerFiles:
+		return "Container Files"
+	case PortForwardsList:
+		return "Port Forwards List"
+	case SessionsList:
+		return "Sessions List"
+	case ExecResultsList:
+		return "Exec Results"
+	case BookmarksList:
+		return "Bookmarks"
+	case ErrorLogList:
+		return "Error Log"
 	default:
 		return "Unknown"
 	}
@@ -0,0 +1,90 @@
+// Package asciicast records and plays terminal sessions in the asciicast v2 format.
+//
+// A recording is a JSON header line followed by one JSON array per event:
+//
+//	{"version": 2, "width": 80, "height": 24, "timestamp": 1700000000}
+//	[0.248, "o", "$ "]
+//	[1.001, "i", "ls\r"]
+//	[2.5, "r", "120x40"]
+//
+// See https://docs.asciinema.org/manual/asciicast/v2/
+package asciicast
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Event types
+const (
+	Output = "o"
+	Input  = "i"
+	Resize = "r"
+)
+
+// Header is the first line of a recording
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Command   string            `json:"command,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	// Metadata describes where the session ran. Players ignore it.
+	Metadata *Metadata `json:"swarm_browser,omitempty"`
+}
+
+// Metadata describes the container a session was recorded in
+type Metadata struct {
+	Cluster     string `json:"cluster,omitempty"`
+	Stack       string `json:"stack,omitempty"`
+	Service     string `json:"service,omitempty"`
+	Task        string `json:"task,omitempty"`
+	Node        string `json:"node,omitempty"`
+	ContainerID string `json:"container_id,omitempty"`
+	User        string `json:"user,omitempty"`
+}
+
+// Event is a timed piece of terminal activity
+type Event struct {
+	Time float64 // Seconds since the start of the recording
+	Type string
+	Data string
+}
+
+// MarshalJSON encodes the event as a [time, type, data] array
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{e.Time, e.Type, e.Data})
+}
+
+// UnmarshalJSON decodes a [time, type, data] array
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) != 3 {
+		return errors.Errorf("expected 3 event fields, got %d", len(fields))
+	}
+	if err := json.Unmarshal(fields[0], &e.Time); err != nil {
+		return errors.Wrap(err, "invalid event time")
+	}
+	if err := json.Unmarshal(fields[1], &e.Type); err != nil {
+		return errors.Wrap(err, "invalid event type")
+	}
+	if err := json.Unmarshal(fields[2], &e.Data); err != nil {
+		return errors.Wrap(err, "invalid event data")
+	}
+	return nil
+}
+
+// ParseSize parses the "WxH" data of a resize event
+func ParseSize(data string) (width int, height int, err error) {
+	if _, err := fmt.Sscanf(data, "%dx%d", &width, &height); err != nil {
+		return 0, 0, errors.Errorf("invalid terminal size %q", data)
+	}
+	return width, height, nil
+}
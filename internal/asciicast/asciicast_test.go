@@ -0,0 +1,104 @@
+package asciicast
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func TestWriteAndRead(t *testing.T) {
+	var recording bytes.Buffer
+	w, err := NewWriter(nopCloser{&recording}, Header{
+		Width:    80,
+		Height:   24,
+		Metadata: &Metadata{Cluster: "prod", Service: "shop_web", User: "alice"},
+	})
+	if err != nil {
+		t.Fatalf("NewWriter failed: %v", err)
+	}
+	w.Output([]byte("$ "))
+	w.Input([]byte("ls\r"))
+	// A character split across two writes is recorded with the second one
+	w.Output([]byte("caf\xc3"))
+	w.Output([]byte("\xa9\r\n"))
+	w.Resize(120, 40)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	r, err := NewReader(&recording)
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	if r.Header.Version != 2 || r.Header.Width != 80 || r.Header.Metadata.Service != "shop_web" {
+		t.Errorf("Unexpected header %+v", r.Header)
+	}
+	expected := []Event{
+		{Type: Output, Data: "$ "},
+		{Type: Input, Data: "ls\r"},
+		{Type: Output, Data: "caf"},
+		{Type: Output, Data: "é\r\n"},
+		{Type: Resize, Data: "120x40"},
+	}
+	for _, want := range expected {
+		event, err := r.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		if event.Type != want.Type || event.Data != want.Data {
+			t.Errorf("Expected %s event %q, got %s event %q", want.Type, want.Data, event.Type, event.Data)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Expected EOF, got %v", err)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	w, path, err := Create(dir, Header{Metadata: &Metadata{Cluster: "prod", Task: "abc/def"}})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	w.Close()
+	if !strings.HasPrefix(path, dir) || !strings.HasSuffix(path, "-prod-abc_def.cast") {
+		t.Errorf("Unexpected recording path %s", path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected the recording file: %v", err)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	recording := `{"version": 2, "width": 80, "height": 24}
+[0.5, "o", "hello "]
+[1.0, "i", "x"]
+[10.0, "o", "world"]
+`
+	r, err := NewReader(strings.NewReader(recording))
+	if err != nil {
+		t.Fatalf("NewReader failed: %v", err)
+	}
+	var out bytes.Buffer
+	started := time.Now()
+	// The 9s pause is capped by the idle limit, and the playback runs 10 times faster
+	if err := Play(context.Background(), r, &out, PlayOptions{Speed: 10, IdleLimit: time.Second}); err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Expected playback to take about 0.2s, took %s", elapsed)
+	}
+	if out.String() != "hello world" {
+		t.Errorf("Expected 'hello world', got %q", out.String())
+	}
+}
@@ -0,0 +1,102 @@
+package asciicast
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Reader reads the events of a recording
+type Reader struct {
+	Header  Header
+	scanner *bufio.Scanner
+}
+
+// NewReader reads the header of a recording
+func NewReader(r io.Reader) (*Reader, error) {
+	scanner := bufio.NewScanner(r)
+	// Output events can hold large chunks of the session
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, errors.Wrap(err, "asciicast.NewReader: failed to read header")
+		}
+		return nil, errors.New("asciicast.NewReader: empty recording")
+	}
+	var header Header
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return nil, errors.Wrap(err, "asciicast.NewReader: invalid header")
+	}
+	if header.Version != 2 {
+		return nil, errors.Errorf("asciicast.NewReader: unsupported asciicast version %d", header.Version)
+	}
+	return &Reader{Header: header, scanner: scanner}, nil
+}
+
+// Next returns the next event, or io.EOF at the end of the recording
+func (r *Reader) Next() (Event, error) {
+	for r.scanner.Scan() {
+		line := r.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			return Event{}, errors.Wrap(err, "asciicast.Reader#Next: invalid event")
+		}
+		return event, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Event{}, errors.Wrap(err, "asciicast.Reader#Next")
+	}
+	return Event{}, io.EOF
+}
+
+// PlayOptions controls the playback of a recording
+type PlayOptions struct {
+	// Speed multiplies the playback speed, 1 plays in real time
+	Speed float64
+	// IdleLimit caps the pauses between events, 0 keeps them
+	IdleLimit time.Duration
+}
+
+// Play writes the output events of a recording to out, respecting their timing.
+// It stops early when ctx is cancelled.
+func Play(ctx context.Context, r *Reader, out io.Writer, opts PlayOptions) error {
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	previous := 0.0
+	for {
+		event, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		pause := time.Duration((event.Time - previous) * float64(time.Second))
+		previous = event.Time
+		if opts.IdleLimit > 0 {
+			pause = min(pause, opts.IdleLimit)
+		}
+		if pause > 0 {
+			select {
+			case <-time.After(time.Duration(float64(pause) / speed)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if event.Type != Output {
+			continue
+		}
+		if _, err := io.WriteString(out, event.Data); err != nil {
+			return errors.Wrap(err, "asciicast.Play")
+		}
+	}
+}
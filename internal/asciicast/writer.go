@@ -0,0 +1,146 @@
+package asciicast
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Writer records terminal activity. It is safe for concurrent use.
+type Writer struct {
+	mu     sync.Mutex
+	out    io.WriteCloser
+	buffer *bufio.Writer
+	start  time.Time
+	// Trailing bytes of an incomplete UTF-8 sequence, per event type
+	pending map[string][]byte
+}
+
+// NewWriter writes the header to out, timing the events from now
+func NewWriter(out io.WriteCloser, header Header) (*Writer, error) {
+	header.Version = 2
+	start := time.Now()
+	if header.Timestamp == 0 {
+		header.Timestamp = start.Unix()
+	}
+	w := &Writer{
+		out:     out,
+		buffer:  bufio.NewWriter(out),
+		start:   start,
+		pending: make(map[string][]byte),
+	}
+	if err := w.writeLine(header); err != nil {
+		return nil, errors.Wrap(err, "asciicast.NewWriter: failed to write header")
+	}
+	return w, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Create starts a recording in a new file of dir, named after the time and the session metadata
+func Create(dir string, header Header) (*Writer, string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, "", errors.Wrap(err, "asciicast.Create: MkdirAll")
+	}
+	name := []string{time.Now().Format("20060102-150405")}
+	if meta := header.Metadata; meta != nil {
+		for _, part := range []string{meta.Cluster, meta.Service, meta.Task} {
+			if part != "" {
+				name = append(name, unsafeFileChars.ReplaceAllString(part, "_"))
+			}
+		}
+	}
+	path := filepath.Join(dir, strings.Join(name, "-")+".cast")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "asciicast.Create: OpenFile")
+	}
+	w, err := NewWriter(file, header)
+	if err != nil {
+		file.Close()
+		return nil, "", err
+	}
+	return w, path, nil
+}
+
+// Output records data written to the terminal
+func (w *Writer) Output(data []byte) error {
+	return w.write(Output, data)
+}
+
+// Input records data typed in the terminal
+func (w *Writer) Input(data []byte) error {
+	return w.write(Input, data)
+}
+
+// Resize records a change of the terminal size
+func (w *Writer) Resize(width, height uint) error {
+	return w.write(Resize, []byte(fmt.Sprintf("%dx%d", width, height)))
+}
+
+// Close flushes the recording and closes the underlying file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.buffer.Flush(); err != nil {
+		w.out.Close()
+		return errors.Wrap(err, "asciicast.Writer#Close: Flush")
+	}
+	return w.out.Close()
+}
+
+func (w *Writer) write(eventType string, data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// Hold back a multi-byte character split across writes, which JSON can't encode
+	data = append(w.pending[eventType], data...)
+	complete := completeUTF8(data)
+	w.pending[eventType] = append([]byte(nil), data[complete:]...)
+	if complete == 0 {
+		return nil
+	}
+	event := Event{
+		Time: time.Since(w.start).Seconds(),
+		Type: eventType,
+		Data: string(data[:complete]),
+	}
+	if err := w.writeLine(event); err != nil {
+		return err
+	}
+	// Keep the file usable if the process dies
+	return w.buffer.Flush()
+}
+
+func (w *Writer) writeLine(value any) error {
+	line, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	_, err = w.buffer.Write(line)
+	return err
+}
+
+// completeUTF8 returns the length of data without a trailing incomplete UTF-8 sequence
+func completeUTF8(data []byte) int {
+	// A sequence is at most 4 bytes long, look for its first byte
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if utf8.FullRune(data[i:]) {
+				return len(data)
+			}
+			return i
+		}
+	}
+	return len(data)
+}
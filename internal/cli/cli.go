@@ -0,0 +1,165 @@
+// Package cli implements the non-interactive swarm-browser subcommands
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mendes11/swarm-browser/internal/config"
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/mendes11/swarm-browser/internal/query"
+	"github.com/moby/moby/api/types/swarm"
+	"github.com/pkg/errors"
+)
+
+// Command is a swarm-browser subcommand
+type Command struct {
+	Name        string
+	Usage       string
+	Description string
+	Run         func(conf config.Config, args []string) error
+	// Offline commands run without the clusters configuration
+	Offline bool
+}
+
+// Commands lists the available subcommands
+var Commands = []Command{
+	{
+		Name:        "cp",
+		Usage:       copyUsage,
+		Description: "Copy files from or to a task container",
+		Run:         Copy,
+	},
+	{
+		Name:        "port-forward",
+		Usage:       portForwardUsage,
+		Description: "Forward local ports to a task container",
+		Run:         PortForward,
+	},
+	{
+		Name:        "run",
+		Usage:       runUsage,
+		Description: "Run a command in every task of a service",
+		Run:         Run,
+	},
+	{
+		Name:        "replay",
+		Usage:       replayUsage,
+		Description: "Play a recorded container session",
+		Run:         Replay,
+		Offline:     true,
+	},
+}
+
+// Lookup returns the subcommand with the given name
+func Lookup(name string) (Command, bool) {
+	for _, command := range Commands {
+		if command.Name == name {
+			return command, true
+		}
+	}
+	return Command{}, false
+}
+
+// newFlagSet creates the flag set of a subcommand with the common --cluster flag
+func newFlagSet(command string, conf config.Config) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	cluster := flags.String("cluster", conf.InitialCluster, "Name of the cluster to connect to")
+	return flags, cluster
+}
+
+// filterFlag adds the --filter flag, selecting the tasks of a service the command applies to
+func filterFlag(flags *flag.FlagSet) *string {
+	return flags.String("filter", "", "Only use the tasks matching a filter query, e.g. 'node:worker-* -status:failed'")
+}
+
+// parseFilter parses the --filter query of the tasks
+func parseFilter(expr string) (query.Query, error) {
+	filter, err := query.Parse(expr)
+	if err == nil {
+		err = filter.Check(query.TaskFields)
+	}
+	if err != nil {
+		return query.Query{}, errors.Wrap(err, "invalid --filter")
+	}
+	return filter, nil
+}
+
+// connect creates a browser for the named cluster
+func connect(conf config.Config, clusterName string) (core.ClusterBrowser, error) {
+	cluster, exists := conf.Clusters[clusterName]
+	if !exists {
+		return nil, errors.Errorf("cluster %s not found in %s", clusterName, conf.ClusterFilePath)
+	}
+	return core.New(cluster), nil
+}
+
+// resolveTask finds a running task by its ID (or ID prefix), or a running task
+// of the service with the given name matching the filter.
+func resolveTask(ctx context.Context, browser core.ClusterBrowser, target string, filter query.Query) (models.Task, error) {
+	tasks, err := resolveTasks(ctx, browser, target, filter)
+	if err != nil {
+		return models.Task{}, err
+	}
+	for _, task := range tasks {
+		if task.Status == swarm.TaskStateRunning {
+			return task, nil
+		}
+	}
+	return models.Task{}, errors.Errorf("no running task found for %s", target)
+}
+
+// resolveTasks returns the tasks of the service named target, or the task whose ID starts with target,
+// keeping those matching the filter
+func resolveTasks(ctx context.Context, browser core.ClusterBrowser, target string, filter query.Query) ([]models.Task, error) {
+	stacks, err := browser.ListStacks(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "cli.resolveTasks: ListStacks")
+	}
+	for _, stack := range stacks {
+		services, err := browser.ListServices(ctx, stack)
+		if err != nil {
+			return nil, errors.Wrap(err, "cli.resolveTasks: ListServices")
+		}
+		for _, service := range services {
+			tasks, err := browser.ListTasks(ctx, service)
+			if err != nil {
+				return nil, errors.Wrap(err, "cli.resolveTasks: ListTasks")
+			}
+			if service.Name == target || service.ID == target {
+				return filterTasks(tasks, target, filter)
+			}
+			for _, task := range tasks {
+				if strings.HasPrefix(task.TaskID, target) {
+					return filterTasks([]models.Task{task}, target, filter)
+				}
+			}
+		}
+	}
+	return nil, errors.Errorf("service or task %s not found", target)
+}
+
+func filterTasks(tasks []models.Task, target string, filter query.Query) ([]models.Task, error) {
+	filtered := query.Filter(filter, tasks, query.TaskItem)
+	if len(filtered) == 0 && len(tasks) > 0 {
+		return nil, errors.Errorf("no task of %s matches the filter", target)
+	}
+	return filtered, nil
+}
+
+// splitContainerPath splits a "<service|task>:<path>" argument.
+// It returns ok false for local paths.
+func splitContainerPath(arg string) (target string, containerPath string, ok bool) {
+	target, containerPath, found := strings.Cut(arg, ":")
+	if !found || target == "" || strings.ContainsAny(target, "/\\.") {
+		return "", "", false
+	}
+	return target, containerPath, true
+}
+
+func usageError(usage string) error {
+	return fmt.Errorf("usage: swarm-browser %s", usage)
+}
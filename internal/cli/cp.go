@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mendes11/swarm-browser/internal/config"
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/pkg/errors"
+)
+
+const copyUsage = "cp [flags] <service|task>:<path> <local dir> | cp [flags] <local path> <service|task>:<dir>"
+
+// Copy copies files between a task container and the local machine.
+//
+//	swarm-browser cp mystack_web:/tmp/heap.hprof ./dumps
+//	swarm-browser cp ./patched.rb mystack_web:/app/lib
+func Copy(conf config.Config, args []string) error {
+	flags, clusterName := newFlagSet("cp", conf)
+	filterExpr := filterFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return usageError(copyUsage)
+	}
+	src, dst := flags.Arg(0), flags.Arg(1)
+
+	filter, err := parseFilter(*filterExpr)
+	if err != nil {
+		return err
+	}
+
+	browser, err := connect(conf, *clusterName)
+	if err != nil {
+		return err
+	}
+	defer browser.Close()
+	ctx := context.Background()
+
+	if target, srcPath, ok := splitContainerPath(src); ok {
+		task, err := resolveTask(ctx, browser, target, filter)
+		if err != nil {
+			return err
+		}
+		if err := core.DownloadFromTask(ctx, browser, task, srcPath, dst); err != nil {
+			return errors.Wrap(err, "cli.Copy")
+		}
+		fmt.Printf("Copied %s:%s to %s\n", task.TaskID, srcPath, dst)
+		return nil
+	}
+	if target, dstDir, ok := splitContainerPath(dst); ok {
+		task, err := resolveTask(ctx, browser, target, filter)
+		if err != nil {
+			return err
+		}
+		if err := core.UploadToTask(ctx, browser, task, src, dstDir); err != nil {
+			return errors.Wrap(err, "cli.Copy")
+		}
+		fmt.Printf("Copied %s to %s:%s\n", src, task.TaskID, dstDir)
+		return nil
+	}
+	return usageError(copyUsage)
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mendes11/swarm-browser/internal/config"
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/pkg/errors"
+)
+
+const portForwardUsage = "port-forward [flags] <service|task> <local:remote>..."
+
+// PortForward forwards local ports to a task container until interrupted.
+//
+//	swarm-browser port-forward mystack_db 5432
+//	swarm-browser port-forward mystack_web 8080:80 9090:9090
+func PortForward(conf config.Config, args []string) error {
+	flags, clusterName := newFlagSet("port-forward", conf)
+	filterExpr := filterFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 {
+		return usageError(portForwardUsage)
+	}
+	type mapping struct{ local, remote int }
+	mappings := make([]mapping, 0, flags.NArg()-1)
+	for _, spec := range flags.Args()[1:] {
+		localPort, remotePort, err := core.ParsePortMapping(spec)
+		if err != nil {
+			return err
+		}
+		mappings = append(mappings, mapping{localPort, remotePort})
+	}
+
+	filter, err := parseFilter(*filterExpr)
+	if err != nil {
+		return err
+	}
+
+	browser, err := connect(conf, *clusterName)
+	if err != nil {
+		return err
+	}
+	defer browser.Close()
+	ctx := context.Background()
+
+	task, err := resolveTask(ctx, browser, flags.Arg(0), filter)
+	if err != nil {
+		return err
+	}
+	forwards := make([]models.PortForward, 0, len(mappings))
+	defer func() {
+		for _, forward := range forwards {
+			browser.StopPortForward(ctx, forward)
+		}
+	}()
+	for _, m := range mappings {
+		forward, err := browser.StartPortForward(ctx, task, m.local, m.remote)
+		if err != nil {
+			return errors.Wrap(err, "cli.PortForward")
+		}
+		forwards = append(forwards, forward)
+		fmt.Printf("Forwarding %s -> %s:%d (task %s on %s)\n", forward.LocalAddr(), forward.RemoteAddr, forward.RemotePort, task.TaskID, task.Node.Hostname)
+	}
+
+	fmt.Println("Press Ctrl+C to stop")
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+	return nil
+}
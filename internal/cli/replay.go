@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mendes11/swarm-browser/internal/asciicast"
+	"github.com/mendes11/swarm-browser/internal/config"
+	"github.com/pkg/errors"
+)
+
+const replayUsage = "replay [--speed 2] [--idle-limit 1s] <file.cast>"
+
+// Replay plays a session recorded with --record in the terminal.
+//
+//	swarm-browser replay recordings/20240501-101500-prod-shop_web.cast
+//	swarm-browser replay --speed 4 --idle-limit 2s session.cast
+func Replay(conf config.Config, args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	speed := flags.Float64("speed", 1, "Playback speed multiplier")
+	idleLimit := flags.Duration("idle-limit", 0, "Limit pauses between events to this duration")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError(replayUsage)
+	}
+	if *speed <= 0 {
+		return errors.New("speed must be positive")
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return errors.Wrap(err, "cli.Replay")
+	}
+	defer file.Close()
+	reader, err := asciicast.NewReader(file)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, describeRecording(reader.Header))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err = asciicast.Play(ctx, reader, os.Stdout, asciicast.PlayOptions{Speed: *speed, IdleLimit: *idleLimit})
+	// Leave the terminal as it was, in case the recording ended in a full-screen program
+	fmt.Fprint(os.Stdout, "\x1b[0m\x1b[?25h\x1b[?1049l\r\n")
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// describeRecording summarizes where and when a session was recorded
+func describeRecording(header asciicast.Header) string {
+	details := []string{fmt.Sprintf("Recorded %s", time.Unix(header.Timestamp, 0).Format(time.DateTime))}
+	if meta := header.Metadata; meta != nil {
+		for _, field := range []struct{ name, value string }{
+			{"by", meta.User},
+			{"cluster", meta.Cluster},
+			{"stack", meta.Stack},
+			{"service", meta.Service},
+			{"task", meta.Task},
+			{"node", meta.Node},
+		} {
+			if field.value != "" {
+				details = append(details, fmt.Sprintf("%s %s", field.name, field.value))
+			}
+		}
+	}
+	return strings.Join(details, ", ")
+}
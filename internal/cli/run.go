@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/mendes11/swarm-browser/internal/config"
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/moby/moby/api/types/swarm"
+	"github.com/pkg/errors"
+)
+
+const runUsage = "run [flags] [--json] <service|task> <command>..."
+
+// runResult is the JSON output of a command run in a task
+type runResult struct {
+	Task       string `json:"task"`
+	Node       string `json:"node"`
+	Container  string `json:"container"`
+	ExitCode   int    `json:"exit_code"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+	DurationMs int64  `json:"duration_ms"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Run runs a shell command in every running task of a service, in parallel.
+// It fails when the command fails in any task, or doesn't exit before --timeout or Ctrl+C.
+//
+//	swarm-browser run mystack_web 'env | grep DATABASE'
+//	swarm-browser run --json mystack_web curl -s localhost/health
+//	swarm-browser run --filter 'node:worker-*' mystack_web df -h /data
+func Run(conf config.Config, args []string) error {
+	flags, clusterName := newFlagSet("run", conf)
+	filterExpr := filterFlag(flags)
+	jsonOutput := flags.Bool("json", false, "Print the results as JSON")
+	timeout := flags.Duration("timeout", 0, "Stop waiting for the command after this long, e.g. 30s (0 waits until it exits)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 {
+		return usageError(runUsage)
+	}
+	commandLine := strings.Join(flags.Args()[1:], " ")
+
+	filter, err := parseFilter(*filterExpr)
+	if err != nil {
+		return err
+	}
+
+	browser, err := connect(conf, *clusterName)
+	if err != nil {
+		return err
+	}
+	defer browser.Close()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	tasks, err := resolveTasks(ctx, browser, flags.Arg(0), filter)
+	if err != nil {
+		return err
+	}
+	running := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.Status == swarm.TaskStateRunning {
+			running = append(running, task)
+		}
+	}
+	if len(running) == 0 {
+		return errors.Errorf("no running task found for %s", flags.Arg(0))
+	}
+
+	results := core.ExecInTasks(ctx, browser, running, core.ShellCommand(commandLine))
+	if *jsonOutput {
+		err = printRunResultsJSON(results)
+	} else {
+		printRunResults(results)
+	}
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.Succeeded() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("command failed in %d of %d tasks", failed, len(results))
+	}
+	return nil
+}
+
+func printRunResults(results []models.ExecResult) {
+	for _, result := range results {
+		status := fmt.Sprintf("exit %d", result.ExitCode)
+		if result.Err != nil {
+			status = fmt.Sprintf("error: %v", result.Err)
+		}
+		fmt.Printf("==> %s on %s (%s)\n", result.Task.TaskID, result.Task.Node.Hostname, status)
+		os.Stdout.Write(result.Stdout)
+		os.Stderr.Write(result.Stderr)
+	}
+}
+
+func printRunResultsJSON(results []models.ExecResult) error {
+	output := make([]runResult, len(results))
+	for i, result := range results {
+		output[i] = runResult{
+			Task:       result.Task.TaskID,
+			Node:       result.Task.Node.Hostname,
+			Container:  result.Task.ContainerID,
+			ExitCode:   result.ExitCode,
+			Stdout:     string(result.Stdout),
+			Stderr:     string(result.Stderr),
+			DurationMs: result.Duration.Milliseconds(),
+			Truncated:  result.Truncated,
+		}
+		if result.Err != nil {
+			output[i].Error = result.Err.Error()
+		}
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(output); err != nil {
+		return errors.Wrap(err, "cli.Run: failed to encode results")
+	}
+	return nil
+}
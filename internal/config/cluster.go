@@ -10,6 +10,19 @@ import (
 
 type ClustersConfig struct {
 	Clusters map[string]models.Cluster `yaml:"clusters"`
+	// Theme names the color theme: dark, light, high-contrast, one of Themes,
+	// or auto (the default) for dark or light after the terminal background
+	Theme string `yaml:"theme,omitempty"`
+	// Themes are the user-defined color themes, by name
+	Themes map[string]Theme `yaml:"themes,omitempty"`
+}
+
+// Theme is a user-defined color theme, changing some colors of a built-in theme
+type Theme struct {
+	// Base is the built-in theme providing the other colors, dark by default
+	Base string `yaml:"base,omitempty"`
+	// Colors are hex (#rgb or #rrggbb) or ANSI (0-255) colors, by color name
+	Colors map[string]string `yaml:"colors"`
 }
 
 func LoadClustersConfig(path string) (*ClustersConfig, error) {
@@ -79,3 +79,40 @@ func TestLoadClustersConfig(t *testing.T) {
 		t.Errorf("Expected 2 node names in staging, got %d", len(nodes))
 	}
 }
+
+func TestLoadClustersConfigThemes(t *testing.T) {
+	yamlContent := `clusters:
+  prod:
+    name: "Production"
+    host: "manager-01.example.com"
+theme: solarized
+themes:
+  solarized:
+    base: light
+    colors:
+      primary: "#268BD2"
+      table-selected-bg: "33"`
+
+	tmpFile := filepath.Join(t.TempDir(), "clusters.yaml")
+	if err := os.WriteFile(tmpFile, []byte(yamlContent), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	config, err := LoadClustersConfig(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to load clusters config: %v", err)
+	}
+	if config.Theme != "solarized" {
+		t.Errorf("Expected theme 'solarized', got '%s'", config.Theme)
+	}
+	theme, found := config.Themes["solarized"]
+	if !found {
+		t.Fatal("Expected to find the 'solarized' theme")
+	}
+	if theme.Base != "light" {
+		t.Errorf("Expected base 'light', got '%s'", theme.Base)
+	}
+	if theme.Colors["primary"] != "#268BD2" || theme.Colors["table-selected-bg"] != "33" {
+		t.Errorf("Unexpected colors %v", theme.Colors)
+	}
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/mendes11/swarm-browser/internal/core/models"
 )
 
@@ -8,6 +10,29 @@ type Config struct {
 	ClusterFilePath string
 	InitialCluster  string
 	Clusters        map[string]models.Cluster
+	// Multiplexer runs container sessions inside tmux or screen ("auto" for either) when available
+	Multiplexer string
+	// RecordDir enables recording container sessions as asciicast files in the directory
+	RecordDir string
+	// RecordInput records the keys typed in the sessions along with their output
+	RecordInput bool
+	// Scrollback is the number of lines of output kept by container sessions
+	Scrollback int
+	// DebugImage is the image of the sidecars debugging containers without a shell
+	DebugImage string
+	// StateFilePath is where the browser remembers e.g. the sort order of the views
+	StateFilePath string
+	// OpenBookmark is the name of the bookmark opened at startup
+	OpenBookmark string
+	// Keymap overrides the default keybindings
+	Keymap Keymap
+	// Theme names the color theme, Themes being the user-defined ones
+	Theme  string
+	Themes map[string]Theme
+	// Mouse enables selecting, opening and scrolling with the mouse
+	Mouse bool
+	// RequestTimeout cancels the requests to the cluster taking longer, zero disabling it
+	RequestTimeout time.Duration
 }
 
 var defaultConfig = &Config{
@@ -23,6 +48,9 @@ func LoadConfig() Config {
 		panic(err)
 	}
 	conf.Clusters = clusters.Clusters
+	conf.Theme = clusters.Theme
+	conf.Themes = clusters.Themes
+	conf.StateFilePath = DefaultStatePath()
 	for k := range conf.Clusters {
 		conf.InitialCluster = k
 		break
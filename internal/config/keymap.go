@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Keymap overrides the keybindings of the browser, e.g.
+//
+//	preset: vim
+//	keys:
+//	  detach: ctrl+g
+//	  files: [f, F]
+type Keymap struct {
+	// Preset is a set of bindings applied before the keys, default, vim or emacs
+	Preset string `yaml:"preset,omitempty"`
+	// Keys are the keys of each action, by action name. An empty list unbinds the action.
+	Keys map[string]KeyList `yaml:"keys,omitempty"`
+}
+
+// KeyList is a list of keys, written as a single key or a sequence of keys
+type KeyList []string
+
+// UnmarshalYAML accepts a single key as well as a sequence of keys
+func (l *KeyList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = KeyList{value.Value}
+		return nil
+	}
+	var keys []string
+	if err := value.Decode(&keys); err != nil {
+		return err
+	}
+	*l = keys
+	return nil
+}
+
+// DefaultKeymapPath returns the keymap file in the user configuration directory,
+// or an empty string when there is none
+func DefaultKeymapPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "swarm-browser", "keymap.yml")
+}
+
+// LoadKeymap reads a keymap file, returning an empty keymap when it doesn't exist
+func LoadKeymap(path string) (Keymap, error) {
+	var keymap Keymap
+	if path == "" {
+		return keymap, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return keymap, nil
+	}
+	if err != nil {
+		return keymap, errors.Wrap(err, "failed to read keymap file")
+	}
+	if err := yaml.Unmarshal(data, &keymap); err != nil {
+		return Keymap{}, errors.Wrap(err, "failed to parse keymap file")
+	}
+	return keymap, nil
+}
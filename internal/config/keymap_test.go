@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadKeymap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keymap.yml")
+
+	keymap, err := LoadKeymap(path)
+	if err != nil || keymap.Preset != "" || len(keymap.Keys) != 0 {
+		t.Fatalf("Expected a missing keymap file to load as an empty keymap, got %+v, %v", keymap, err)
+	}
+
+	data := "preset: vim\nkeys:\n  detach: ctrl+g\n  files: [f, F]\n  networks: []\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	keymap, err = LoadKeymap(path)
+	if err != nil {
+		t.Fatalf("Failed to load keymap: %v", err)
+	}
+	if keymap.Preset != "vim" {
+		t.Errorf("Unexpected preset %q", keymap.Preset)
+	}
+	if keys := keymap.Keys["detach"]; !slices.Equal(keys, KeyList{"ctrl+g"}) {
+		t.Errorf("Unexpected detach keys %q", keys)
+	}
+	if keys := keymap.Keys["files"]; !slices.Equal(keys, KeyList{"f", "F"}) {
+		t.Errorf("Unexpected files keys %q", keys)
+	}
+	if keys, found := keymap.Keys["networks"]; !found || len(keys) != 0 {
+		t.Errorf("Expected networks to be unbound, got %q", keys)
+	}
+
+	if err := os.WriteFile(path, []byte("keys:\n  detach: {key: ctrl+g}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadKeymap(path); err == nil {
+		t.Errorf("Expected an error for a mapping of keys")
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// State is what the browser remembers from one run to the next, as opposed to the
+// configuration written by the user
+type State struct {
+	// Sort is the sort order of each view, by view name
+	Sort map[string]SortOrder `yaml:"sort,omitempty"`
+	// Bookmarks are the saved locations, in the order they were added
+	Bookmarks []Bookmark `yaml:"bookmarks,omitempty"`
+
+	// loadErr is why the state file couldn't be loaded, which isn't overwritten then
+	loadErr error
+}
+
+// Bookmark is a saved location of the browser: a cluster, optionally one of its stacks
+// and a service of the stack, along with the filter of the view
+type Bookmark struct {
+	Name    string `yaml:"name"`
+	Cluster string `yaml:"cluster"`
+	Stack   string `yaml:"stack,omitempty"`
+	Service string `yaml:"service,omitempty"`
+	Filter  string `yaml:"filter,omitempty"`
+}
+
+// SortOrder sorts the rows of a table by one of its columns
+type SortOrder struct {
+	Column     string `yaml:"column"`
+	Descending bool   `yaml:"descending,omitempty"`
+}
+
+// DefaultStatePath returns the state file in the user configuration directory,
+// or an empty string when there is none
+func DefaultStatePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "swarm-browser", "state.yml")
+}
+
+// LoadState reads the state file, returning an empty state when it doesn't exist yet. When it
+// can't be read or parsed, the empty state returned along with the error refuses to be saved,
+// so that the file is left for the user to fix rather than losing its bookmarks.
+func LoadState(path string) (*State, error) {
+	state := &State{}
+	if path == "" {
+		return state, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		state.loadErr = errors.Wrap(err, "failed to read state file")
+		return state, state.loadErr
+	}
+	if err := yaml.Unmarshal(data, state); err != nil {
+		state = &State{loadErr: errors.Wrap(err, "failed to parse state file")}
+		return state, state.loadErr
+	}
+	return state, nil
+}
+
+// Save writes the state file, creating its directory if needed. A state file that failed to
+// load is not overwritten.
+func (s *State) Save(path string) error {
+	if path == "" {
+		return nil
+	}
+	if s.loadErr != nil {
+		return errors.Wrapf(s.loadErr, "not overwriting %s", path)
+	}
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode state")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrap(err, "failed to create state directory")
+	}
+	// Write then rename, so an interrupted write doesn't lose the previous state
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return errors.Wrap(err, "failed to write state file")
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return errors.Wrap(err, "failed to write state file")
+	}
+	return nil
+}
+
+// LoadErr returns why the state file couldn't be loaded, if it couldn't
+func (s *State) LoadErr() error {
+	return s.loadErr
+}
+
+// SortOrder returns the sort order of a view, if one was chosen
+func (s *State) SortOrder(view string) (SortOrder, bool) {
+	order, found := s.Sort[view]
+	return order, found
+}
+
+// SetSortOrder changes the sort order of a view, the zero value restoring the default order
+func (s *State) SetSortOrder(view string, order SortOrder) {
+	if order == (SortOrder{}) {
+		delete(s.Sort, view)
+		return
+	}
+	if s.Sort == nil {
+		s.Sort = make(map[string]SortOrder)
+	}
+	s.Sort[view] = order
+}
+
+// Bookmark returns the bookmark with the given name, if any
+func (s *State) Bookmark(name string) (Bookmark, bool) {
+	index := slices.IndexFunc(s.Bookmarks, func(b Bookmark) bool { return b.Name == name })
+	if index < 0 {
+		return Bookmark{}, false
+	}
+	return s.Bookmarks[index], true
+}
+
+// AddBookmark saves a bookmark, replacing the one with the same name if any
+func (s *State) AddBookmark(bookmark Bookmark) {
+	index := slices.IndexFunc(s.Bookmarks, func(b Bookmark) bool { return b.Name == bookmark.Name })
+	if index >= 0 {
+		s.Bookmarks[index] = bookmark
+		return
+	}
+	s.Bookmarks = append(s.Bookmarks, bookmark)
+}
+
+// RemoveBookmark deletes the bookmark with the given name
+func (s *State) RemoveBookmark(name string) {
+	s.Bookmarks = slices.DeleteFunc(s.Bookmarks, func(b Bookmark) bool { return b.Name == name })
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swarm-browser", "state.yml")
+
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("Expected a missing state file to load as an empty state, got %v", err)
+	}
+	if _, found := state.SortOrder("Services List"); found {
+		t.Fatalf("Expected no sort order in an empty state")
+	}
+
+	state.SetSortOrder("Services List", SortOrder{Column: "Replicas", Descending: true})
+	state.SetSortOrder("Task List", SortOrder{Column: "Node"})
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if order, _ := loaded.SortOrder("Services List"); order != (SortOrder{Column: "Replicas", Descending: true}) {
+		t.Errorf("Unexpected services sort order: %+v", order)
+	}
+	if order, _ := loaded.SortOrder("Task List"); order != (SortOrder{Column: "Node"}) {
+		t.Errorf("Unexpected tasks sort order: %+v", order)
+	}
+
+	// The zero value restores the default order
+	loaded.SetSortOrder("Task List", SortOrder{})
+	if _, found := loaded.SortOrder("Task List"); found {
+		t.Errorf("Expected the tasks sort order to be removed")
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.yml")
+
+	state := &State{}
+	state.AddBookmark(Bookmark{Name: "api", Cluster: "prod", Stack: "backend", Service: "backend_api"})
+	state.AddBookmark(Bookmark{Name: "failing", Cluster: "prod", Stack: "backend", Filter: "replicas<desired"})
+	// Same name, replacing the first bookmark in place
+	state.AddBookmark(Bookmark{Name: "api", Cluster: "staging", Stack: "backend", Service: "backend_api", Filter: "status:running"})
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if len(loaded.Bookmarks) != 2 || loaded.Bookmarks[0].Name != "api" || loaded.Bookmarks[1].Name != "failing" {
+		t.Fatalf("Unexpected bookmarks: %+v", loaded.Bookmarks)
+	}
+	expected := Bookmark{Name: "api", Cluster: "staging", Stack: "backend", Service: "backend_api", Filter: "status:running"}
+	if bookmark, found := loaded.Bookmark("api"); !found || bookmark != expected {
+		t.Errorf("Bookmark(api) = %+v, %v, expected %+v", bookmark, found, expected)
+	}
+
+	loaded.RemoveBookmark("api")
+	if _, found := loaded.Bookmark("api"); found {
+		t.Errorf("Expected the api bookmark to be removed")
+	}
+	if len(loaded.Bookmarks) != 1 {
+		t.Errorf("Unexpected bookmarks: %+v", loaded.Bookmarks)
+	}
+}
+
+func TestStateNotOverwrittenWhenInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.yml")
+	invalid := []byte("bookmarks:\n  - name: api\n    cluster: [prod\n")
+	if err := os.WriteFile(path, invalid, 0o644); err != nil {
+		t.Fatalf("Failed to write state file: %v", err)
+	}
+
+	state, err := LoadState(path)
+	if err == nil {
+		t.Fatal("Expected an error loading an invalid state file")
+	}
+	if state.LoadErr() == nil {
+		t.Error("Expected the state to keep the load error")
+	}
+	state.AddBookmark(Bookmark{Name: "failing", Cluster: "prod"})
+	if err := state.Save(path); err == nil {
+		t.Error("Expected an error saving over a state file that failed to load")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != string(invalid) {
+		t.Errorf("Expected the state file to be left untouched, got %q (%v)", data, err)
+	}
+}
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"archive/tar"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/pkg/errors"
+)
+
+// ArchivePath creates a tar archive of a local file or directory, in the same format
+// expected by the Docker archive API. Entries are rooted at the base name of srcPath.
+//
+// The archive is written in the background, so the returned reader must be closed.
+func ArchivePath(srcPath string) (io.ReadCloser, error) {
+	srcPath = filepath.Clean(srcPath)
+	if _, err := os.Lstat(srcPath); err != nil {
+		return nil, errors.Wrap(err, "core.ArchivePath: Lstat")
+	}
+	reader, writer := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(writer)
+		baseDir := filepath.Dir(srcPath)
+		err := filepath.Walk(srcPath, func(filePath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			linkTarget := ""
+			if info.Mode()&os.ModeSymlink != 0 {
+				if linkTarget, err = os.Readlink(filePath); err != nil {
+					return err
+				}
+			}
+			header, err := tar.FileInfoHeader(info, linkTarget)
+			if err != nil {
+				return err
+			}
+			relPath, err := filepath.Rel(baseDir, filePath)
+			if err != nil {
+				return err
+			}
+			header.Name = filepath.ToSlash(relPath)
+			if info.IsDir() {
+				header.Name += "/"
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			file, err := os.Open(filePath)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			_, err = io.Copy(tw, file)
+			return err
+		})
+		if err == nil {
+			err = tw.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+	return reader, nil
+}
+
+// ExtractArchive extracts a tar archive into the local dstDir directory.
+// Entries escaping dstDir are rejected, including through the links of the archive: the
+// links can't point out of dstDir, and the directories written to are resolved first.
+func ExtractArchive(content io.Reader, dstDir string) error {
+	dstDir = filepath.Clean(dstDir)
+	if err := os.MkdirAll(dstDir, 0755); err != nil {
+		return errors.Wrap(err, "core.ExtractArchive: MkdirAll")
+	}
+	realDstDir, err := filepath.EvalSymlinks(dstDir)
+	if err != nil {
+		return errors.Wrap(err, "core.ExtractArchive: EvalSymlinks")
+	}
+	tr := tar.NewReader(content)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "core.ExtractArchive: Next")
+		}
+		target := filepath.Join(dstDir, filepath.FromSlash(header.Name))
+		if !insideDir(dstDir, target) {
+			return errors.Errorf("core.ExtractArchive: entry %s escapes the destination directory", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := checkResolvedPath(realDstDir, target, header.Name); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(target, os.FileMode(header.Mode).Perm()|0700); err != nil {
+				return errors.Wrap(err, "core.ExtractArchive: MkdirAll")
+			}
+		case tar.TypeReg:
+			if err := checkResolvedPath(realDstDir, filepath.Dir(target), header.Name); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return errors.Wrap(err, "core.ExtractArchive: MkdirAll")
+			}
+			// The file replaces a link of the same name rather than being written through it
+			if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+				if err := os.Remove(target); err != nil {
+					return errors.Wrap(err, "core.ExtractArchive: Remove")
+				}
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+			if err != nil {
+				return errors.Wrap(err, "core.ExtractArchive: OpenFile")
+			}
+			_, err = io.Copy(file, tr)
+			file.Close()
+			if err != nil {
+				return errors.Wrap(err, "core.ExtractArchive: Copy")
+			}
+		case tar.TypeSymlink:
+			linkTarget := filepath.FromSlash(header.Linkname)
+			if filepath.IsAbs(linkTarget) || !insideDir(dstDir, filepath.Join(filepath.Dir(target), linkTarget)) {
+				return errors.Errorf("core.ExtractArchive: link %s to %s escapes the destination directory", header.Name, header.Linkname)
+			}
+			if err := checkResolvedPath(realDstDir, filepath.Dir(target), header.Name); err != nil {
+				return err
+			}
+			os.Remove(target)
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return errors.Wrap(err, "core.ExtractArchive: Symlink")
+			}
+		case tar.TypeLink:
+			// The link names an entry of the archive, extracted before it
+			source := filepath.Join(dstDir, filepath.FromSlash(header.Linkname))
+			if !insideDir(dstDir, source) {
+				return errors.Errorf("core.ExtractArchive: link %s to %s escapes the destination directory", header.Name, header.Linkname)
+			}
+			if err := checkResolvedPath(realDstDir, source, header.Name); err != nil {
+				return err
+			}
+			if err := checkResolvedPath(realDstDir, filepath.Dir(target), header.Name); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return errors.Wrap(err, "core.ExtractArchive: MkdirAll")
+			}
+			os.Remove(target)
+			if err := os.Link(source, target); err != nil {
+				return errors.Wrap(err, "core.ExtractArchive: Link")
+			}
+		}
+	}
+}
+
+// insideDir reports whether target is dir or one of its descendants, comparing cleaned paths
+func insideDir(dir, target string) bool {
+	return target == dir || strings.HasPrefix(target, dir+string(os.PathSeparator))
+}
+
+// checkResolvedPath rejects an entry written to dirPath when the symlinks extracted so far make
+// it resolve out of realDstDir. The directories missing yet are resolved from their closest
+// existing parent, which they are created into.
+func checkResolvedPath(realDstDir, dirPath, name string) error {
+	for {
+		resolved, err := filepath.EvalSymlinks(dirPath)
+		if err == nil {
+			if !insideDir(realDstDir, resolved) {
+				return errors.Errorf("core.ExtractArchive: entry %s escapes the destination directory through a link", name)
+			}
+			return nil
+		}
+		if !os.IsNotExist(err) {
+			return errors.Wrap(err, "core.ExtractArchive: EvalSymlinks")
+		}
+		parent := filepath.Dir(dirPath)
+		if parent == dirPath {
+			return errors.Wrap(err, "core.ExtractArchive: EvalSymlinks")
+		}
+		dirPath = parent
+	}
+}
+
+// ListArchiveDir returns the direct children of the root directory of a tar archive,
+// as produced by the Docker archive API for dirPath.
+func ListArchiveDir(content io.Reader, dirPath string) ([]models.FileInfo, error) {
+	tr := tar.NewReader(content)
+	files := make([]models.FileInfo, 0)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "core.ListArchiveDir: Next")
+		}
+		// Entries are rooted at the directory name, so children have exactly two segments,
+		// except when listing the filesystem root.
+		name := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(header.Name, "./"), "/"), "/")
+		parts := strings.Split(name, "/")
+		if dirPath != "/" {
+			if len(parts) != 2 {
+				continue
+			}
+			parts = parts[1:]
+		}
+		if len(parts) != 1 || parts[0] == "" {
+			continue
+		}
+		info := header.FileInfo()
+		files = append(files, models.FileInfo{
+			Name:       parts[0],
+			Path:       path.Join(dirPath, parts[0]),
+			Size:       info.Size(),
+			Mode:       info.Mode(),
+			ModTime:    info.ModTime(),
+			LinkTarget: header.Linkname,
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	srcDir := filepath.Join(t.TempDir(), "app")
+	if err := os.MkdirAll(filepath.Join(srcDir, "config"), 0755); err != nil {
+		t.Fatalf("Failed to create source dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "script.rb"), []byte("puts 1"), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "config", "app.yml"), []byte("env: dev"), 0644); err != nil {
+		t.Fatalf("Failed to create source file: %v", err)
+	}
+
+	// Listing only returns the direct children of the archived directory
+	content, err := ArchivePath(srcDir)
+	if err != nil {
+		t.Fatalf("ArchivePath failed: %v", err)
+	}
+	files, err := ListArchiveDir(content, "/app")
+	content.Close()
+	if err != nil {
+		t.Fatalf("ListArchiveDir failed: %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+	if files[0].Name != "config" || !files[0].IsDir() {
+		t.Errorf("Expected 'config' directory, got '%s'", files[0].Name)
+	}
+	if files[1].Path != "/app/script.rb" {
+		t.Errorf("Expected path '/app/script.rb', got '%s'", files[1].Path)
+	}
+
+	// Extracting recreates the directory under the destination
+	content, err = ArchivePath(srcDir)
+	if err != nil {
+		t.Fatalf("ArchivePath failed: %v", err)
+	}
+	dstDir := t.TempDir()
+	err = ExtractArchive(content, dstDir)
+	content.Close()
+	if err != nil {
+		t.Fatalf("ExtractArchive failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dstDir, "app", "config", "app.yml"))
+	if err != nil {
+		t.Fatalf("Expected extracted file: %v", err)
+	}
+	if string(data) != "env: dev" {
+		t.Errorf("Expected file content 'env: dev', got '%s'", data)
+	}
+}
+
+func TestExtractArchiveRejectsTraversal(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "../evil", Mode: 0644, Size: 4, Typeflag: tar.TypeReg})
+	tw.Write([]byte("evil"))
+	tw.Close()
+
+	if err := ExtractArchive(&buf, t.TempDir()); err == nil {
+		t.Error("Expected error for entry escaping the destination")
+	}
+}
+
+func TestExtractArchiveRejectsSymlinkEscape(t *testing.T) {
+	outsideDir := t.TempDir()
+	archive := func(headers ...*tar.Header) *bytes.Buffer {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, header := range headers {
+			tw.WriteHeader(header)
+			if header.Typeflag == tar.TypeReg {
+				tw.Write([]byte("pwned"))
+			}
+		}
+		tw.Close()
+		return &buf
+	}
+	pwned := &tar.Header{Name: "d/link/pwned", Mode: 0644, Size: 5, Typeflag: tar.TypeReg}
+
+	tests := map[string]*bytes.Buffer{
+		"absolute link": archive(
+			&tar.Header{Name: "d/", Mode: 0755, Typeflag: tar.TypeDir},
+			&tar.Header{Name: "d/link", Linkname: outsideDir, Typeflag: tar.TypeSymlink},
+			pwned,
+		),
+		"relative link": archive(
+			&tar.Header{Name: "d/", Mode: 0755, Typeflag: tar.TypeDir},
+			&tar.Header{Name: "d/link", Linkname: "../../" + filepath.Base(outsideDir), Typeflag: tar.TypeSymlink},
+			pwned,
+		),
+		// Each link stays within the destination textually, but the first one resolves to its
+		// parent, which the second one climbs out of
+		"chained links": archive(
+			&tar.Header{Name: "d/", Mode: 0755, Typeflag: tar.TypeDir},
+			&tar.Header{Name: "top", Linkname: ".", Typeflag: tar.TypeSymlink},
+			&tar.Header{Name: "d/out", Linkname: "../top/..", Typeflag: tar.TypeSymlink},
+			&tar.Header{Name: "d/out/pwned", Mode: 0644, Size: 5, Typeflag: tar.TypeReg},
+		),
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			dstDir := filepath.Join(t.TempDir(), "dst")
+			err := ExtractArchive(content, dstDir)
+			if err == nil || !strings.Contains(err.Error(), "escapes the destination directory") {
+				t.Errorf("Expected error for entry written through a link escaping the destination, got %v", err)
+			}
+			for _, dir := range []string{outsideDir, filepath.Dir(dstDir)} {
+				if _, err := os.Stat(filepath.Join(dir, "pwned")); err == nil {
+					t.Errorf("Expected no file written to %s", dir)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractArchiveHardLinks(t *testing.T) {
+	archive := func(headers ...*tar.Header) *bytes.Buffer {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, header := range headers {
+			tw.WriteHeader(header)
+			if header.Typeflag == tar.TypeReg {
+				tw.Write([]byte("data"))
+			}
+		}
+		tw.Close()
+		return &buf
+	}
+
+	dstDir := t.TempDir()
+	content := archive(
+		&tar.Header{Name: "app/a", Mode: 0644, Size: 4, Typeflag: tar.TypeReg},
+		&tar.Header{Name: "app/d/b", Linkname: "app/a", Typeflag: tar.TypeLink},
+	)
+	if err := ExtractArchive(content, dstDir); err != nil {
+		t.Fatalf("ExtractArchive failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dstDir, "app", "d", "b"))
+	if err != nil {
+		t.Fatalf("Expected extracted hard link: %v", err)
+	}
+	if string(data) != "data" {
+		t.Errorf("Expected file content 'data', got '%s'", data)
+	}
+
+	tests := map[string]*bytes.Buffer{
+		"link out": archive(
+			&tar.Header{Name: "stolen", Linkname: "../secret", Typeflag: tar.TypeLink},
+		),
+		// The link stays within the destination textually, but resolves to its parent through
+		// the symlinks
+		"link through symlinks": archive(
+			&tar.Header{Name: "d/", Mode: 0755, Typeflag: tar.TypeDir},
+			&tar.Header{Name: "top", Linkname: ".", Typeflag: tar.TypeSymlink},
+			&tar.Header{Name: "d/out", Linkname: "../top/..", Typeflag: tar.TypeSymlink},
+			&tar.Header{Name: "stolen", Linkname: "d/out/secret", Typeflag: tar.TypeLink},
+		),
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			rootDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(rootDir, "secret"), []byte("secret"), 0600); err != nil {
+				t.Fatalf("Failed to create outside file: %v", err)
+			}
+			dstDir := filepath.Join(rootDir, "dst")
+			err := ExtractArchive(content, dstDir)
+			if err == nil || !strings.Contains(err.Error(), "escapes the destination directory") {
+				t.Errorf("Expected error for hard link escaping the destination, got %v", err)
+			}
+			if _, err := os.Lstat(filepath.Join(dstDir, "stolen")); err == nil {
+				t.Error("Expected no hard link to the outside file")
+			}
+		})
+	}
+}
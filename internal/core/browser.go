@@ -3,24 +3,57 @@ package core
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"maps"
 	"slices"
+	"sync"
 
 	"github.com/mendes11/swarm-browser/internal/core/models"
 	"github.com/mendes11/swarm-browser/internal/services/connector"
 	"github.com/moby/moby/api/types/filters"
+	"github.com/moby/moby/api/types/network"
 	"github.com/moby/moby/api/types/swarm"
+	"github.com/moby/moby/api/types/system"
+	"github.com/moby/moby/api/types/volume"
+	"github.com/moby/moby/client"
 	"github.com/pkg/errors"
 )
 
 // ClusterBrowser exposes methods to browse a specific Swarm Cluster
 type ClusterBrowser interface {
-	InspectNode(models.Node) (*models.NodeInfo, error)
+	InspectNode(ctx context.Context, node models.Node) (*models.NodeInfo, error)
 	ListStacks(ctx context.Context) ([]models.Stack, error)
 	ListServices(ctx context.Context, stack models.Stack) ([]models.Service, error)
 	ListTasks(ctx context.Context, service models.Service) ([]models.Task, error)
+	ListNetworks(ctx context.Context) ([]models.Network, error)
+	ListNetworkAttachments(ctx context.Context, network models.Network) ([]models.NetworkAttachment, error)
+	ListTaskMounts(ctx context.Context, task models.Task) ([]models.Mount, error)
+	ListNodeVolumes(ctx context.Context, node models.Node) ([]models.Volume, error)
+	StreamTaskStats(ctx context.Context, tasks []models.Task) (<-chan models.ContainerStats, error)
+	StatContainerPath(ctx context.Context, task models.Task, path string) (models.FileInfo, error)
+	// ContainerWorkingDir returns the working directory of the task container, / when unset
+	ContainerWorkingDir(ctx context.Context, task models.Task) (string, error)
+	ListContainerDir(ctx context.Context, task models.Task, dirPath string) ([]models.FileInfo, error)
+	CopyFromContainer(ctx context.Context, task models.Task, srcPath string) (io.ReadCloser, models.FileInfo, error)
+	CopyToContainer(ctx context.Context, task models.Task, dstDir string, content io.Reader) error
+	StartPortForward(ctx context.Context, task models.Task, localPort int, remotePort int) (models.PortForward, error)
+	StopPortForward(ctx context.Context, forward models.PortForward) error
+	ListPortForwards() []models.PortForward
 	AttachToService(ctx context.Context, service models.Service, cmd []string) (ContainerConnection, error)
 	AttachToTask(ctx context.Context, task models.Task, cmd []string) (ContainerConnection, error)
+	// RunInContainer runs a non-interactive command in the task container until it exits,
+	// feeding it stdin (if not nil) and collecting its output and exit code
+	RunInContainer(ctx context.Context, task models.Task, cmd []string, stdin io.Reader) (models.ExecResult, error)
+	// Exec sessions keep running after detaching from them, until killed or the browser is closed
+	DetachSession(conn ContainerConnection) (models.ExecSession, error)
+	ListDetachedSessions() []models.ExecSession
+	ReattachSession(ctx context.Context, id int) (ContainerConnection, error)
+	KillSession(id int) error
+	// Debug sidecars share the namespaces of a task container, providing tools (and a shell)
+	// its image lacks. The returned task describes the sidecar, to open sessions in it.
+	StartDebugSidecar(ctx context.Context, task models.Task, image string) (models.Task, error)
+	RemoveDebugSidecar(ctx context.Context, sidecar models.Task) error
 
 	// Closes all open connections to the cluster nodes / containers
 	Close() error
@@ -29,6 +62,17 @@ type ClusterBrowser interface {
 type SwarmConnector struct {
 	Cluster   models.Cluster
 	connector *connector.DockerConnector
+
+	// Active port forwards, keyed by local port
+	forwardsMu sync.Mutex
+	forwards   map[int]models.PortForward
+
+	// Exec sessions opened in the cluster containers
+	sessions *ExecSessions
+
+	// Running debug sidecars, keyed by container ID
+	sidecarsMu sync.Mutex
+	sidecars   map[string]models.Task
 }
 
 // Ensure it conforms to the interface
@@ -38,16 +82,19 @@ func New(cluster models.Cluster) *SwarmConnector {
 	return &SwarmConnector{
 		Cluster:   cluster,
 		connector: connector.NewConnector(),
+		forwards:  make(map[int]models.PortForward),
+		sessions:  NewExecSessions(),
+		sidecars:  make(map[string]models.Task),
 	}
 }
 
-func (s *SwarmConnector) InspectNode(node models.Node) (*models.NodeInfo, error) {
+func (s *SwarmConnector) InspectNode(ctx context.Context, node models.Node) (*models.NodeInfo, error) {
 	conn, err := s.connector.ClientForHost(node.Host)
 	if err != nil {
 		return nil, errors.Wrap(err, "browser.SwarmConnector#InspectNode: ClientForHost")
 	}
 	filter := filters.NewArgs(filters.KeyValuePair{Key: "name", Value: node.Hostname})
-	nodes, err := conn.NodeList(context.Background(), swarm.NodeListOptions{Filters: filter})
+	nodes, err := conn.NodeList(ctx, swarm.NodeListOptions{Filters: filter})
 	if err != nil {
 		return nil, errors.Wrap(err, "browser.SwarmConnector#InspectNode: NodeList")
 	}
@@ -55,17 +102,25 @@ func (s *SwarmConnector) InspectNode(node models.Node) (*models.NodeInfo, error)
 	if len(nodes) == 0 {
 		return nil, errors.Errorf("browser.SwarmConnector#InspectNode: node %s not found", node.Hostname)
 	}
-	nodeInfo, _, err := conn.NodeInspectWithRaw(context.Background(), nodes[0].ID)
+	nodeInfo, _, err := conn.NodeInspectWithRaw(ctx, nodes[0].ID)
 	if err != nil {
 		return nil, errors.Wrap(err, "browser.SwarmConnector#InspectNode: NodeInspectWithRaw")
 	}
-	return &models.NodeInfo{
-		Role:     string(nodeInfo.Spec.Role),
-		Name:     nodeInfo.Spec.Name,
-		Platform: fmt.Sprintf("%s - %s", nodeInfo.Description.Platform.Architecture, nodeInfo.Description.Platform.OS),
-		CPUs:     nodeInfo.Description.Resources.NanoCPUs,
-		Memory:   nodeInfo.Description.Resources.MemoryBytes,
-	}, nil
+	info := newNodeInfo(nodeInfo)
+	return &info, nil
+}
+
+// newNodeInfo describes an inspected node
+func newNodeInfo(node swarm.Node) models.NodeInfo {
+	return models.NodeInfo{
+		Role:         string(node.Spec.Role),
+		Name:         node.Spec.Name,
+		Platform:     fmt.Sprintf("%s - %s", node.Description.Platform.Architecture, node.Description.Platform.OS),
+		CPUs:         node.Description.Resources.NanoCPUs / 1e9,
+		Memory:       node.Description.Resources.MemoryBytes,
+		State:        string(node.Status.State),
+		Availability: string(node.Spec.Availability),
+	}
 }
 
 // AttachToService implements ClusterBrowser.
@@ -79,11 +134,11 @@ func (s *SwarmConnector) AttachToService(ctx context.Context, service models.Ser
 		if task.Status != swarm.TaskStateRunning {
 			continue
 		}
-		containerConn, err := s.connector.AttachToContainer(ctx, task.Node.Host, task.ContainerID, cmd)
+		containerConn, err := s.connector.AttachToContainer(ctx, task.Node.Host, task.ContainerID, cmd, true)
 		if err != nil {
 			return nil, errors.Wrap(err, "connector.SwarmConnector#AttachToService: AttachToContainer")
 		}
-		return containerConn, nil
+		return s.sessions.Open(containerConn, models.ExecSession{Service: &service, Cmd: cmd}), nil
 	}
 	return nil, fmt.Errorf("connector.SwarmConnector#AttachToService: unable to attach to a running container")
 }
@@ -94,16 +149,43 @@ func (s *SwarmConnector) AttachToTask(ctx context.Context, task models.Task, cmd
 		return nil, fmt.Errorf("connector.SwarmConnector#AttachToTask: task %s is not running (status: %s)", task.TaskID, task.Status)
 	}
 
-	containerConn, err := s.connector.AttachToContainer(ctx, task.Node.Host, task.ContainerID, cmd)
+	containerConn, err := s.connector.AttachToContainer(ctx, task.Node.Host, task.ContainerID, cmd, true)
 	if err != nil {
 		return nil, errors.Wrap(err, "connector.SwarmConnector#AttachToTask: AttachToContainer")
 	}
 
-	return containerConn, nil
+	return s.sessions.Open(containerConn, models.ExecSession{Task: &task, Cmd: cmd}), nil
+}
+
+// DetachSession implements ClusterBrowser.
+func (s *SwarmConnector) DetachSession(conn ContainerConnection) (models.ExecSession, error) {
+	return s.sessions.Detach(conn)
+}
+
+// ListDetachedSessions implements ClusterBrowser.
+func (s *SwarmConnector) ListDetachedSessions() []models.ExecSession {
+	return s.sessions.List()
+}
+
+// ReattachSession implements ClusterBrowser.
+func (s *SwarmConnector) ReattachSession(ctx context.Context, id int) (ContainerConnection, error) {
+	return s.sessions.Reattach(id)
+}
+
+// KillSession implements ClusterBrowser.
+func (s *SwarmConnector) KillSession(id int) error {
+	return s.sessions.Kill(id)
 }
 
 // Close implements Clusterconnector.
 func (s *SwarmConnector) Close() error {
+	// Forwards go down along with the SSH connections
+	s.forwardsMu.Lock()
+	clear(s.forwards)
+	s.forwardsMu.Unlock()
+	s.sessions.CloseAll()
+	s.removeDebugSidecars()
+
 	err := s.connector.Close()
 	if err != nil {
 		return errors.Wrap(err, "connector.SwarmConnector#Close: Close")
@@ -133,14 +215,50 @@ func (s *SwarmConnector) ListServices(ctx context.Context, stack models.Stack) (
 			Name:         service.Spec.Name,
 			RunningTasks: service.ServiceStatus.RunningTasks,
 			Stack:        stack,
+			Labels:       service.Spec.Labels,
 		}
 		if service.Spec.Mode.Replicated != nil {
 			services[i].DesiredTasks = *service.Spec.Mode.Replicated.Replicas
+		} else if service.ServiceStatus != nil {
+			services[i].DesiredTasks = service.ServiceStatus.DesiredTasks
+		}
+		if service.Spec.TaskTemplate.ContainerSpec != nil {
+			services[i].Image = service.Spec.TaskTemplate.ContainerSpec.Image
 		}
+		describeServiceSpec(&services[i], service)
 	}
 	return services, nil
 }
 
+// describeServiceSpec fills the summary of the spec of a service: its mode, published ports,
+// placement constraints and last update
+func describeServiceSpec(svc *models.Service, service swarm.Service) {
+	switch mode := service.Spec.Mode; {
+	case mode.Global != nil:
+		svc.Mode = "global"
+	case mode.ReplicatedJob != nil:
+		svc.Mode = "replicated-job"
+	case mode.GlobalJob != nil:
+		svc.Mode = "global-job"
+	default:
+		svc.Mode = "replicated"
+	}
+	for _, port := range service.Endpoint.Ports {
+		svc.Ports = append(svc.Ports, fmt.Sprintf("%d:%d/%s", port.PublishedPort, port.TargetPort, port.Protocol))
+	}
+	if placement := service.Spec.TaskTemplate.Placement; placement != nil {
+		svc.Constraints = placement.Constraints
+	}
+	if update := service.UpdateStatus; update != nil {
+		svc.UpdateState = string(update.State)
+		if update.CompletedAt != nil {
+			svc.UpdatedAt = *update.CompletedAt
+		} else if update.StartedAt != nil {
+			svc.UpdatedAt = *update.StartedAt
+		}
+	}
+}
+
 // ListStacks implements Clusterconnector.
 func (s *SwarmConnector) ListStacks(ctx context.Context) ([]models.Stack, error) {
 	cli, err := s.connector.ClientForHost(s.Cluster.Host)
@@ -179,6 +297,7 @@ func (s *SwarmConnector) ListTasks(ctx context.Context, service models.Service)
 	}
 	tasks := make([]models.Task, len(tasksResp))
 	nodeIDMap := make(map[string]models.Node)
+	nodeInfos := make(map[string]models.NodeInfo)
 	for i, task := range tasksResp {
 		if _, exists := nodeIDMap[task.NodeID]; !exists {
 			node, _, err := cli.NodeInspectWithRaw(ctx, task.NodeID)
@@ -190,13 +309,216 @@ func (s *SwarmConnector) ListTasks(ctx context.Context, service models.Service)
 				return nil, fmt.Errorf("connector.SwarmConnector#ListTasks: node hostname %s is missing in the cluster configurations", node.Description.Hostname)
 			}
 			nodeIDMap[task.NodeID] = nodeInfo
+			nodeInfos[task.NodeID] = newNodeInfo(node)
 		}
 		tasks[i] = models.Task{
-			TaskID:      task.ID,
-			ContainerID: task.Status.ContainerStatus.ContainerID,
-			Node:        nodeIDMap[task.NodeID],
-			Status:      task.Status.State,
+			TaskID:       task.ID,
+			ContainerID:  task.Status.ContainerStatus.ContainerID,
+			Node:         nodeIDMap[task.NodeID],
+			NodeInfo:     nodeInfos[task.NodeID],
+			Status:       task.Status.State,
+			DesiredState: task.DesiredState,
+			Message:      task.Status.Message,
+			Err:          task.Status.Err,
+			ExitCode:     task.Status.ContainerStatus.ExitCode,
+			CreatedAt:    task.CreatedAt,
+		}
+		// The labels of the service, so that the tasks match the same label queries, along
+		// with the ones of their container, which take precedence
+		tasks[i].Labels = make(map[string]string)
+		maps.Copy(tasks[i].Labels, service.Labels)
+		if task.Spec.ContainerSpec != nil {
+			tasks[i].Image = task.Spec.ContainerSpec.Image
+			maps.Copy(tasks[i].Labels, task.Spec.ContainerSpec.Labels)
 		}
 	}
 	return tasks, nil
 }
+
+// ListNetworks implements ClusterBrowser.
+func (s *SwarmConnector) ListNetworks(ctx context.Context) ([]models.Network, error) {
+	cli, err := s.connector.ClientForHost(s.Cluster.Host)
+	if err != nil {
+		return nil, errors.Wrap(err, "connector.SwarmConnector#ListNetworks: ClientForHost")
+	}
+	filter := filters.NewArgs()
+	filter.Add("driver", "overlay")
+	networksResp, err := cli.NetworkList(ctx, network.ListOptions{Filters: filter})
+	if err != nil {
+		return nil, errors.Wrap(err, "connector.SwarmConnector#ListNetworks: NetworkList")
+	}
+	networks := make([]models.Network, len(networksResp))
+	for i, net := range networksResp {
+		subnets := make([]string, 0, len(net.IPAM.Config))
+		for _, ipamConfig := range net.IPAM.Config {
+			if ipamConfig.Subnet != "" {
+				subnets = append(subnets, ipamConfig.Subnet)
+			}
+		}
+		networks[i] = models.Network{
+			ID:         net.ID,
+			Name:       net.Name,
+			Driver:     net.Driver,
+			Scope:      net.Scope,
+			Subnets:    subnets,
+			Attachable: net.Attachable,
+			Ingress:    net.Ingress,
+			Internal:   net.Internal,
+		}
+	}
+	return networks, nil
+}
+
+// ListNetworkAttachments implements ClusterBrowser.
+//
+// It returns the virtual IP of every service attached to the network followed by
+// the addresses of their running tasks.
+func (s *SwarmConnector) ListNetworkAttachments(ctx context.Context, net models.Network) ([]models.NetworkAttachment, error) {
+	cli, err := s.connector.ClientForHost(s.Cluster.Host)
+	if err != nil {
+		return nil, errors.Wrap(err, "connector.SwarmConnector#ListNetworkAttachments: ClientForHost")
+	}
+	servicesResp, err := cli.ServiceList(ctx, swarm.ServiceListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "connector.SwarmConnector#ListNetworkAttachments: ServiceList")
+	}
+	attachments := make([]models.NetworkAttachment, 0)
+	servicesMap := make(map[string]models.Service)
+	for _, service := range servicesResp {
+		svc := models.Service{
+			ID:    service.ID,
+			Name:  service.Spec.Name,
+			Stack: models.Stack{Name: service.Spec.Labels["com.docker.stack.namespace"]},
+		}
+		servicesMap[service.ID] = svc
+		for _, vip := range service.Endpoint.VirtualIPs {
+			if vip.NetworkID == net.ID {
+				attachments = append(attachments, models.NetworkAttachment{Service: svc, Address: vip.Addr})
+			}
+		}
+	}
+
+	filter := filters.NewArgs()
+	filter.Add("desired-state", "running")
+	tasksResp, err := cli.TaskList(ctx, swarm.TaskListOptions{Filters: filter})
+	if err != nil {
+		return nil, errors.Wrap(err, "connector.SwarmConnector#ListNetworkAttachments: TaskList")
+	}
+	nodeIDMap := make(map[string]models.Node)
+	for _, task := range tasksResp {
+		for _, attachment := range task.NetworksAttachments {
+			if attachment.Network.ID != net.ID {
+				continue
+			}
+			if _, exists := nodeIDMap[task.NodeID]; !exists {
+				node, _, err := cli.NodeInspectWithRaw(ctx, task.NodeID)
+				if err != nil {
+					return nil, errors.Wrap(err, "connector.SwarmConnector#ListNetworkAttachments: NodeInspectWithRaw")
+				}
+				nodeInfo, found := s.Cluster.GetNodeByHostname(node.Description.Hostname)
+				if !found {
+					// Nodes missing in the configuration are still displayed by hostname
+					nodeInfo = models.Node{Hostname: node.Description.Hostname}
+				}
+				nodeIDMap[task.NodeID] = nodeInfo
+			}
+			for _, addr := range attachment.Addresses {
+				attachments = append(attachments, models.NetworkAttachment{
+					Service: servicesMap[task.ServiceID],
+					TaskID:  task.ID,
+					Node:    nodeIDMap[task.NodeID],
+					Address: addr,
+				})
+			}
+		}
+	}
+	return attachments, nil
+}
+
+// ListTaskMounts implements ClusterBrowser.
+//
+// The task container is inspected directly on its node, since Swarm managers
+// don't expose the container mounts.
+func (s *SwarmConnector) ListTaskMounts(ctx context.Context, task models.Task) ([]models.Mount, error) {
+	if task.ContainerID == "" {
+		return nil, fmt.Errorf("connector.SwarmConnector#ListTaskMounts: task %s has no container", task.TaskID)
+	}
+	cli, err := s.connector.ClientForHost(task.Node.Host)
+	if err != nil {
+		return nil, errors.Wrap(err, "connector.SwarmConnector#ListTaskMounts: ClientForHost")
+	}
+	containerInfo, err := cli.ContainerInspect(ctx, task.ContainerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "connector.SwarmConnector#ListTaskMounts: ContainerInspect")
+	}
+	usage, err := volumesUsage(ctx, cli)
+	if err != nil {
+		// Sizes are optional, so we still return the mounts without them
+		log.Printf("connector.SwarmConnector#ListTaskMounts: unable to retrieve volumes usage: %v\n", err)
+	}
+	mounts := make([]models.Mount, len(containerInfo.Mounts))
+	for i, mount := range containerInfo.Mounts {
+		mounts[i] = models.Mount{
+			Type:        string(mount.Type),
+			Name:        mount.Name,
+			Source:      mount.Source,
+			Destination: mount.Destination,
+			Driver:      mount.Driver,
+			Mode:        mount.Mode,
+			RW:          mount.RW,
+			Size:        -1,
+		}
+		if data, exists := usage[mount.Name]; exists && mount.Name != "" {
+			mounts[i].Size = data.Size
+		}
+	}
+	return mounts, nil
+}
+
+// ListNodeVolumes implements ClusterBrowser.
+func (s *SwarmConnector) ListNodeVolumes(ctx context.Context, node models.Node) ([]models.Volume, error) {
+	cli, err := s.connector.ClientForHost(node.Host)
+	if err != nil {
+		return nil, errors.Wrap(err, "connector.SwarmConnector#ListNodeVolumes: ClientForHost")
+	}
+	volumesResp, err := cli.VolumeList(ctx, volume.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "connector.SwarmConnector#ListNodeVolumes: VolumeList")
+	}
+	usage, err := volumesUsage(ctx, cli)
+	if err != nil {
+		log.Printf("connector.SwarmConnector#ListNodeVolumes: unable to retrieve volumes usage: %v\n", err)
+	}
+	volumes := make([]models.Volume, len(volumesResp.Volumes))
+	for i, vol := range volumesResp.Volumes {
+		volumes[i] = models.Volume{
+			Name:       vol.Name,
+			Driver:     vol.Driver,
+			Scope:      vol.Scope,
+			Mountpoint: vol.Mountpoint,
+			Size:       -1,
+			RefCount:   -1,
+			Node:       node,
+		}
+		if data, exists := usage[vol.Name]; exists {
+			volumes[i].Size = data.Size
+			volumes[i].RefCount = data.RefCount
+		}
+	}
+	return volumes, nil
+}
+
+// volumesUsage returns the disk usage of every volume in the host, keyed by volume name
+func volumesUsage(ctx context.Context, cli *client.Client) (map[string]volume.UsageData, error) {
+	diskUsage, err := cli.DiskUsage(ctx, system.DiskUsageOptions{Types: []system.DiskUsageObject{system.VolumeObject}})
+	if err != nil {
+		return nil, errors.Wrap(err, "volumesUsage: DiskUsage")
+	}
+	usage := make(map[string]volume.UsageData, len(diskUsage.Volumes))
+	for _, vol := range diskUsage.Volumes {
+		if vol != nil && vol.UsageData != nil {
+			usage[vol.Name] = *vol.UsageData
+		}
+	}
+	return usage, nil
+}
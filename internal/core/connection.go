@@ -2,12 +2,16 @@ package core
 
 import (
 	"context"
+	"io"
 	"net"
 )
 
 type ContainerConnection interface {
 	ResizeTTY(ctx context.Context, width, height uint) error
 	ContainerID() string
+	// Conn is written the input of the container. Its output is read from Reader, which may
+	// hold output read along with the attach response.
 	Conn() net.Conn
+	Reader() io.Reader
 	Close() error
 }
@@ -0,0 +1,240 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/client"
+	"github.com/pkg/errors"
+)
+
+// StatContainerPath implements ClusterBrowser.
+func (s *SwarmConnector) StatContainerPath(ctx context.Context, task models.Task, filePath string) (models.FileInfo, error) {
+	cli, err := s.taskClient(task)
+	if err != nil {
+		return models.FileInfo{}, errors.Wrap(err, "connector.SwarmConnector#StatContainerPath: taskClient")
+	}
+	stat, err := cli.ContainerStatPath(ctx, task.ContainerID, filePath)
+	if err != nil {
+		return models.FileInfo{}, errors.Wrap(err, "connector.SwarmConnector#StatContainerPath: ContainerStatPath")
+	}
+	return fileInfoFromStat(filePath, stat), nil
+}
+
+// ContainerWorkingDir implements ClusterBrowser.
+func (s *SwarmConnector) ContainerWorkingDir(ctx context.Context, task models.Task) (string, error) {
+	cli, err := s.taskClient(task)
+	if err != nil {
+		return "", errors.Wrap(err, "connector.SwarmConnector#ContainerWorkingDir: taskClient")
+	}
+	containerInfo, err := cli.ContainerInspect(ctx, task.ContainerID)
+	if err != nil {
+		return "", errors.Wrap(err, "connector.SwarmConnector#ContainerWorkingDir: ContainerInspect")
+	}
+	if containerInfo.Config == nil {
+		return "/", nil
+	}
+	return cmp.Or(containerInfo.Config.WorkingDir, "/"), nil
+}
+
+// listDirScript prints the mode, size and modification time of the entries of the directory
+// given as argument, then an empty line and the targets of the symlinks among them, in order
+const listDirScript = `cd -- "$1" || exit
+set --
+for f in .* *; do
+	case $f in .|..) continue ;; esac
+	{ [ -e "$f" ] || [ -L "$f" ]; } && set -- "$@" "$f"
+done
+[ $# -eq 0 ] && exit 0
+stat -c '%f %s %Y %n' -- "$@" || exit
+echo
+for f in "$@"; do
+	[ -L "$f" ] && readlink -- "$f"
+done
+exit 0`
+
+// ListContainerDir implements ClusterBrowser.
+//
+// The directory is listed with stat through a non-interactive exec. Containers without a shell
+// or stat, as well as stopped ones, are listed by reading the headers of the archive of the
+// directory instead, which streams its whole tree from the node.
+func (s *SwarmConnector) ListContainerDir(ctx context.Context, task models.Task, dirPath string) ([]models.FileInfo, error) {
+	result, err := s.RunInContainer(ctx, task, []string{"/bin/sh", "-c", listDirScript, "sh", dirPath}, nil)
+	if ctx.Err() != nil {
+		return nil, errors.Wrap(ctx.Err(), "connector.SwarmConnector#ListContainerDir")
+	}
+	// Exit codes 126 and 127 mean /bin/sh or stat couldn't be run
+	if err != nil || result.ExitCode == 126 || result.ExitCode == 127 {
+		return s.listArchiveDir(ctx, task, dirPath)
+	}
+	if result.ExitCode != 0 {
+		return nil, errors.Errorf("connector.SwarmConnector#ListContainerDir: failed to list %s: %s", dirPath, strings.TrimSpace(string(result.Stderr)))
+	}
+	files, err := parseDirListing(result.Stdout, dirPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "connector.SwarmConnector#ListContainerDir")
+	}
+	return files, nil
+}
+
+// listArchiveDir lists a directory from the headers of its archive
+func (s *SwarmConnector) listArchiveDir(ctx context.Context, task models.Task, dirPath string) ([]models.FileInfo, error) {
+	content, stat, err := s.CopyFromContainer(ctx, task, dirPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "connector.SwarmConnector#ListContainerDir: CopyFromContainer")
+	}
+	defer content.Close()
+	if !stat.IsDir() {
+		return nil, errors.Errorf("connector.SwarmConnector#ListContainerDir: %s is not a directory", dirPath)
+	}
+	files, err := ListArchiveDir(content, dirPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "connector.SwarmConnector#ListContainerDir: ListArchiveDir")
+	}
+	return files, nil
+}
+
+// parseDirListing reads the output of listDirScript for dirPath
+func parseDirListing(output []byte, dirPath string) ([]models.FileInfo, error) {
+	stats, links, _ := strings.Cut(string(output), "\n\n")
+	targets := strings.Split(links, "\n")
+	files := make([]models.FileInfo, 0)
+	for _, line := range strings.Split(stats, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 4)
+		if len(fields) != 4 {
+			return nil, errors.Errorf("unexpected stat output %q", line)
+		}
+		rawMode, errMode := strconv.ParseUint(fields[0], 16, 32)
+		size, errSize := strconv.ParseInt(fields[1], 10, 64)
+		modTime, errTime := strconv.ParseInt(fields[2], 10, 64)
+		if err := cmp.Or(errMode, errSize, errTime); err != nil {
+			return nil, errors.Wrapf(err, "unexpected stat output %q", line)
+		}
+		file := models.FileInfo{
+			Name:    fields[3],
+			Path:    path.Join(dirPath, fields[3]),
+			Size:    size,
+			Mode:    unixFileMode(uint32(rawMode)),
+			ModTime: time.Unix(modTime, 0),
+		}
+		if file.Mode&os.ModeSymlink != 0 && len(targets) > 0 {
+			file.LinkTarget, targets = targets[0], targets[1:]
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// unixFileMode converts a raw Unix file mode, as printed by stat, to an os.FileMode
+func unixFileMode(raw uint32) os.FileMode {
+	mode := os.FileMode(raw & 0777)
+	switch raw & 0170000 {
+	case 0040000:
+		mode |= os.ModeDir
+	case 0120000:
+		mode |= os.ModeSymlink
+	case 0010000:
+		mode |= os.ModeNamedPipe
+	case 0140000:
+		mode |= os.ModeSocket
+	case 0020000:
+		mode |= os.ModeDevice | os.ModeCharDevice
+	case 0060000:
+		mode |= os.ModeDevice
+	}
+	if raw&04000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if raw&02000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if raw&01000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode
+}
+
+// CopyFromContainer implements ClusterBrowser.
+//
+// It returns a tar archive of srcPath, which must be closed by the caller.
+func (s *SwarmConnector) CopyFromContainer(ctx context.Context, task models.Task, srcPath string) (io.ReadCloser, models.FileInfo, error) {
+	cli, err := s.taskClient(task)
+	if err != nil {
+		return nil, models.FileInfo{}, errors.Wrap(err, "connector.SwarmConnector#CopyFromContainer: taskClient")
+	}
+	content, stat, err := cli.CopyFromContainer(ctx, task.ContainerID, srcPath)
+	if err != nil {
+		return nil, models.FileInfo{}, errors.Wrap(err, "connector.SwarmConnector#CopyFromContainer: CopyFromContainer")
+	}
+	return content, fileInfoFromStat(srcPath, stat), nil
+}
+
+// CopyToContainer implements ClusterBrowser.
+//
+// content must be a tar archive, which is extracted into the dstDir directory.
+func (s *SwarmConnector) CopyToContainer(ctx context.Context, task models.Task, dstDir string, content io.Reader) error {
+	cli, err := s.taskClient(task)
+	if err != nil {
+		return errors.Wrap(err, "connector.SwarmConnector#CopyToContainer: taskClient")
+	}
+	if err := cli.CopyToContainer(ctx, task.ContainerID, dstDir, content, container.CopyToContainerOptions{}); err != nil {
+		return errors.Wrap(err, "connector.SwarmConnector#CopyToContainer: CopyToContainer")
+	}
+	return nil
+}
+
+// taskClient returns the Docker client of the node running the task container
+func (s *SwarmConnector) taskClient(task models.Task) (*client.Client, error) {
+	if task.ContainerID == "" {
+		return nil, errors.Errorf("task %s has no container", task.TaskID)
+	}
+	return s.connector.ClientForHost(task.Node.Host)
+}
+
+func fileInfoFromStat(filePath string, stat container.PathStat) models.FileInfo {
+	return models.FileInfo{
+		Name:       stat.Name,
+		Path:       path.Clean(filePath),
+		Size:       stat.Size,
+		Mode:       stat.Mode,
+		ModTime:    stat.Mtime,
+		LinkTarget: stat.LinkTarget,
+	}
+}
+
+// DownloadFromTask copies srcPath from the task container into the local dstDir directory
+func DownloadFromTask(ctx context.Context, browser ClusterBrowser, task models.Task, srcPath string, dstDir string) error {
+	content, _, err := browser.CopyFromContainer(ctx, task, srcPath)
+	if err != nil {
+		return errors.Wrap(err, "core.DownloadFromTask: CopyFromContainer")
+	}
+	defer content.Close()
+	if err := ExtractArchive(content, dstDir); err != nil {
+		return errors.Wrap(err, "core.DownloadFromTask: ExtractArchive")
+	}
+	return nil
+}
+
+// UploadToTask copies the local srcPath file or directory into the dstDir directory of the task container
+func UploadToTask(ctx context.Context, browser ClusterBrowser, task models.Task, srcPath string, dstDir string) error {
+	content, err := ArchivePath(srcPath)
+	if err != nil {
+		return errors.Wrap(err, "core.UploadToTask: ArchivePath")
+	}
+	defer content.Close()
+	if err := browser.CopyToContainer(ctx, task, dstDir, content); err != nil {
+		return errors.Wrap(err, "core.UploadToTask: CopyToContainer")
+	}
+	return nil
+}
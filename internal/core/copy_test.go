@@ -0,0 +1,74 @@
+package core
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestListDirScript(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "config"), 0750); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app name.rb"), []byte("puts 1"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("A=1\n"), 0600); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := os.Symlink("config", filepath.Join(dir, "current")); err != nil {
+		t.Fatalf("Failed to create link: %v", err)
+	}
+	if err := os.Symlink("missing", filepath.Join(dir, "dangling")); err != nil {
+		t.Fatalf("Failed to create link: %v", err)
+	}
+
+	output, err := exec.Command("/bin/sh", "-c", listDirScript, "sh", dir).Output()
+	if err != nil {
+		t.Fatalf("Listing script failed: %v", err)
+	}
+	files, err := parseDirListing(output, "/app")
+	if err != nil {
+		t.Fatalf("parseDirListing failed: %v", err)
+	}
+	byName := make(map[string]int)
+	for i, file := range files {
+		byName[file.Name] = i
+	}
+	if len(files) != 5 {
+		t.Fatalf("Expected 5 files, got %+v", files)
+	}
+
+	file := files[byName["app name.rb"]]
+	if file.Path != "/app/app name.rb" || file.Size != 6 || file.Mode != 0644 {
+		t.Errorf("Unexpected file %+v", file)
+	}
+	if file := files[byName[".env"]]; file.Mode != 0600 {
+		t.Errorf("Expected hidden file with mode 0600, got %+v", file)
+	}
+	if file := files[byName["config"]]; file.Mode != os.ModeDir|0750 || !file.IsDir() {
+		t.Errorf("Expected directory, got %+v", file)
+	}
+	for name, target := range map[string]string{"current": "config", "dangling": "missing"} {
+		if file := files[byName[name]]; file.Mode&os.ModeSymlink == 0 || file.LinkTarget != target {
+			t.Errorf("Expected link to %s, got %+v", target, file)
+		}
+	}
+	info, _ := os.Stat(filepath.Join(dir, "app name.rb"))
+	if !file.ModTime.Equal(info.ModTime().Truncate(1e9)) {
+		t.Errorf("Expected modification time %v, got %v", info.ModTime(), file.ModTime)
+	}
+}
+
+func TestListDirScriptEmptyDir(t *testing.T) {
+	output, err := exec.Command("/bin/sh", "-c", listDirScript, "sh", t.TempDir()).Output()
+	if err != nil {
+		t.Fatalf("Listing script failed: %v", err)
+	}
+	files, err := parseDirListing(output, "/empty")
+	if err != nil || len(files) != 0 {
+		t.Errorf("Expected no files, got %+v, %v", files, err)
+	}
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/image"
+	"github.com/moby/moby/api/types/strslice"
+	"github.com/pkg/errors"
+)
+
+// DefaultDebugImage is the image of the debug sidecars, bundling network and process tools
+const DefaultDebugImage = "nicolaka/netshoot"
+
+// debugSidecarLabel marks the sidecar containers, with the ID of the debugged container
+const debugSidecarLabel = "swarm-browser.debug"
+
+// StartDebugSidecar implements ClusterBrowser.
+//
+// The sidecar runs on the node of the task and joins the network, PID and IPC namespaces of
+// its container. Docker has no way to join a mount namespace, so the container volumes are
+// mounted in the sidecar instead, and its root filesystem is reachable at /proc/1/root.
+func (s *SwarmConnector) StartDebugSidecar(ctx context.Context, task models.Task, debugImage string) (models.Task, error) {
+	if task.Status != "running" {
+		return models.Task{}, fmt.Errorf("core.SwarmConnector#StartDebugSidecar: task %s is not running (status: %s)", task.TaskID, task.Status)
+	}
+	cli, err := s.connector.ClientForHost(task.Node.Host)
+	if err != nil {
+		return models.Task{}, errors.Wrap(err, "core.SwarmConnector#StartDebugSidecar: ClientForHost")
+	}
+
+	if _, err := cli.ImageInspect(ctx, debugImage); err != nil {
+		log.Printf("core.SwarmConnector#StartDebugSidecar: Pulling %s on %s\n", debugImage, task.Node.Hostname)
+		progress, err := cli.ImagePull(ctx, debugImage, image.PullOptions{})
+		if err != nil {
+			return models.Task{}, errors.Wrap(err, "core.SwarmConnector#StartDebugSidecar: ImagePull")
+		}
+		// The pull completes once its progress is read
+		_, err = io.Copy(io.Discard, progress)
+		progress.Close()
+		if err != nil {
+			return models.Task{}, errors.Wrap(err, "core.SwarmConnector#StartDebugSidecar: ImagePull")
+		}
+	}
+
+	target := "container:" + task.ContainerID
+	created, err := cli.ContainerCreate(ctx, &container.Config{
+		Image: debugImage,
+		// Keep the sidecar idle, the sessions are execs
+		Entrypoint: strslice.StrSlice{"sleep", "infinity"},
+		Labels:     map[string]string{debugSidecarLabel: task.ContainerID},
+	}, &container.HostConfig{
+		NetworkMode: container.NetworkMode(target),
+		PidMode:     container.PidMode(target),
+		IpcMode:     container.IpcMode(target),
+		VolumesFrom: []string{task.ContainerID},
+		CapAdd:      strslice.StrSlice{"SYS_PTRACE", "NET_ADMIN", "NET_RAW"},
+		AutoRemove:  true,
+	}, nil, nil, "")
+	if err != nil {
+		return models.Task{}, errors.Wrap(err, "core.SwarmConnector#StartDebugSidecar: ContainerCreate")
+	}
+	if err := cli.ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		cli.ContainerRemove(context.Background(), created.ID, container.RemoveOptions{Force: true})
+		return models.Task{}, errors.Wrap(err, "core.SwarmConnector#StartDebugSidecar: ContainerStart")
+	}
+
+	sidecar := DebugSidecarTask(task, created.ID, debugImage)
+	s.sidecarsMu.Lock()
+	s.sidecars[created.ID] = sidecar
+	s.sidecarsMu.Unlock()
+	return sidecar, nil
+}
+
+// RemoveDebugSidecar implements ClusterBrowser.
+func (s *SwarmConnector) RemoveDebugSidecar(ctx context.Context, sidecar models.Task) error {
+	s.sidecarsMu.Lock()
+	delete(s.sidecars, sidecar.ContainerID)
+	s.sidecarsMu.Unlock()
+
+	cli, err := s.connector.ClientForHost(sidecar.Node.Host)
+	if err != nil {
+		return errors.Wrap(err, "core.SwarmConnector#RemoveDebugSidecar: ClientForHost")
+	}
+	if err := cli.ContainerRemove(ctx, sidecar.ContainerID, container.RemoveOptions{Force: true}); err != nil {
+		return errors.Wrap(err, "core.SwarmConnector#RemoveDebugSidecar: ContainerRemove")
+	}
+	return nil
+}
+
+// removeDebugSidecars removes the sidecars left running, e.g. when closing the browser
+func (s *SwarmConnector) removeDebugSidecars() {
+	s.sidecarsMu.Lock()
+	sidecars := make([]models.Task, 0, len(s.sidecars))
+	for _, sidecar := range s.sidecars {
+		sidecars = append(sidecars, sidecar)
+	}
+	s.sidecarsMu.Unlock()
+	for _, sidecar := range sidecars {
+		if err := s.RemoveDebugSidecar(context.Background(), sidecar); err != nil {
+			log.Printf("core.SwarmConnector#removeDebugSidecars: %v\n", err)
+		}
+	}
+}
+
+// DebugSidecarTask describes the sidecar container debugging a task as a task of its own,
+// so sessions can be opened in it like in any task
+func DebugSidecarTask(task models.Task, containerID, debugImage string) models.Task {
+	return models.Task{
+		TaskID:      "debug-" + task.TaskID,
+		Node:        task.Node,
+		ContainerID: containerID,
+		Status:      task.Status,
+		Image:       debugImage,
+	}
+}
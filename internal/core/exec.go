@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/mendes11/swarm-browser/internal/services/connector"
+	"github.com/pkg/errors"
+)
+
+// maxExecOutput is the amount of stdout and stderr collected from a command
+const maxExecOutput = 1 << 20
+
+// maxParallelExecs limits the commands run at once by ExecInTasks
+const maxParallelExecs = 8
+
+// ShellCommand returns the command running a shell command line, allowing pipes and redirections
+func ShellCommand(commandLine string) []string {
+	return []string{"/bin/sh", "-c", commandLine}
+}
+
+// RunInContainer implements ClusterBrowser.
+//
+// Cancelling ctx closes the connection to the exec, which stops waiting for it. Docker
+// can't signal exec processes, so a command ignoring its closed input keeps running.
+func (s *SwarmConnector) RunInContainer(ctx context.Context, task models.Task, cmd []string, stdin io.Reader) (models.ExecResult, error) {
+	if task.Status != "running" {
+		return models.ExecResult{}, fmt.Errorf("core.SwarmConnector#RunInContainer: task %s is not running (status: %s)", task.TaskID, task.Status)
+	}
+	started := time.Now()
+	conn, err := s.connector.AttachToContainer(ctx, task.Node.Host, task.ContainerID, cmd, false)
+	if err != nil {
+		return models.ExecResult{}, errors.Wrap(err, "core.SwarmConnector#RunInContainer: AttachToContainer")
+	}
+	defer conn.Close()
+
+	// Feed the input, closing it once consumed so the command sees its end
+	input := make(chan error, 1)
+	go func() {
+		input <- feedInput(conn, stdin)
+	}()
+
+	stdout, stderr := &cappedBuffer{}, &cappedBuffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- conn.ReadOutput(stdout, stderr)
+	}()
+	for output := false; !output; {
+		select {
+		case err := <-done:
+			if err != nil {
+				return models.ExecResult{}, errors.Wrap(err, "core.SwarmConnector#RunInContainer: ReadOutput")
+			}
+			output = true
+		case err := <-input:
+			// Without the end of its input, a command reading it would wait until ctx expires
+			if err != nil {
+				conn.Close()
+				return models.ExecResult{}, errors.Wrap(err, "core.SwarmConnector#RunInContainer: the input of the command can't be closed")
+			}
+			input = nil
+		case <-ctx.Done():
+			conn.Close()
+			return models.ExecResult{}, errors.Wrap(ctx.Err(), "core.SwarmConnector#RunInContainer")
+		}
+	}
+
+	exitCode, err := conn.ExitCode(ctx)
+	if err != nil {
+		return models.ExecResult{}, errors.Wrap(err, "core.SwarmConnector#RunInContainer: ExitCode")
+	}
+	return models.ExecResult{
+		Task:      task,
+		Cmd:       cmd,
+		Stdout:    stdout.data,
+		Stderr:    stderr.data,
+		ExitCode:  exitCode,
+		Duration:  time.Since(started),
+		Truncated: stdout.truncated || stderr.truncated,
+	}, nil
+}
+
+// feedInput copies stdin, if any, to the exec and closes its input. Commands may exit before
+// consuming all of it, so failing to write it is only logged.
+func feedInput(conn *connector.ContainerConnection, stdin io.Reader) error {
+	if stdin != nil {
+		if _, err := io.Copy(conn.Conn(), stdin); err != nil {
+			log.Printf("core.feedInput: failed to write the input of the command: %v\n", err)
+			return nil
+		}
+	}
+	return conn.CloseWrite()
+}
+
+// ExecInTasks runs a command in every task in parallel, returning the results in the task order.
+// Failures to run the command are reported in the Err of each result.
+func ExecInTasks(ctx context.Context, browser ClusterBrowser, tasks []models.Task, cmd []string) []models.ExecResult {
+	results := make([]models.ExecResult, len(tasks))
+	limit := make(chan struct{}, maxParallelExecs)
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			result, err := browser.RunInContainer(ctx, task, cmd, nil)
+			if err != nil {
+				result = models.ExecResult{Task: task, Cmd: cmd, Err: err}
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// cappedBuffer keeps the first maxExecOutput bytes written to it
+type cappedBuffer struct {
+	data      []byte
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	room := maxExecOutput - len(b.data)
+	if len(p) > room {
+		b.truncated = true
+		b.data = append(b.data, p[:max(room, 0)]...)
+	} else {
+		b.data = append(b.data, p...)
+	}
+	// Keep consuming the output so the command doesn't block
+	return len(p), nil
+}
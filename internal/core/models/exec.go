@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// ExecResult is the outcome of a non-interactive command run in a task container
+type ExecResult struct {
+	Task     Task
+	Cmd      []string
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+	Duration time.Duration
+	// Truncated is set when the output exceeded the collected size
+	Truncated bool
+	// Err is set when the command could not be run
+	Err error
+}
+
+// Succeeded reports whether the command ran and exited with status 0
+func (r ExecResult) Succeeded() bool {
+	return r.Err == nil && r.ExitCode == 0
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"os"
+	"time"
+)
+
+// FileInfo describes a file stored in a task container
+type FileInfo struct {
+	Name       string
+	Path       string // Absolute path inside the container
+	Size       int64
+	Mode       os.FileMode
+	ModTime    time.Time
+	LinkTarget string
+}
+
+// IsDir reports whether the file is a directory
+func (f FileInfo) IsDir() bool {
+	return f.Mode.IsDir()
+}
@@ -0,0 +1,24 @@
+package models
+
+// Mount represents a filesystem mount of a task container
+type Mount struct {
+	Type        string // Eg: volume, bind, tmpfs
+	Name        string // Volume name, empty for non-volume mounts
+	Source      string
+	Destination string
+	Driver      string
+	Mode        string
+	RW          bool
+	Size        int64 // Size in bytes, -1 when not available
+}
+
+// Volume represents a Docker volume stored in a node
+type Volume struct {
+	Name       string
+	Driver     string
+	Scope      string
+	Mountpoint string
+	Size       int64 // Size in bytes, -1 when not available
+	RefCount   int64 // Number of containers using the volume, -1 when not available
+	Node       Node
+}
@@ -0,0 +1,33 @@
+package models
+
+import "strings"
+
+// Network represents a Docker Swarm network
+type Network struct {
+	ID         string
+	Name       string
+	Driver     string
+	Scope      string
+	Subnets    []string
+	Attachable bool
+	Ingress    bool
+	Internal   bool
+}
+
+func (n Network) String() string {
+	return n.Name
+}
+
+// SubnetsString returns the network subnets joined by commas
+func (n Network) SubnetsString() string {
+	return strings.Join(n.Subnets, ", ")
+}
+
+// NetworkAttachment represents a service or task endpoint attached to a network.
+// Service virtual IPs have an empty TaskID.
+type NetworkAttachment struct {
+	Service Service
+	TaskID  string
+	Node    Node
+	Address string
+}
@@ -6,4 +6,7 @@ type NodeInfo struct {
 	CPUs     int64
 	Memory   int64
 	Platform string // Eg: Architecture - OS
+	// State is ready or down, and Availability active, pause or drain
+	State        string
+	Availability string
 }
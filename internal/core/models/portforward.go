@@ -0,0 +1,24 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// PortForward is an active tunnel from a local port to a port of a task container
+type PortForward struct {
+	Task       Task
+	LocalPort  int
+	RemoteAddr string // Container address, as reachable from its node
+	RemotePort int
+	StartedAt  time.Time
+}
+
+// LocalAddr returns the local address listening for connections
+func (p PortForward) LocalAddr() string {
+	return fmt.Sprintf("127.0.0.1:%d", p.LocalPort)
+}
+
+func (p PortForward) String() string {
+	return fmt.Sprintf("%s -> %s:%d", p.LocalAddr(), p.RemoteAddr, p.RemotePort)
+}
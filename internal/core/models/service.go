@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"time"
 )
 
 type Service struct {
@@ -10,6 +11,18 @@ type Service struct {
 	RunningTasks uint64
 	DesiredTasks uint64
 	Stack        Stack
+	// Image reference the tasks of the service run
+	Image  string
+	Labels map[string]string
+	// Mode is replicated or global, or replicated-job or global-job for jobs
+	Mode string
+	// Ports published by the service, e.g. 8080:80/tcp
+	Ports []string
+	// Placement constraints of the tasks, e.g. node.role==worker
+	Constraints []string
+	// State of the last update of the service, if any, e.g. completed or rollback_completed
+	UpdateState string
+	UpdatedAt   time.Time
 }
 
 func (s Service) String() string {
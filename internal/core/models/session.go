@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// ExecSession is an interactive exec session opened in a task container
+type ExecSession struct {
+	ID          int
+	ContainerID string
+	// The service or task the session was opened on, only one of them is set
+	Service *Service
+	Task    *Task
+	Cmd     []string
+
+	StartedAt  time.Time
+	DetachedAt time.Time
+	// Exited is set once the process running in the container ends
+	Exited bool
+	// Output bytes buffered while detached, replayed when re-attaching
+	Buffered int
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ContainerStats is a resource usage sample of a task container
+type ContainerStats struct {
+	TaskID      string
+	ContainerID string
+	Read        time.Time
+	CPUPercent  float64
+	MemoryUsage uint64
+	MemoryLimit uint64
+	NetRx       uint64
+	NetTx       uint64
+	BlockRead   uint64
+	BlockWrite  uint64
+}
@@ -1,10 +1,29 @@
 package models
 
-import "github.com/moby/moby/api/types/swarm"
+import (
+	"time"
+
+	"github.com/moby/moby/api/types/swarm"
+)
 
 type Task struct {
 	TaskID      string
 	Node        Node
 	ContainerID string
 	Status      swarm.TaskState
+	// DesiredState is the state the orchestrator drives the task to, e.g. shutdown
+	DesiredState swarm.TaskState
+	// Message describes the status, and Err why the task failed, if it did
+	Message string
+	Err     string
+	// ExitCode of the container, once it exited
+	ExitCode int
+	// Image reference of the task container, pinned by digest when the service was deployed with one
+	Image string
+	// CreatedAt is when the task was scheduled
+	CreatedAt time.Time
+	// Labels of the service, along with the ones of the task container overriding them
+	Labels map[string]string
+	// NodeInfo describes the node running the task, when it is known
+	NodeInfo NodeInfo
 }
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/moby/moby/api/types/network"
+	"github.com/moby/moby/api/types/swarm"
+	"github.com/pkg/errors"
+)
+
+// gatewayBridgeNetwork connects swarm containers to their node, so their address in it
+// is reachable from the node itself, unlike overlay addresses.
+const gatewayBridgeNetwork = "docker_gwbridge"
+
+// ParsePortMapping parses a "local:remote" port mapping. A single port is used for both sides.
+func ParsePortMapping(mapping string) (localPort int, remotePort int, err error) {
+	localSpec, remoteSpec, found := strings.Cut(mapping, ":")
+	if !found {
+		remoteSpec = localSpec
+	}
+	if localPort, err = parsePort(localSpec); err != nil {
+		return 0, 0, errors.Wrap(err, "invalid local port")
+	}
+	if remotePort, err = parsePort(remoteSpec); err != nil {
+		return 0, 0, errors.Wrap(err, "invalid remote port")
+	}
+	return localPort, remotePort, nil
+}
+
+func parsePort(spec string) (int, error) {
+	port, err := strconv.Atoi(spec)
+	if err != nil {
+		return 0, errors.Errorf("%q is not a number", spec)
+	}
+	if port < 1 || port > 65535 {
+		return 0, errors.Errorf("%d is out of range", port)
+	}
+	return port, nil
+}
+
+// StartPortForward implements ClusterBrowser.
+//
+// The forward is added to the SSH connection of the task node, targeting the container
+// address in the node gateway bridge.
+func (s *SwarmConnector) StartPortForward(ctx context.Context, task models.Task, localPort int, remotePort int) (models.PortForward, error) {
+	if task.Status != swarm.TaskStateRunning {
+		return models.PortForward{}, fmt.Errorf("connector.SwarmConnector#StartPortForward: task %s is not running (status: %s)", task.TaskID, task.Status)
+	}
+	addr, err := s.containerAddr(ctx, task)
+	if err != nil {
+		return models.PortForward{}, errors.Wrap(err, "connector.SwarmConnector#StartPortForward: containerAddr")
+	}
+	forward := models.PortForward{
+		Task:       task,
+		LocalPort:  localPort,
+		RemoteAddr: addr,
+		RemotePort: remotePort,
+		StartedAt:  time.Now(),
+	}
+
+	s.forwardsMu.Lock()
+	defer s.forwardsMu.Unlock()
+	if _, exists := s.forwards[localPort]; exists {
+		return models.PortForward{}, fmt.Errorf("connector.SwarmConnector#StartPortForward: local port %d is already forwarded", localPort)
+	}
+	remote := net.JoinHostPort(addr, strconv.Itoa(remotePort))
+	if err := s.connector.ForwardPort(task.Node.Host, forward.LocalAddr(), remote); err != nil {
+		return models.PortForward{}, errors.Wrap(err, "connector.SwarmConnector#StartPortForward: ForwardPort")
+	}
+	s.forwards[localPort] = forward
+	return forward, nil
+}
+
+// StopPortForward implements ClusterBrowser.
+func (s *SwarmConnector) StopPortForward(ctx context.Context, forward models.PortForward) error {
+	s.forwardsMu.Lock()
+	defer s.forwardsMu.Unlock()
+	if _, exists := s.forwards[forward.LocalPort]; !exists {
+		return fmt.Errorf("connector.SwarmConnector#StopPortForward: local port %d is not forwarded", forward.LocalPort)
+	}
+	remote := net.JoinHostPort(forward.RemoteAddr, strconv.Itoa(forward.RemotePort))
+	if err := s.connector.CancelForward(forward.Task.Node.Host, forward.LocalAddr(), remote); err != nil {
+		return errors.Wrap(err, "connector.SwarmConnector#StopPortForward: CancelForward")
+	}
+	delete(s.forwards, forward.LocalPort)
+	return nil
+}
+
+// ListPortForwards implements ClusterBrowser.
+func (s *SwarmConnector) ListPortForwards() []models.PortForward {
+	s.forwardsMu.Lock()
+	defer s.forwardsMu.Unlock()
+	return sortedPortForwards(s.forwards)
+}
+
+// containerAddr returns the container IP address reachable from its node
+func (s *SwarmConnector) containerAddr(ctx context.Context, task models.Task) (string, error) {
+	cli, err := s.taskClient(task)
+	if err != nil {
+		return "", errors.Wrap(err, "taskClient")
+	}
+	gwBridge, err := cli.NetworkInspect(ctx, gatewayBridgeNetwork, network.InspectOptions{})
+	if err == nil {
+		if endpoint, exists := gwBridge.Containers[task.ContainerID]; exists && endpoint.IPv4Address != "" {
+			ip, _, _ := strings.Cut(endpoint.IPv4Address, "/")
+			return ip, nil
+		}
+	}
+	// Containers outside of swarm overlay networks (e.g. host bridge) are reachable through their own address
+	containerInfo, err := cli.ContainerInspect(ctx, task.ContainerID)
+	if err != nil {
+		return "", errors.Wrap(err, "ContainerInspect")
+	}
+	if containerInfo.NetworkSettings != nil {
+		for _, endpoint := range containerInfo.NetworkSettings.Networks {
+			if endpoint != nil && endpoint.IPAddress != "" {
+				return endpoint.IPAddress, nil
+			}
+		}
+	}
+	return "", errors.Errorf("container %s has no address reachable from node %s", task.ContainerID, task.Node.Hostname)
+}
+
+// sortedPortForwards returns the forwards ordered by local port
+func sortedPortForwards(forwards map[int]models.PortForward) []models.PortForward {
+	list := make([]models.PortForward, 0, len(forwards))
+	for _, forward := range forwards {
+		list = append(list, forward)
+	}
+	slices.SortFunc(list, func(a, b models.PortForward) int {
+		return a.LocalPort - b.LocalPort
+	})
+	return list
+}
@@ -0,0 +1,34 @@
+package core
+
+import "testing"
+
+func TestParsePortMapping(t *testing.T) {
+	tests := []struct {
+		mapping    string
+		localPort  int
+		remotePort int
+		wantErr    bool
+	}{
+		{mapping: "8080:80", localPort: 8080, remotePort: 80},
+		{mapping: "5432", localPort: 5432, remotePort: 5432},
+		{mapping: "http:80", wantErr: true},
+		{mapping: "8080:", wantErr: true},
+		{mapping: "70000:80", wantErr: true},
+	}
+	for _, tt := range tests {
+		localPort, remotePort, err := ParsePortMapping(tt.mapping)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParsePortMapping(%q): expected error", tt.mapping)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePortMapping(%q) failed: %v", tt.mapping, err)
+			continue
+		}
+		if localPort != tt.localPort || remotePort != tt.remotePort {
+			t.Errorf("ParsePortMapping(%q) = %d:%d, want %d:%d", tt.mapping, localPort, remotePort, tt.localPort, tt.remotePort)
+		}
+	}
+}
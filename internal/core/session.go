@@ -0,0 +1,384 @@
+package core
+
+import (
+	"context"
+	"io"
+	"log"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/pkg/errors"
+)
+
+// maxSessionBuffer is the amount of output kept per session, replayed when re-attaching
+const maxSessionBuffer = 1 << 20
+
+// ErrSessionAttached is returned when re-attaching to a session that is being displayed
+var ErrSessionAttached = errors.New("session is already attached")
+
+// sessionName prefixes the tmux / screen sessions joined by the session command, numbered
+// from 1 (e.g. swarm-browser-1)
+const sessionName = "swarm-browser"
+
+// Multiplexers lists the accepted session multiplexer settings, "auto" using tmux or screen
+var Multiplexers = []string{"tmux", "screen", "auto"}
+
+// SessionCommand returns the command of an exec session running a shell inside
+// the multiplexer, when the image provides it, so the session survives the exec
+// ending. It returns nil without a multiplexer.
+//
+// Each exec session joins the first multiplexer session no client is attached to, creating it
+// if needed: sessions opened at the same time get their own shell, while reconnecting after a
+// restart resumes the sessions left detached.
+func SessionCommand(multiplexer string) []string {
+	script := ""
+	if multiplexer == "tmux" || multiplexer == "auto" {
+		script += "command -v tmux >/dev/null 2>&1 && {\n" +
+			"attached=$(tmux list-sessions -F '#{session_name} #{session_attached}' 2>/dev/null | grep -v ' 0$')\n" +
+			"n=1; while echo \"$attached\" | grep -qx \"" + sessionName + "-$n [0-9]*\"; do n=$((n+1)); done\n" +
+			"exec tmux new-session -A -s " + sessionName + "-$n\n}\n"
+	}
+	if multiplexer == "screen" || multiplexer == "auto" {
+		script += "command -v screen >/dev/null 2>&1 && {\n" +
+			"attached=$(screen -ls 2>/dev/null | grep '(Attached)')\n" +
+			"n=1; while echo \"$attached\" | grep -q \"[.]" + sessionName + "-$n[[:space:]]\"; do n=$((n+1)); done\n" +
+			"exec screen -D -R -S " + sessionName + "-$n\n}\n"
+	}
+	if script == "" {
+		return nil
+	}
+	// Fall back to a plain shell
+	script += "command -v bash >/dev/null 2>&1 && exec bash\nexec sh"
+	return []string{"/bin/sh", "-c", script}
+}
+
+// SessionRecorder records the terminal activity of an exec session
+type SessionRecorder interface {
+	Output(data []byte) error
+	Input(data []byte) error
+	Resize(width, height uint) error
+	Close() error
+}
+
+// RecordSession records the output of the session of an attached connection from its
+// start, along with its input when input is set. The recorder is closed with the session.
+func RecordSession(conn ContainerConnection, recorder SessionRecorder, input bool) error {
+	attached, ok := conn.(*sessionConn)
+	if !ok {
+		return errors.New("core.RecordSession: not an exec session connection")
+	}
+	session := attached.session
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.recorder != nil {
+		return errors.Errorf("core.RecordSession: session %d is already recorded", session.info.ID)
+	}
+	if session.err != nil {
+		return errors.Wrapf(session.err, "core.RecordSession: session %d ended", session.info.ID)
+	}
+	// Catch up with the output read since the session was opened
+	if err := recorder.Output(session.buffer); err != nil {
+		return errors.Wrap(err, "core.RecordSession: Output")
+	}
+	session.recorder = recorder
+	session.recordInput = input
+	return nil
+}
+
+// ExecSessions keeps the exec sessions opened in a cluster, so they can be detached from
+// and re-attached to without ending the process running in the container.
+//
+// The output of every session is read continuously into a bounded buffer, which is
+// replayed to the connection returned when re-attaching.
+type ExecSessions struct {
+	mu       sync.Mutex
+	sessions map[int]*execSession
+	nextID   int
+}
+
+// NewExecSessions creates an empty session registry
+func NewExecSessions() *ExecSessions {
+	return &ExecSessions{
+		sessions: make(map[int]*execSession),
+		nextID:   1,
+	}
+}
+
+// Open starts buffering the output of an attached connection, returning the attached
+// connection to the session, which replaces conn.
+func (s *ExecSessions) Open(conn ContainerConnection, info models.ExecSession) ContainerConnection {
+	s.mu.Lock()
+	info.ID = s.nextID
+	s.nextID++
+	info.ContainerID = conn.ContainerID()
+	info.StartedAt = time.Now()
+	session := &execSession{info: info, conn: conn, sessions: s}
+	session.cond = sync.NewCond(&session.mu)
+	s.sessions[info.ID] = session
+	s.mu.Unlock()
+
+	go session.pump()
+	return session.attach(0)
+}
+
+// Detach leaves the session of an attached connection running in background
+func (s *ExecSessions) Detach(conn ContainerConnection) (models.ExecSession, error) {
+	attached, ok := conn.(*sessionConn)
+	if !ok {
+		return models.ExecSession{}, errors.New("core.ExecSessions#Detach: not an exec session connection")
+	}
+	session := attached.session
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.attached != attached {
+		return models.ExecSession{}, errors.Errorf("core.ExecSessions#Detach: session %d is not attached", session.info.ID)
+	}
+	session.attached = nil
+	session.info.DetachedAt = time.Now()
+	session.detachedAt = session.written
+	// Wake up the reader of the detached connection
+	attached.detached = true
+	session.cond.Broadcast()
+	return session.infoLocked(), nil
+}
+
+// Reattach returns a new connection to a detached session, starting with its buffered output
+func (s *ExecSessions) Reattach(id int) (ContainerConnection, error) {
+	s.mu.Lock()
+	session, exists := s.sessions[id]
+	s.mu.Unlock()
+	if !exists {
+		return nil, errors.Errorf("core.ExecSessions#Reattach: session %d not found", id)
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.attached != nil {
+		return nil, errors.Wrapf(ErrSessionAttached, "core.ExecSessions#Reattach: session %d", id)
+	}
+	session.info.DetachedAt = time.Time{}
+	return session.attachLocked(session.start()), nil
+}
+
+// List returns the detached sessions, oldest first
+func (s *ExecSessions) List() []models.ExecSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	detached := make([]models.ExecSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		session.mu.Lock()
+		if session.attached == nil {
+			detached = append(detached, session.infoLocked())
+		}
+		session.mu.Unlock()
+	}
+	slices.SortFunc(detached, func(a, b models.ExecSession) int {
+		return a.ID - b.ID
+	})
+	return detached
+}
+
+// Kill ends a session, closing its exec connection
+func (s *ExecSessions) Kill(id int) error {
+	session := s.remove(id)
+	if session == nil {
+		return errors.Errorf("core.ExecSessions#Kill: session %d not found", id)
+	}
+	if err := session.conn.Close(); err != nil {
+		return errors.Wrap(err, "core.ExecSessions#Kill: Close")
+	}
+	return nil
+}
+
+func (s *ExecSessions) remove(id int) *execSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.sessions[id]
+	delete(s.sessions, id)
+	return session
+}
+
+// CloseAll ends every session
+func (s *ExecSessions) CloseAll() {
+	s.mu.Lock()
+	sessions := s.sessions
+	s.sessions = make(map[int]*execSession)
+	s.mu.Unlock()
+	for _, session := range sessions {
+		session.conn.Close()
+	}
+}
+
+// execSession buffers the output of an exec connection
+type execSession struct {
+	conn     ContainerConnection
+	sessions *ExecSessions
+
+	mu   sync.Mutex
+	cond *sync.Cond // Signalled on new output, exit and detach
+	info models.ExecSession
+	// Output buffer, holding the output from offset written-len(buffer) to written
+	buffer     []byte
+	written    int64
+	detachedAt int64
+	err        error
+	// The connection displaying the session, nil while detached
+	attached *sessionConn
+
+	recorder    SessionRecorder
+	recordInput bool
+}
+
+// pump reads the exec output until the process ends
+func (s *execSession) pump() {
+	chunk := make([]byte, 32*1024)
+	for {
+		n, err := s.conn.Reader().Read(chunk)
+		s.mu.Lock()
+		if n > 0 {
+			s.buffer = append(s.buffer, chunk[:n]...)
+			s.written += int64(n)
+			// Trim the buffer once it doubles, so the copy is amortized
+			if len(s.buffer) > 2*maxSessionBuffer {
+				s.buffer = slices.Clone(s.buffer[len(s.buffer)-maxSessionBuffer:])
+			}
+			s.record(SessionRecorder.Output, chunk[:n])
+		}
+		if err != nil {
+			s.err = err
+			s.info.Exited = true
+			if s.recorder != nil {
+				s.recorder.Close()
+				s.recorder = nil
+			}
+		}
+		s.cond.Broadcast()
+		s.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// record passes session activity to the recorder, if any. The caller holds s.mu.
+func (s *execSession) record(event func(SessionRecorder, []byte) error, data []byte) {
+	if s.recorder == nil {
+		return
+	}
+	if err := event(s.recorder, data); err != nil {
+		log.Printf("core.execSession#record: session %d: %v\n", s.info.ID, err)
+	}
+}
+
+// start returns the offset of the oldest buffered output
+func (s *execSession) start() int64 {
+	return s.written - int64(len(s.buffer))
+}
+
+func (s *execSession) attach(offset int64) *sessionConn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attachLocked(offset)
+}
+
+func (s *execSession) attachLocked(offset int64) *sessionConn {
+	s.attached = &sessionConn{session: s, offset: offset}
+	return s.attached
+}
+
+func (s *execSession) infoLocked() models.ExecSession {
+	info := s.info
+	if s.attached == nil {
+		info.Buffered = int(s.written - max(s.detachedAt, s.start()))
+	}
+	return info
+}
+
+// sessionConn is an attached connection to an exec session, reading its buffered output
+type sessionConn struct {
+	session  *execSession
+	offset   int64
+	detached bool
+}
+
+// Ensure it conforms to the interface
+var _ ContainerConnection = &sessionConn{}
+
+// read returns the session output from the connection offset, waiting for new output
+func (c *sessionConn) read(p []byte) (int, error) {
+	s := c.session
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for c.offset == s.written && s.err == nil && !c.detached {
+		s.cond.Wait()
+	}
+	if c.detached {
+		return 0, io.EOF
+	}
+	if c.offset == s.written {
+		return 0, s.err
+	}
+	// Skip the output dropped from the buffer
+	c.offset = max(c.offset, s.start())
+	n := copy(p, s.buffer[c.offset-s.start():])
+	c.offset += int64(n)
+	return n, nil
+}
+
+// ResizeTTY implements ContainerConnection.
+func (c *sessionConn) ResizeTTY(ctx context.Context, width, height uint) error {
+	s := c.session
+	s.mu.Lock()
+	s.record(func(recorder SessionRecorder, _ []byte) error {
+		return recorder.Resize(width, height)
+	}, nil)
+	s.mu.Unlock()
+	return s.conn.ResizeTTY(ctx, width, height)
+}
+
+// ContainerID implements ContainerConnection.
+func (c *sessionConn) ContainerID() string {
+	return c.session.conn.ContainerID()
+}
+
+// Conn returns the session stream: reads return the buffered output, writes go to the exec
+func (c *sessionConn) Conn() net.Conn {
+	return sessionStream{Conn: c.session.conn.Conn(), attached: c}
+}
+
+// Reader implements ContainerConnection, returning the buffered output of the session
+func (c *sessionConn) Reader() io.Reader {
+	return c.Conn()
+}
+
+// Close ends the session. Use ExecSessions.Detach to leave it running instead.
+func (c *sessionConn) Close() error {
+	return c.session.sessions.Kill(c.session.info.ID)
+}
+
+// sessionStream is the net.Conn of an attached session connection
+type sessionStream struct {
+	net.Conn
+	attached *sessionConn
+}
+
+func (s sessionStream) Read(p []byte) (int, error) {
+	return s.attached.read(p)
+}
+
+func (s sessionStream) Write(p []byte) (int, error) {
+	n, err := s.Conn.Write(p)
+	session := s.attached.session
+	session.mu.Lock()
+	if session.recordInput && n > 0 {
+		session.record(SessionRecorder.Input, p[:n])
+	}
+	session.mu.Unlock()
+	return n, err
+}
+
+func (s sessionStream) Close() error {
+	return s.attached.Close()
+}
@@ -0,0 +1,228 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+// pipeConnection is a ContainerConnection over one end of a net.Pipe
+type pipeConnection struct {
+	conn net.Conn
+	// reader replaces conn as the output, e.g. to hold output read along with the attach response
+	reader io.Reader
+}
+
+func (p *pipeConnection) ResizeTTY(ctx context.Context, width, height uint) error { return nil }
+func (p *pipeConnection) ContainerID() string                                     { return "container-1" }
+func (p *pipeConnection) Conn() net.Conn                                          { return p.conn }
+func (p *pipeConnection) Reader() io.Reader {
+	if p.reader != nil {
+		return p.reader
+	}
+	return p.conn
+}
+func (p *pipeConnection) Close() error { return p.conn.Close() }
+
+func readString(t *testing.T, conn ContainerConnection, want string) {
+	t.Helper()
+	data := make([]byte, 0, len(want))
+	buffer := make([]byte, 64)
+	for len(data) < len(want) {
+		n, err := conn.Reader().Read(buffer)
+		if err != nil {
+			t.Fatalf("Read failed after %q: %v", data, err)
+		}
+		data = append(data, buffer[:n]...)
+	}
+	if string(data) != want {
+		t.Fatalf("Expected %q, got %q", want, data)
+	}
+}
+
+func TestExecSessionsReadBufferedOutput(t *testing.T) {
+	client, server := net.Pipe()
+	sessions := NewExecSessions()
+	// The first output was buffered along with the attach response
+	reader := io.MultiReader(strings.NewReader("welcome\r\n"), client)
+	conn := sessions.Open(&pipeConnection{conn: client, reader: reader}, models.ExecSession{Cmd: []string{"sh"}})
+
+	server.Write([]byte("$ "))
+	readString(t, conn, "welcome\r\n$ ")
+	conn.Close()
+}
+
+func TestExecSessionsDetachAndReattach(t *testing.T) {
+	client, server := net.Pipe()
+	sessions := NewExecSessions()
+	conn := sessions.Open(&pipeConnection{conn: client}, models.ExecSession{Cmd: []string{"sh"}})
+
+	server.Write([]byte("$ "))
+	readString(t, conn, "$ ")
+
+	// Input goes through to the exec
+	go conn.Conn().Write([]byte("ls\n"))
+	input := make([]byte, 3)
+	if _, err := io.ReadFull(server, input); err != nil || string(input) != "ls\n" {
+		t.Fatalf("Expected input 'ls\\n', got %q (%v)", input, err)
+	}
+
+	if len(sessions.List()) != 0 {
+		t.Error("Expected no detached sessions while attached")
+	}
+	info, err := sessions.Detach(conn)
+	if err != nil {
+		t.Fatalf("Detach failed: %v", err)
+	}
+	if _, err := conn.Reader().Read(make([]byte, 8)); err != io.EOF {
+		t.Errorf("Expected EOF reading a detached connection, got %v", err)
+	}
+
+	// The exec keeps running, and its output is buffered
+	server.Write([]byte("a b\r\n$ "))
+	deadline := time.Now().Add(time.Second)
+	for sessions.List()[0].Buffered != 7 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	detached := sessions.List()
+	if len(detached) != 1 || detached[0].ID != info.ID || detached[0].Buffered != 7 {
+		t.Fatalf("Expected session %d with 7 buffered bytes, got %+v", info.ID, detached)
+	}
+
+	reattached, err := sessions.Reattach(info.ID)
+	if err != nil {
+		t.Fatalf("Reattach failed: %v", err)
+	}
+	readString(t, reattached, "$ a b\r\n$ ")
+	if _, err := sessions.Reattach(info.ID); err == nil {
+		t.Error("Expected an error re-attaching an attached session")
+	}
+
+	// Closing the connection ends the session
+	reattached.Close()
+	if _, err := server.Write([]byte("x")); err == nil {
+		t.Error("Expected the exec connection to be closed")
+	}
+	if _, err := sessions.Reattach(info.ID); err == nil {
+		t.Error("Expected an error re-attaching a closed session")
+	}
+}
+
+func TestSessionCommand(t *testing.T) {
+	if cmd := SessionCommand(""); cmd != nil {
+		t.Errorf("Expected no command without a multiplexer, got %v", cmd)
+	}
+	cmd := SessionCommand("tmux")
+	if len(cmd) != 3 || !strings.Contains(cmd[2], "exec tmux new-session -A -s swarm-browser-$n") || strings.Contains(cmd[2], "screen") {
+		t.Errorf("Expected a tmux session script, got %q", cmd)
+	}
+}
+
+// fakeMultiplexers are tmux and screen commands listing the sessions in the SESSIONS variable,
+// and printing the session they would join
+var fakeMultiplexers = map[string]string{
+	"tmux": `case "$1" in
+list-sessions) printf "$SESSIONS" ;;
+new-session) echo "$4" ;;
+esac
+`,
+	"screen": `case "$1" in
+-ls) printf "$SESSIONS" ;;
+-D) echo "$4" ;;
+esac
+`,
+}
+
+func TestSessionCommandConcurrentSessions(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no shell to run the session command")
+	}
+	tests := []struct {
+		multiplexer string
+		sessions    string
+		want        string
+	}{
+		{"tmux", "", "swarm-browser-1"},
+		// A second session opened while the first one is attached gets its own shell
+		{"tmux", `swarm-browser-1 1\n`, "swarm-browser-2"},
+		{"tmux", `swarm-browser-1 1\nswarm-browser-2 2\nswarm-browser-10 1\n`, "swarm-browser-3"},
+		// Detached sessions are resumed, e.g. after a restart
+		{"tmux", `swarm-browser-1 0\nswarm-browser-2 1\n`, "swarm-browser-1"},
+		{"screen", "", "swarm-browser-1"},
+		{"screen", `\t123.swarm-browser-1\t(Attached)\n\t456.swarm-browser-2\t(Detached)\n`, "swarm-browser-2"},
+	}
+	for _, test := range tests {
+		binDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(binDir, test.multiplexer), []byte("#!/bin/sh\n"+fakeMultiplexers[test.multiplexer]), 0755); err != nil {
+			t.Fatalf("Failed to create the fake %s: %v", test.multiplexer, err)
+		}
+		cmd := SessionCommand(test.multiplexer)
+		run := exec.Command(cmd[0], cmd[1:]...)
+		run.Env = append(os.Environ(), "PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"), "SESSIONS="+test.sessions)
+		output, err := run.Output()
+		if err != nil {
+			t.Fatalf("%s session command failed: %v", test.multiplexer, err)
+		}
+		if got := strings.TrimSpace(string(output)); got != test.want {
+			t.Errorf("Expected %s to join %s with the sessions %q, got %s", test.multiplexer, test.want, test.sessions, got)
+		}
+	}
+}
+
+// memoryRecorder keeps the recorded activity
+type memoryRecorder struct {
+	output, input []byte
+	sizes         []uint
+	closed        chan struct{}
+}
+
+func (r *memoryRecorder) Output(data []byte) error { r.output = append(r.output, data...); return nil }
+func (r *memoryRecorder) Input(data []byte) error  { r.input = append(r.input, data...); return nil }
+func (r *memoryRecorder) Resize(width, height uint) error {
+	r.sizes = append(r.sizes, width, height)
+	return nil
+}
+func (r *memoryRecorder) Close() error { close(r.closed); return nil }
+
+func TestRecordSession(t *testing.T) {
+	client, server := net.Pipe()
+	sessions := NewExecSessions()
+	conn := sessions.Open(&pipeConnection{conn: client}, models.ExecSession{})
+	server.Write([]byte("banner\r\n"))
+	readString(t, conn, "banner\r\n")
+
+	recorder := &memoryRecorder{closed: make(chan struct{})}
+	if err := RecordSession(conn, recorder, true); err != nil {
+		t.Fatalf("RecordSession failed: %v", err)
+	}
+	conn.ResizeTTY(context.Background(), 100, 30)
+	go io.ReadFull(server, make([]byte, 3))
+	conn.Conn().Write([]byte("ls\r"))
+	server.Write([]byte("a b\r\n"))
+	readString(t, conn, "a b\r\n")
+	conn.Close()
+	select {
+	case <-recorder.closed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the recorder to be closed with the session")
+	}
+
+	// The output read before recording started is recorded too
+	if string(recorder.output) != "banner\r\na b\r\n" {
+		t.Errorf("Unexpected recorded output %q", recorder.output)
+	}
+	if string(recorder.input) != "ls\r" {
+		t.Errorf("Unexpected recorded input %q", recorder.input)
+	}
+	if len(recorder.sizes) != 2 || recorder.sizes[0] != 100 || recorder.sizes[1] != 30 {
+		t.Errorf("Unexpected recorded sizes %v", recorder.sizes)
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/pkg/errors"
+)
+
+// Shells lists the shells looked for in containers, by preference
+var Shells = []string{"bash", "ash", "zsh", "sh"}
+
+// ErrNoShell is returned when a container provides no shell, as with distroless images
+var ErrNoShell = errors.New("no shell found in the container")
+
+// shellProbeCommand prints the path of the first shell found in the container
+var shellProbeCommand = ShellCommand(`for shell in ` + strings.Join(Shells, " ") + `; do command -v "$shell" && exit 0; done; exit 1`)
+
+// ShellProbe detects the shell of task containers with a non-interactive exec, before
+// opening a session. Results are cached by image digest, since containers of the same
+// image provide the same shells.
+type ShellProbe struct {
+	mu     sync.Mutex
+	shells map[string]string // image digest -> shell path, empty when there is none
+}
+
+func NewShellProbe() *ShellProbe {
+	return &ShellProbe{shells: make(map[string]string)}
+}
+
+// Detect returns the path of the preferred shell of the task container, or ErrNoShell
+func (p *ShellProbe) Detect(ctx context.Context, browser ClusterBrowser, task models.Task) (string, error) {
+	digest := ImageDigest(task.Image)
+	if digest != "" {
+		p.mu.Lock()
+		shell, found := p.shells[digest]
+		p.mu.Unlock()
+		if found {
+			return shellOrError(shell)
+		}
+	}
+
+	result, err := browser.RunInContainer(ctx, task, shellProbeCommand, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "core.ShellProbe#Detect: RunInContainer")
+	}
+	shell := ""
+	// Exit codes 126 and 127 mean /bin/sh itself couldn't be run
+	if result.ExitCode == 0 {
+		shell = strings.TrimSpace(strings.SplitN(string(result.Stdout), "\n", 2)[0])
+	}
+
+	if digest != "" {
+		p.mu.Lock()
+		p.shells[digest] = shell
+		p.mu.Unlock()
+	}
+	return shellOrError(shell)
+}
+
+func shellOrError(shell string) (string, error) {
+	if shell == "" {
+		return "", ErrNoShell
+	}
+	return shell, nil
+}
+
+// ImageDigest returns the digest of an image reference pinned by digest
+// (e.g. nginx:1.27@sha256:...), or an empty string
+func ImageDigest(image string) string {
+	_, digest, found := strings.Cut(image, "@")
+	if !found {
+		return ""
+	}
+	return digest
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+// probedBrowser answers the shell probe with a fixed result, counting the probes
+type probedBrowser struct {
+	ClusterBrowser
+	result models.ExecResult
+	probes int
+}
+
+func (b *probedBrowser) RunInContainer(ctx context.Context, task models.Task, cmd []string, stdin io.Reader) (models.ExecResult, error) {
+	b.probes++
+	return b.result, nil
+}
+
+func TestShellProbe(t *testing.T) {
+	ctx := context.Background()
+	pinned := models.Task{TaskID: "task-1", Image: "alpine:3.20@sha256:abc"}
+
+	t.Run("DetectsAndCaches", func(t *testing.T) {
+		browser := &probedBrowser{result: models.ExecResult{Stdout: []byte("/bin/ash\n")}}
+		probe := NewShellProbe()
+		for range 2 {
+			shell, err := probe.Detect(ctx, browser, pinned)
+			if err != nil || shell != "/bin/ash" {
+				t.Fatalf("Expected /bin/ash, got %q (%v)", shell, err)
+			}
+		}
+		if browser.probes != 1 {
+			t.Errorf("Expected the shell to be probed once per digest, got %d probes", browser.probes)
+		}
+
+		// Images without a digest may change, so they are probed every time
+		unpinned := models.Task{TaskID: "task-2", Image: "alpine:latest"}
+		probe.Detect(ctx, browser, unpinned)
+		probe.Detect(ctx, browser, unpinned)
+		if browser.probes != 3 {
+			t.Errorf("Expected unpinned images to be probed each time, got %d probes", browser.probes)
+		}
+	})
+
+	t.Run("NoShell", func(t *testing.T) {
+		browser := &probedBrowser{result: models.ExecResult{
+			Stdout:   []byte(`exec: "/bin/sh": stat /bin/sh: no such file or directory`),
+			ExitCode: 127,
+		}}
+		probe := NewShellProbe()
+		for range 2 {
+			if _, err := probe.Detect(ctx, browser, pinned); !errors.Is(err, ErrNoShell) {
+				t.Fatalf("Expected ErrNoShell, got %v", err)
+			}
+		}
+		if browser.probes != 1 {
+			t.Errorf("Expected missing shells to be cached, got %d probes", browser.probes)
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/moby/moby/api/types/container"
+	"github.com/moby/moby/api/types/swarm"
+	"github.com/moby/moby/client"
+	"github.com/pkg/errors"
+)
+
+// StreamTaskStats implements ClusterBrowser.
+//
+// A stats stream is opened concurrently for every running task, using the Docker client
+// of the node it runs on. The returned channel is closed once ctx is cancelled and
+// every stream has finished.
+func (s *SwarmConnector) StreamTaskStats(ctx context.Context, tasks []models.Task) (<-chan models.ContainerStats, error) {
+	type taskClient struct {
+		task models.Task
+		cli  *client.Client
+	}
+	// Resolve all clients before starting any stream, so a failure doesn't leak goroutines
+	targets := make([]taskClient, 0, len(tasks))
+	for _, task := range tasks {
+		if task.Status != swarm.TaskStateRunning || task.ContainerID == "" {
+			continue
+		}
+		cli, err := s.connector.ClientForHost(task.Node.Host)
+		if err != nil {
+			return nil, errors.Wrap(err, "connector.SwarmConnector#StreamTaskStats: ClientForHost")
+		}
+		targets = append(targets, taskClient{task: task, cli: cli})
+	}
+
+	statsCh := make(chan models.ContainerStats)
+	var wg sync.WaitGroup
+	for _, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := streamContainerStats(ctx, target.cli, target.task, statsCh); err != nil && ctx.Err() == nil {
+				log.Printf("connector.SwarmConnector#StreamTaskStats: task %s: %v\n", target.task.TaskID, err)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(statsCh)
+	}()
+	return statsCh, nil
+}
+
+func streamContainerStats(ctx context.Context, cli *client.Client, task models.Task, statsCh chan<- models.ContainerStats) error {
+	resp, err := cli.ContainerStats(ctx, task.ContainerID, true)
+	if err != nil {
+		return errors.Wrap(err, "streamContainerStats: ContainerStats")
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var statsResp container.StatsResponse
+		if err := decoder.Decode(&statsResp); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return errors.Wrap(err, "streamContainerStats: Decode")
+		}
+		stats := statsFromResponse(statsResp)
+		stats.TaskID = task.TaskID
+		stats.ContainerID = task.ContainerID
+		select {
+		case statsCh <- stats:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// statsFromResponse converts a Docker stats sample, computing usage the same way `docker stats` does
+func statsFromResponse(resp container.StatsResponse) models.ContainerStats {
+	stats := models.ContainerStats{
+		Read:        resp.Read,
+		MemoryLimit: resp.MemoryStats.Limit,
+	}
+
+	cpuDelta := float64(resp.CPUStats.CPUUsage.TotalUsage) - float64(resp.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(resp.CPUStats.SystemUsage) - float64(resp.PreCPUStats.SystemUsage)
+	onlineCPUs := float64(resp.CPUStats.OnlineCPUs)
+	if onlineCPUs == 0 {
+		onlineCPUs = float64(len(resp.CPUStats.CPUUsage.PercpuUsage))
+	}
+	if cpuDelta > 0 && systemDelta > 0 {
+		stats.CPUPercent = cpuDelta / systemDelta * onlineCPUs * 100
+	}
+
+	// Page cache is reclaimable, so it's not accounted as used memory
+	stats.MemoryUsage = resp.MemoryStats.Usage
+	cache := resp.MemoryStats.Stats["inactive_file"]
+	if cache == 0 {
+		cache = resp.MemoryStats.Stats["total_inactive_file"]
+	}
+	if cache < stats.MemoryUsage {
+		stats.MemoryUsage -= cache
+	}
+
+	for _, network := range resp.Networks {
+		stats.NetRx += network.RxBytes
+		stats.NetTx += network.TxBytes
+	}
+	for _, entry := range resp.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			stats.BlockRead += entry.Value
+		case "write":
+			stats.BlockWrite += entry.Value
+		}
+	}
+	return stats
+}
@@ -0,0 +1,59 @@
+package query
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+// The fields of the stacks, services and tasks, as named in queries
+var (
+	StackFields   = []string{"name"}
+	ServiceFields = []string{"id", "name", "stack", "replicas", "desired", "image", "label"}
+	TaskFields    = []string{"id", "container", "status", "node", "host", "image", "label", "age"}
+)
+
+func StackItem(stack models.Stack) Item {
+	return Item{
+		Fields: map[string]string{"name": stack.Name},
+		Text:   []string{stack.Name},
+	}
+}
+
+// ServiceItem describes a service, replicas being the number of its running tasks
+func ServiceItem(service models.Service) Item {
+	return Item{
+		Fields: map[string]string{
+			"id":       service.ID,
+			"name":     service.Name,
+			"stack":    service.Stack.Name,
+			"replicas": strconv.FormatUint(service.RunningTasks, 10),
+			"desired":  strconv.FormatUint(service.DesiredTasks, 10),
+			"image":    service.Image,
+		},
+		Labels: service.Labels,
+		Text:   []string{service.Name, service.ID},
+	}
+}
+
+// TaskItem describes a task, its age being in seconds
+func TaskItem(task models.Task) Item {
+	age := ""
+	if !task.CreatedAt.IsZero() {
+		age = strconv.FormatFloat(time.Since(task.CreatedAt).Seconds(), 'f', 0, 64)
+	}
+	return Item{
+		Fields: map[string]string{
+			"id":        task.TaskID,
+			"container": task.ContainerID,
+			"status":    string(task.Status),
+			"node":      task.Node.Hostname,
+			"host":      task.Node.Host,
+			"image":     task.Image,
+			"age":       age,
+		},
+		Labels: task.Labels,
+		Text:   []string{task.TaskID, task.ContainerID, string(task.Status), task.Node.Host, task.Node.Hostname},
+	}
+}
@@ -0,0 +1,356 @@
+package query
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	wordToken tokenKind = iota
+	openToken
+	closeToken
+	andToken
+	orToken
+	notToken
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// quoted words are always matched as text, e.g. "http://host"
+	quoted bool
+}
+
+// lex splits an expression into words, parentheses and operators. Quotes group the text of
+// a word, e.g. image:"my registry/app" or "a phrase".
+func lex(expr string) ([]token, error) {
+	var tokens []token
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, token{kind: openToken, text: "("})
+			i++
+			continue
+		case r == ')':
+			tokens = append(tokens, token{kind: closeToken, text: ")"})
+			i++
+			continue
+		case r == '|':
+			i++
+			if i < len(runes) && runes[i] == '|' {
+				i++
+			}
+			tokens = append(tokens, token{kind: orToken, text: "or"})
+			continue
+		case r == '&' && i+1 < len(runes) && runes[i+1] == '&':
+			tokens = append(tokens, token{kind: andToken, text: "and"})
+			i += 2
+			continue
+		case (r == '-' || r == '!') && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != '=':
+			tokens = append(tokens, token{kind: notToken, text: string(r)})
+			i++
+			continue
+		}
+
+		// A word, up to a space outside of quotes or a parenthesis closing a group. Parentheses
+		// opened in the word belong to it, e.g. image:~(redis|memcached).
+		var sb strings.Builder
+		quoted, unquoted := false, false
+		depth := 0
+		for i < len(runes) && !unicode.IsSpace(runes[i]) && (runes[i] != ')' || depth > 0) {
+			switch runes[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if runes[i] != '"' {
+				sb.WriteRune(runes[i])
+				unquoted = true
+				i++
+				continue
+			}
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("missing closing quote")
+			}
+			sb.WriteString(string(runes[i+1 : end]))
+			quoted = true
+			i = end + 1
+		}
+		word := token{kind: wordToken, text: sb.String(), quoted: quoted && !unquoted}
+		if !quoted {
+			switch strings.ToLower(word.text) {
+			case "and":
+				word.kind = andToken
+			case "or":
+				word.kind = orToken
+			case "not":
+				word.kind = notToken
+			}
+		}
+		tokens = append(tokens, word)
+	}
+	return joinComparisons(tokens)
+}
+
+// comparisonOperator matches a comparison operator, alone in a word
+var comparisonOperator = regexp.MustCompile(`^(<=|>=|!=|<|>|=)$`)
+
+// joinComparisons joins the words of the comparisons written with spaces around their
+// operator, e.g. replicas < desired, into a single word. An operator without a field before
+// it is reported.
+func joinComparisons(tokens []token) ([]token, error) {
+	joined := make([]token, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		word := tokens[i]
+		if word.kind != wordToken || word.quoted {
+			joined = append(joined, word)
+			continue
+		}
+		if comparisonOperator.MatchString(word.text) {
+			return nil, fmt.Errorf("expected a field before %s", word.text)
+		}
+		next := func() (token, bool) {
+			if i+1 < len(tokens) && tokens[i+1].kind == wordToken {
+				return tokens[i+1], true
+			}
+			return token{}, false
+		}
+		// The field, then the operator, alone or followed by the value
+		if value, found := next(); found && !value.quoted && fieldName.MatchString(word.text) && strings.IndexAny(value.text, "<>=!") == 0 {
+			word.text += value.text
+			i++
+		}
+		// The field and the operator, then the value
+		if index := strings.IndexAny(word.text, "<>=!"); index > 0 && fieldName.MatchString(word.text[:index]) && comparisonOperator.MatchString(word.text[index:]) {
+			if value, found := next(); found {
+				word.text += value.text
+				i++
+			}
+		}
+		joined = append(joined, word)
+	}
+	return joined, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() *token {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+// parseOr parses terms joined with or, which binds less than and
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for token := p.peek(); token != nil && token.kind == orToken; token = p.peek() {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+// parseAnd parses terms joined with and, or just following each other
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for token := p.peek(); token != nil && token.kind != orToken && token.kind != closeToken; token = p.peek() {
+		if token.kind == andToken {
+			p.pos++
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	token := p.peek()
+	if token == nil {
+		if p.pos > 0 {
+			return nil, fmt.Errorf("expected a term after %q", p.tokens[p.pos-1].text)
+		}
+		return nil, fmt.Errorf("expected a term")
+	}
+	p.pos++
+	switch token.kind {
+	case notToken:
+		negated, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{negated}, nil
+	case openToken:
+		group, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.peek(); closing == nil || closing.kind != closeToken {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return group, nil
+	case wordToken:
+		return parseTerm(*token)
+	default:
+		return nil, fmt.Errorf("unexpected %q", token.text)
+	}
+}
+
+// operators of the field terms, longest first so that <= isn't read as <
+var operators = []string{"<=", ">=", "!=", ":", "<", ">", "="}
+
+// fieldName matches the names of the fields
+var fieldName = regexp.MustCompile(`^[a-zA-Z_]+$`)
+
+// parseTerm parses a word as a field term, or as text to search
+func parseTerm(word token) (node, error) {
+	if word.quoted {
+		return textNode{strings.ToLower(word.text)}, nil
+	}
+	index := strings.IndexAny(word.text, ":<>=!")
+	if index <= 0 || !fieldName.MatchString(word.text[:index]) {
+		return textNode{strings.ToLower(word.text)}, nil
+	}
+	field := strings.ToLower(word.text[:index])
+	operator := ""
+	for _, candidate := range operators {
+		if strings.HasPrefix(word.text[index:], candidate) {
+			operator = candidate
+			break
+		}
+	}
+	if operator == "" {
+		return nil, fmt.Errorf("invalid operator in %q", word.text)
+	}
+	value := word.text[index+len(operator):]
+
+	if operator != ":" {
+		if value == "" {
+			return nil, fmt.Errorf("expected a value after %s%s", field, operator)
+		}
+		return compareNode{field: field, operator: operator, value: value}, nil
+	}
+	if field == "label" {
+		return parseLabelTerm(value)
+	}
+	pattern, err := parsePattern(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", word.text, err)
+	}
+	return patternNode{field: field, pattern: pattern}, nil
+}
+
+func parseLabelTerm(value string) (node, error) {
+	if strings.HasPrefix(value, "~") {
+		pattern, err := parsePattern(value)
+		if err != nil {
+			return nil, fmt.Errorf("label:%s: %w", value, err)
+		}
+		return labelNode{pattern: pattern}, nil
+	}
+	key, valuePattern, hasValue := strings.Cut(value, "=")
+	if key == "" {
+		return nil, fmt.Errorf("expected a label key after label:")
+	}
+	if !hasValue {
+		return labelNode{key: key}, nil
+	}
+	pattern, err := parsePattern(valuePattern)
+	if err != nil {
+		return nil, fmt.Errorf("label:%s: %w", value, err)
+	}
+	return labelNode{key: key, pattern: pattern}, nil
+}
+
+// parsePattern compiles a ~regexp or a glob pattern, both case-insensitive
+func parsePattern(value string) (*regexp.Regexp, error) {
+	if expr, isRegexp := strings.CutPrefix(value, "~"); isRegexp {
+		pattern, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		return pattern, nil
+	}
+	expr := regexp.QuoteMeta(value)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	return regexp.Compile("(?is)^" + expr + "$")
+}
+
+// compareValues compares two values as numbers when both are, and as text otherwise
+func compareValues(a, b string) int {
+	numberA, okA := parseNumber(a)
+	numberB, okB := parseNumber(b)
+	if okA && okB {
+		return cmp.Compare(numberA, numberB)
+	}
+	return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// sizeUnits are the suffixes of sizes, binary and decimal
+var sizeUnits = []struct {
+	suffix string
+	factor float64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+	{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+	{"B", 1},
+}
+
+// parseNumber parses a number, a percentage, a size in bytes or a duration in seconds
+func parseNumber(value string) (float64, bool) {
+	value = strings.TrimSuffix(value, "%")
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number, true
+	}
+	for _, unit := range sizeUnits {
+		if number, found := strings.CutSuffix(value, unit.suffix); found {
+			if size, err := strconv.ParseFloat(number, 64); err == nil {
+				return size * unit.factor, true
+			}
+		}
+	}
+	if days, found := strings.CutSuffix(value, "d"); found {
+		if number, err := strconv.ParseFloat(days, 64); err == nil {
+			return number * 24 * 60 * 60, true
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return duration.Seconds(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,207 @@
+// Package query implements the filter language of the tables and of the --filter flag
+// of the subcommands, e.g.
+//
+//	status:running node:worker-0* -label:env=dev
+//	replicas<desired or image:~redis
+//
+// A query is made of terms, all of which must match unless joined with "or":
+//
+//   - a bare word matches items containing it, case-insensitively
+//   - field:pattern matches a field against a glob pattern, where * matches any text
+//   - field:~regexp matches a field against a case-insensitive regular expression
+//   - label:key=pattern matches a label, and label:key its presence
+//   - field<value compares a field with a value or another field, numerically when both
+//     are numbers, durations (1h30m) or sizes (512MiB). The operators are = != < <= > >=
+//
+// Terms are negated with a leading - or ! (or not), joined with and / && (the default)
+// and or / || / |, and grouped with parentheses. Quote values with spaces or operators.
+package query
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Item is what a query is matched against, e.g. a service or a task
+type Item struct {
+	// Fields are the values of the fields that terms name, by lowercase field name
+	Fields map[string]string
+	// Labels are matched by the label field
+	Labels map[string]string
+	// Text is searched by bare words
+	Text []string
+}
+
+// Query is a parsed filter expression
+type Query struct {
+	root node
+}
+
+// Parse parses a filter expression. An empty expression matches everything.
+func Parse(expr string) (Query, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return Query{}, err
+	}
+	p := &parser{tokens: tokens}
+	if len(tokens) == 0 {
+		return Query{}, nil
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return Query{}, err
+	}
+	if token := p.peek(); token != nil {
+		return Query{}, fmt.Errorf("unexpected %q", token.text)
+	}
+	return Query{root: root}, nil
+}
+
+// Empty reports whether the query matches everything
+func (q Query) Empty() bool {
+	return q.root == nil
+}
+
+// Match reports whether an item matches the query
+func (q Query) Match(item Item) bool {
+	return q.root == nil || q.root.match(item)
+}
+
+// Check returns an error naming the first field of the query that isn't one of fields
+func (q Query) Check(fields []string) error {
+	if q.root == nil {
+		return nil
+	}
+	if field := q.root.unknownField(fields); field != "" {
+		return fmt.Errorf("unknown field %q, expected one of %s", field, strings.Join(fields, ", "))
+	}
+	return nil
+}
+
+// Filter returns the items matching the query, item describing each of them
+func Filter[T any](q Query, items []T, item func(T) Item) []T {
+	if q.Empty() {
+		return items
+	}
+	filtered := make([]T, 0, len(items))
+	for _, i := range items {
+		if q.Match(item(i)) {
+			filtered = append(filtered, i)
+		}
+	}
+	return filtered
+}
+
+type node interface {
+	match(item Item) bool
+	// unknownField returns the first field name not in fields, if any
+	unknownField(fields []string) string
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) match(item Item) bool { return n.left.match(item) || n.right.match(item) }
+func (n orNode) unknownField(fields []string) string {
+	return cmp.Or(n.left.unknownField(fields), n.right.unknownField(fields))
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) match(item Item) bool { return n.left.match(item) && n.right.match(item) }
+func (n andNode) unknownField(fields []string) string {
+	return cmp.Or(n.left.unknownField(fields), n.right.unknownField(fields))
+}
+
+type notNode struct{ node node }
+
+func (n notNode) match(item Item) bool                { return !n.node.match(item) }
+func (n notNode) unknownField(fields []string) string { return n.node.unknownField(fields) }
+
+// textNode is a bare word
+type textNode struct{ text string }
+
+func (n textNode) match(item Item) bool {
+	for _, text := range item.Text {
+		if strings.Contains(strings.ToLower(text), n.text) {
+			return true
+		}
+	}
+	return false
+}
+func (n textNode) unknownField(fields []string) string { return "" }
+
+// patternNode is a field:pattern or field:~regexp term
+type patternNode struct {
+	field   string
+	pattern *regexp.Regexp
+}
+
+func (n patternNode) match(item Item) bool {
+	value, found := item.Fields[n.field]
+	return found && n.pattern.MatchString(value)
+}
+func (n patternNode) unknownField(fields []string) string { return unknown(n.field, fields) }
+
+// labelNode is a label:key=pattern, label:key or label:~regexp term
+type labelNode struct {
+	key     string
+	pattern *regexp.Regexp // Of the value, or of key=value when key is empty
+}
+
+func (n labelNode) match(item Item) bool {
+	if n.key == "" {
+		for key, value := range item.Labels {
+			if n.pattern.MatchString(key + "=" + value) {
+				return true
+			}
+		}
+		return false
+	}
+	value, found := item.Labels[n.key]
+	return found && (n.pattern == nil || n.pattern.MatchString(value))
+}
+func (n labelNode) unknownField(fields []string) string { return unknown("label", fields) }
+
+// compareNode is a field<value term, value possibly naming another field
+type compareNode struct {
+	field    string
+	operator string
+	value    string
+}
+
+func (n compareNode) match(item Item) bool {
+	left, found := item.Fields[n.field]
+	if !found {
+		return false
+	}
+	right, isField := item.Fields[strings.ToLower(n.value)]
+	if !isField {
+		right = n.value
+	}
+	result := compareValues(left, right)
+	switch n.operator {
+	case "=":
+		return result == 0
+	case "!=":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	default: // >=
+		return result >= 0
+	}
+}
+func (n compareNode) unknownField(fields []string) string { return unknown(n.field, fields) }
+
+func unknown(field string, fields []string) string {
+	if slices.Contains(fields, field) {
+		return ""
+	}
+	return field
+}
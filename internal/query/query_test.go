@@ -0,0 +1,118 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/moby/moby/api/types/swarm"
+)
+
+func TestMatch(t *testing.T) {
+	api := ServiceItem(models.Service{
+		ID: "svc-1", Name: "backend_api", RunningTasks: 4, DesiredTasks: 5,
+		Image:  "registry.example.com/backend/api:2.3.1",
+		Labels: map[string]string{"env": "prod", "team": "core"},
+	})
+	cache := ServiceItem(models.Service{
+		ID: "svc-2", Name: "backend_redis", RunningTasks: 3, DesiredTasks: 3,
+		Image:  "redis:7.4-alpine",
+		Labels: map[string]string{"env": "staging"},
+	})
+	failed := TaskItem(models.Task{
+		TaskID: "task-1", Status: swarm.TaskStateFailed,
+		Node:      models.Node{Hostname: "worker-01", Host: "10.0.0.5"},
+		CreatedAt: time.Now().Add(-2 * time.Hour),
+	})
+
+	tests := []struct {
+		expr  string
+		item  Item
+		match bool
+	}{
+		{"", api, true},
+		{"API", api, true},
+		{"redis", api, false},
+		{"replicas<desired", api, true},
+		{"replicas<desired", cache, false},
+		{"replicas>=3 desired=5", api, true},
+		{"replicas < desired", api, true},
+		{"replicas < desired", cache, false},
+		{"replicas <desired", api, true},
+		{"replicas< desired", api, true},
+		{"replicas >= 3 and desired != 4", api, true},
+		{"replicas >= 3 and desired != 5", api, false},
+		{"label:env=prod", api, true},
+		{"label:env=prod", cache, false},
+		{"label:env=PR*", api, true},
+		{"label:team", api, true},
+		{"label:team", cache, false},
+		{"label:~^team=", api, true},
+		{"image:~redis", cache, true},
+		{"image:~redis", api, false},
+		{"image:redis", cache, false},
+		{"image:redis:*", cache, true},
+		{"image:~(redis|memcached)", cache, true},
+		{"(image:~(redis|memcached))", api, false},
+		{"-label:env=prod", cache, true},
+		{"!label:env=prod", api, false},
+		{"not image:~redis", api, true},
+		{"image:~redis or label:env=prod", api, true},
+		{"image:~redis or label:env=prod", cache, true},
+		{"image:~redis and label:env=prod", cache, false},
+		{"image:~redis || label:env=prod", api, true},
+		{"backend -(image:~redis | replicas<desired)", api, false},
+		{"backend -(image:~redis | replicas<desired)", cache, false},
+		{"name:backend_*", api, true},
+		{`"svc-2"`, cache, true},
+		{"status:failed", failed, true},
+		{"status:running", failed, false},
+		{"node:worker-0*", failed, true},
+		{"host:10.0.0.*", failed, true},
+		{"age>1h", failed, true},
+		{"age<30m", failed, false},
+	}
+	for _, test := range tests {
+		q, err := Parse(test.expr)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", test.expr, err)
+			continue
+		}
+		if match := q.Match(test.item); match != test.match {
+			t.Errorf("Parse(%q).Match(%v) = %v, expected %v", test.expr, test.item.Fields, match, test.match)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"(status:failed":     "missing closing parenthesis",
+		"status:failed)":     `unexpected ")"`,
+		"status:failed or":   `expected a term after "or"`,
+		"image:~(":           "invalid regular expression",
+		`name:"unterminated`: "missing closing quote",
+		"replicas<":          "expected a value after replicas<",
+		"replicas <":         "expected a value after replicas<",
+		"replicas < or api":  "expected a value after replicas<",
+		"< desired":          "expected a field before <",
+		"label:":             "expected a label key",
+	}
+	for expr, expected := range tests {
+		_, err := Parse(expr)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Parse(%q) = %v, expected an error containing %q", expr, err, expected)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	q, _ := Parse("status:failed or nod:worker-01")
+	if err := q.Check(TaskFields); err == nil || !strings.Contains(err.Error(), `"nod"`) {
+		t.Errorf("Expected an unknown field error for nod, got %v", err)
+	}
+	q, _ = Parse("status:failed node:worker-01 -label:env=dev")
+	if err := q.Check(TaskFields); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -17,6 +18,8 @@ import (
 type sshConnection struct {
 	Cmd        *exec.Cmd
 	SocketPath string
+	// ControlPath is the ssh control socket, used to add port forwards to the connection
+	ControlPath string
 }
 
 // Connector manages the connections to remote Docker hosts
@@ -25,9 +28,21 @@ type sshConnection struct {
 // forwarding the remote Docker socket to a local temporary socket file.
 // So it's important that the user has SSH access to the remote hosts already set.
 type DockerConnector struct {
+	// Guards clients, sshConnections and connecting, since commands run concurrently
+	mu sync.Mutex
 	// Maps hostnames to Docker clients
 	clients        map[string]*client.Client
 	sshConnections map[string]sshConnection
+	// The connections in progress, by hostname
+	connecting map[string]*pendingConnection
+}
+
+// pendingConnection is a connection to a host in progress, whose client is shared with the
+// callers asking for it meanwhile
+type pendingConnection struct {
+	done chan struct{}
+	cli  *client.Client
+	err  error
 }
 
 type Options func(*DockerConnector)
@@ -36,6 +51,7 @@ func NewConnector(opts ...Options) *DockerConnector {
 	conn := &DockerConnector{
 		clients:        make(map[string]*client.Client),
 		sshConnections: make(map[string]sshConnection),
+		connecting:     make(map[string]*pendingConnection),
 	}
 	for _, opt := range opts {
 		opt(conn)
@@ -44,6 +60,8 @@ func NewConnector(opts ...Options) *DockerConnector {
 }
 
 func (c *DockerConnector) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	log.Println("DockerConnector: Closing")
 	for host, cli := range c.clients {
 		log.Printf("DockerConnector: Closing %s\n", host)
@@ -61,6 +79,7 @@ func (c *DockerConnector) Close() error {
 		sshConn.Cmd.Wait()
 		log.Printf("DockerConnector: SSH connection to host %s closed\n", host)
 		os.Remove(sshConn.SocketPath)
+		os.Remove(sshConn.ControlPath)
 		log.Printf("DockerConnector: Removed socket file at %s\n", sshConn.SocketPath)
 	}
 	return nil
@@ -70,38 +89,110 @@ func (c *DockerConnector) Close() error {
 // This connector expects your machine to have an SSH configuration for the host already set
 // through the ~/.ssh/config file.
 //
+// The lock is only held to look up the clients: a host being connected only makes the callers
+// asking for that host wait, and they share its connection.
+//
 // Make sure you call Close() at the end of your program to ensure all connections are properly closed.
 func (c *DockerConnector) ClientForHost(host string) (*client.Client, error) {
-	if _, exists := c.clients[host]; !exists {
-		return c.connectToHost(host)
+	c.mu.Lock()
+	if cli, exists := c.clients[host]; exists {
+		c.mu.Unlock()
+		return cli, nil
 	}
-	return c.clients[host], nil
+	pending, inProgress := c.connecting[host]
+	if !inProgress {
+		pending = &pendingConnection{done: make(chan struct{})}
+		c.connecting[host] = pending
+	}
+	c.mu.Unlock()
+	if inProgress {
+		<-pending.done
+		return pending.cli, pending.err
+	}
+
+	pending.cli, pending.err = c.connectToHost(host)
+	c.mu.Lock()
+	// A failed connection is attempted again by the next caller
+	delete(c.connecting, host)
+	if pending.err == nil {
+		c.clients[host] = pending.cli
+	}
+	c.mu.Unlock()
+	close(pending.done)
+	return pending.cli, pending.err
 }
 
 // AttachToContainer executes a command in a running container, returning an open connection to it.
+// Interactive sessions use a TTY; without it, the output streams are multiplexed (see ContainerConnection.ReadOutput).
 // IMPORTANT: You must make sure to close the connection to avoid any issues.
-func (c *DockerConnector) AttachToContainer(ctx context.Context, host string, containerID string, cmd []string) (*ContainerConnection, error) {
+func (c *DockerConnector) AttachToContainer(ctx context.Context, host string, containerID string, cmd []string, tty bool) (*ContainerConnection, error) {
 	cli, err := c.ClientForHost(host)
 	if err != nil {
 		return nil, errors.Wrap(err, "Connector#AttachToContainer: failed to retrieve host")
 	}
 	execResp, err := cli.ContainerExecCreate(ctx, containerID, container.ExecOptions{
-		Tty:          true,
+		Tty:          tty,
 		AttachStdin:  true,
 		AttachStderr: true,
 		AttachStdout: true,
-		Cmd:          cmd,
+		// Output is rendered by an xterm compatible emulator
+		Env: []string{"TERM=xterm-256color"},
+		Cmd: cmd,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "Connector#AttachToContainer: failed to create exec")
 	}
 	containerCli, err := cli.ContainerExecAttach(ctx, execResp.ID, container.ExecAttachOptions{
-		Tty: true,
+		Tty: tty,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "Connector#AttachToContainer: failed to attach to container")
 	}
-	return &ContainerConnection{cli: cli, attachID: execResp.ID, containerID: containerID, conn: containerCli.Conn}, nil
+	return &ContainerConnection{
+		cli:         cli,
+		attachID:    execResp.ID,
+		containerID: containerID,
+		conn:        containerCli.Conn,
+		reader:      containerCli.Reader,
+		tty:         tty,
+	}, nil
+}
+
+// ForwardPort forwards localAddr to remoteAddr, as seen from the host, through the host SSH connection.
+// The forward lasts until CancelForward is called or the connector is closed.
+func (c *DockerConnector) ForwardPort(host string, localAddr string, remoteAddr string) error {
+	if err := c.controlCommand(host, "forward", localAddr, remoteAddr); err != nil {
+		return errors.Wrap(err, "Connector#ForwardPort")
+	}
+	return nil
+}
+
+// CancelForward stops a port forward started with ForwardPort
+func (c *DockerConnector) CancelForward(host string, localAddr string, remoteAddr string) error {
+	if err := c.controlCommand(host, "cancel", localAddr, remoteAddr); err != nil {
+		return errors.Wrap(err, "Connector#CancelForward")
+	}
+	return nil
+}
+
+// controlCommand sends a forwarding control command to the host SSH control master
+func (c *DockerConnector) controlCommand(host string, command string, localAddr string, remoteAddr string) error {
+	// Make sure the connection to the host is established
+	if _, err := c.ClientForHost(host); err != nil {
+		return errors.Wrap(err, "failed to connect to host")
+	}
+	c.mu.Lock()
+	sshConn := c.sshConnections[host]
+	c.mu.Unlock()
+
+	output, err := exec.Command(
+		"ssh", "-S", sshConn.ControlPath, "-O", command,
+		"-L", fmt.Sprintf("%s:%s", localAddr, remoteAddr), host,
+	).CombinedOutput()
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("ssh -O %s failed: %s", command, output))
+	}
+	return nil
 }
 
 func (c *DockerConnector) connectToHost(host string) (cli *client.Client, err error) {
@@ -116,7 +207,9 @@ func (c *DockerConnector) connectToHost(host string) (cli *client.Client, err er
 	}
 	log.Printf("Establishing SSH connection to host %s\n", host)
 	socketPath := fmt.Sprintf("/tmp/swarm-browser-%d.sock", time.Now().UnixNano())
-	sshCommand := exec.Command("ssh", "-N", "-L", fmt.Sprintf("%s:/var/run/docker.sock", socketPath), host)
+	controlPath := fmt.Sprintf("/tmp/swarm-browser-%d.ctl", time.Now().UnixNano())
+	// The connection is started as a control master, so port forwards can be added to it later on
+	sshCommand := exec.Command("ssh", "-N", "-M", "-S", controlPath, "-L", fmt.Sprintf("%s:/var/run/docker.sock", socketPath), host)
 	sshCommand.Stdout = log.Writer()
 	sshCommand.Stderr = log.Writer()
 	if err := sshCommand.Start(); err != nil {
@@ -124,7 +217,9 @@ func (c *DockerConnector) connectToHost(host string) (cli *client.Client, err er
 		return nil, errors.Wrap(err, fmt.Sprintf("failed to start SSH tunnel to host %s", host))
 	}
 	log.Printf("SSH connection to host %s established with PID %d\n", host, sshCommand.Process.Pid)
-	c.sshConnections[host] = sshConnection{Cmd: sshCommand, SocketPath: socketPath}
+	c.mu.Lock()
+	c.sshConnections[host] = sshConnection{Cmd: sshCommand, SocketPath: socketPath, ControlPath: controlPath}
+	c.mu.Unlock()
 
 	// Wait for the socket to be available
 	if err := waitForSocket(socketPath, 15*time.Second); err != nil {
@@ -139,7 +234,6 @@ func (c *DockerConnector) connectToHost(host string) (cli *client.Client, err er
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("failed to create Docker client for host %s", host))
 	}
-	c.clients[host] = cli
 	return cli, nil
 }
 
@@ -2,8 +2,11 @@ package connector
 
 import (
 	"context"
+	"io"
 	"net"
+	"time"
 
+	"github.com/moby/moby/api/pkg/stdcopy"
 	"github.com/moby/moby/api/types/container"
 	"github.com/moby/moby/client"
 	"github.com/pkg/errors"
@@ -14,10 +17,14 @@ type ContainerConnection struct {
 	containerID string
 	attachID    string
 	conn        net.Conn
+	// Buffered reader of conn, which may hold output read along with the attach response
+	reader io.Reader
+	tty    bool
 }
 
 func (c *ContainerConnection) ResizeTTY(ctx context.Context, width, height uint) error {
-	if err := c.cli.ContainerResize(ctx, c.containerID, container.ResizeOptions{
+	// The exec session has its own TTY, separate from the container one
+	if err := c.cli.ContainerExecResize(ctx, c.attachID, container.ResizeOptions{
 		Height: height,
 		Width:  width,
 	}); err != nil {
@@ -34,6 +41,60 @@ func (c *ContainerConnection) Conn() net.Conn {
 	return c.conn
 }
 
+// Reader returns the buffered reader of the connection, the output of the exec
+func (c *ContainerConnection) Reader() io.Reader {
+	return c.reader
+}
+
+// ReadOutput copies the output of the exec until it ends, splitting stdout and stderr
+// when the exec has no TTY. With a TTY, both are written to stdout.
+func (c *ContainerConnection) ReadOutput(stdout, stderr io.Writer) error {
+	var err error
+	if c.tty {
+		_, err = io.Copy(stdout, c.reader)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, c.reader)
+	}
+	if err != nil {
+		return errors.Wrap(err, "connector.ContainerConnection#ReadOutput")
+	}
+	return nil
+}
+
+// CloseWrite closes the exec standard input, signalling the end of the input to the command
+func (c *ContainerConnection) CloseWrite() error {
+	closeWriter, ok := c.conn.(interface{ CloseWrite() error })
+	if !ok {
+		return errors.New("connector.ContainerConnection#CloseWrite: connection can't be half-closed")
+	}
+	if err := closeWriter.CloseWrite(); err != nil {
+		return errors.Wrap(err, "connector.ContainerConnection#CloseWrite")
+	}
+	return nil
+}
+
+// ExitCode returns the exit code of the exec, once it ended. The output of the exec can end
+// just before the daemon records it as ended, so it is inspected again until then, or until
+// ctx is done.
+func (c *ContainerConnection) ExitCode(ctx context.Context) (int, error) {
+	backoff := 10 * time.Millisecond
+	for {
+		inspect, err := c.cli.ContainerExecInspect(ctx, c.attachID)
+		if err != nil {
+			return 0, errors.Wrap(err, "connector.ContainerConnection#ExitCode: failed to inspect exec")
+		}
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+		select {
+		case <-time.After(backoff):
+			backoff = min(backoff*2, time.Second)
+		case <-ctx.Done():
+			return 0, errors.Wrap(ctx.Err(), "connector.ContainerConnection#ExitCode: exec is still running")
+		}
+	}
+}
+
 func (c *ContainerConnection) Close() error {
 	err := c.conn.Close()
 	if err != nil {
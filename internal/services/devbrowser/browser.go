@@ -1,12 +1,18 @@
 package devbrowser
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"fmt"
 	"io"
+	"math/rand"
 	"net"
 	"os"
 	"os/exec"
+	"path"
+	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/mendes11/swarm-browser/internal/core"
@@ -18,6 +24,13 @@ type DevBrowser struct {
 	clusterName string
 	config      *DevConfig
 	conns       []net.Conn
+
+	// Active port forwards, keyed by local port
+	forwardsMu sync.Mutex
+	forwards   map[int]*devPortForward
+
+	// Shell sessions, kept running while detached
+	sessions *core.ExecSessions
 }
 
 // Ensure it conforms to the interface
@@ -43,6 +56,7 @@ func New(clusterName string, configPath string) (*DevBrowser, error) {
 		clusterName: clusterName,
 		config:      config,
 		conns:       make([]net.Conn, 0),
+		sessions:    core.NewExecSessions(),
 	}, nil
 }
 
@@ -61,6 +75,7 @@ func NewWithConfig(clusterName string, config *DevConfig) (*DevBrowser, error) {
 		clusterName: clusterName,
 		config:      config,
 		conns:       make([]net.Conn, 0),
+		sessions:    core.NewExecSessions(),
 	}, nil
 }
 
@@ -70,7 +85,7 @@ func (d *DevBrowser) GetCluster() models.Cluster {
 }
 
 // InspectNode implements core.ClusterBrowser by returning mock node information
-func (d *DevBrowser) InspectNode(node models.Node) (*models.NodeInfo, error) {
+func (d *DevBrowser) InspectNode(ctx context.Context, node models.Node) (*models.NodeInfo, error) {
 	cluster := d.config.Clusters[d.clusterName]
 
 	// Verify the node exists in the cluster
@@ -86,14 +101,42 @@ func (d *DevBrowser) InspectNode(node models.Node) (*models.NodeInfo, error) {
 		return nil, fmt.Errorf("node %s not found in cluster %s", node.Hostname, d.clusterName)
 	}
 
-	// Return mock node info
-	return &models.NodeInfo{
-		Role:     "manager", // Default to manager for dev
-		Name:     node.Hostname,
-		CPUs:     4,
-		Memory:   8589934592, // 8GB in bytes
-		Platform: "linux/amd64",
-	}, nil
+	info := mockNodeInfo(node)
+	return &info, nil
+}
+
+// mockNodeInfo returns the node information of a mock node
+func mockNodeInfo(node models.Node) models.NodeInfo {
+	return models.NodeInfo{
+		Role:         "manager", // Default to manager for dev
+		Name:         node.Hostname,
+		CPUs:         4,
+		Memory:       8589934592, // 8GB in bytes
+		Platform:     "linux/amd64",
+		State:        "ready",
+		Availability: "active",
+	}
+}
+
+// mockTaskStatus completes a mock task with the details of its status: the state it is driven
+// to, the status message and, for failed tasks, the error and exit code
+func mockTaskStatus(task *models.Task, taskErr string) {
+	task.DesiredState = swarm.TaskStateRunning
+	task.NodeInfo = mockNodeInfo(task.Node)
+	switch task.Status {
+	case swarm.TaskStateFailed:
+		task.DesiredState = swarm.TaskStateShutdown
+		task.Message = "started"
+		task.Err = cmp.Or(taskErr, "task: non-zero exit (1)")
+		task.ExitCode = 1
+	case swarm.TaskStatePending:
+		task.Message = "pending task scheduling"
+		task.Err = cmp.Or(taskErr, "no suitable node (insufficient resources)")
+	case swarm.TaskStateRunning:
+		task.Message = "started"
+	default:
+		task.Message = string(task.Status)
+	}
 }
 
 // ListStacks implements core.ClusterBrowser using config data
@@ -121,6 +164,11 @@ func (d *DevBrowser) ListServices(ctx context.Context, stack models.Stack) ([]mo
 					RunningTasks: svcConfig.RunningTasks,
 					DesiredTasks: svcConfig.DesiredTasks,
 					Stack:        stack,
+					Image:        svcConfig.Image,
+					Labels:       svcConfig.Labels,
+					Mode:         cmp.Or(svcConfig.Mode, "replicated"),
+					Ports:        svcConfig.Ports,
+					Constraints:  svcConfig.Constraints,
 				}
 			}
 			return services, nil
@@ -186,12 +234,21 @@ func (d *DevBrowser) ListTasks(ctx context.Context, service models.Service) ([]m
 
 			status := d.parseTaskStatus(taskConfig.Status)
 
+			image := taskConfig.Image
+			if image == "" {
+				image = serviceConfig.Image
+			}
+
 			tasks[i] = models.Task{
 				TaskID:      taskID,
 				ContainerID: containerID,
 				Node:        node,
 				Status:      status,
+				Image:       image,
+				CreatedAt:   taskCreatedAt(i),
+				Labels:      serviceConfig.Labels,
 			}
+			mockTaskStatus(&tasks[i], taskConfig.Error)
 		}
 		return tasks, nil
 	}
@@ -207,7 +264,11 @@ func (d *DevBrowser) ListTasks(ctx context.Context, service models.Service) ([]m
 			ContainerID: fmt.Sprintf("container-%s-%03d", service.ID, i+1),
 			Node:        nodes[nodeIndex],
 			Status:      swarm.TaskStateRunning,
+			Image:       serviceConfig.Image,
+			CreatedAt:   taskCreatedAt(int(i)),
+			Labels:      serviceConfig.Labels,
 		})
+		mockTaskStatus(&tasks[len(tasks)-1], "")
 	}
 
 	// Generate pending/failed tasks for the remaining desired count
@@ -223,12 +284,270 @@ func (d *DevBrowser) ListTasks(ctx context.Context, service models.Service) ([]m
 			ContainerID: fmt.Sprintf("container-%s-%03d", service.ID, i+1),
 			Node:        nodes[nodeIndex],
 			Status:      status,
+			Image:       serviceConfig.Image,
+			CreatedAt:   taskCreatedAt(int(i)),
+			Labels:      serviceConfig.Labels,
 		})
+		mockTaskStatus(&tasks[len(tasks)-1], "")
 	}
 
 	return tasks, nil
 }
 
+// taskCreatedAt makes up the creation time of the i-th task of a service, older tasks first.
+// Times are rounded to the hour so they don't change from one listing to the next.
+func taskCreatedAt(i int) time.Time {
+	return time.Now().Truncate(time.Hour).Add(-time.Duration(i+1) * 95 * time.Minute)
+}
+
+// ListNetworks implements core.ClusterBrowser by generating an ingress network
+// and a default overlay network for each configured stack
+func (d *DevBrowser) ListNetworks(ctx context.Context) ([]models.Network, error) {
+	networks := []models.Network{
+		{
+			ID:      "ingress-network",
+			Name:    "ingress",
+			Driver:  "overlay",
+			Scope:   "swarm",
+			Subnets: []string{"10.0.0.0/24"},
+			Ingress: true,
+		},
+	}
+	for i, stackConfig := range d.config.GetStacksForCluster(d.clusterName) {
+		networks = append(networks, models.Network{
+			ID:         fmt.Sprintf("%s-network", stackConfig.Name),
+			Name:       fmt.Sprintf("%s_default", stackConfig.Name),
+			Driver:     "overlay",
+			Scope:      "swarm",
+			Subnets:    []string{fmt.Sprintf("10.0.%d.0/24", i+1)},
+			Attachable: true,
+		})
+	}
+	return networks, nil
+}
+
+// ListNetworkAttachments implements core.ClusterBrowser by attaching every service
+// of a stack, and their running tasks, to the stack default network
+func (d *DevBrowser) ListNetworkAttachments(ctx context.Context, network models.Network) ([]models.NetworkAttachment, error) {
+	attachments := []models.NetworkAttachment{}
+	for i, stackConfig := range d.config.GetStacksForCluster(d.clusterName) {
+		if network.ID != fmt.Sprintf("%s-network", stackConfig.Name) {
+			continue
+		}
+		services, err := d.ListServices(ctx, models.Stack{Name: stackConfig.Name})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services: %w", err)
+		}
+		host := 2
+		for _, service := range services {
+			attachments = append(attachments, models.NetworkAttachment{
+				Service: service,
+				Address: fmt.Sprintf("10.0.%d.%d/24", i+1, host),
+			})
+			host++
+			tasks, err := d.ListTasks(ctx, service)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list tasks: %w", err)
+			}
+			for _, task := range tasks {
+				if task.Status != swarm.TaskStateRunning {
+					continue
+				}
+				attachments = append(attachments, models.NetworkAttachment{
+					Service: service,
+					TaskID:  task.TaskID,
+					Node:    task.Node,
+					Address: fmt.Sprintf("10.0.%d.%d/24", i+1, host),
+				})
+				host++
+			}
+		}
+	}
+	return attachments, nil
+}
+
+// ListTaskMounts implements core.ClusterBrowser by returning a mock data volume
+// and a timezone bind mount for every task
+func (d *DevBrowser) ListTaskMounts(ctx context.Context, task models.Task) ([]models.Mount, error) {
+	if task.ContainerID == "" {
+		return nil, fmt.Errorf("task %s has no container", task.TaskID)
+	}
+	return []models.Mount{
+		{
+			Type:        "volume",
+			Name:        fmt.Sprintf("%s_data", task.ContainerID),
+			Source:      fmt.Sprintf("/var/lib/docker/volumes/%s_data/_data", task.ContainerID),
+			Destination: "/data",
+			Driver:      "local",
+			Mode:        "z",
+			RW:          true,
+			Size:        268435456, // 256MB
+		},
+		{
+			Type:        "bind",
+			Source:      "/etc/localtime",
+			Destination: "/etc/localtime",
+			Mode:        "ro",
+			RW:          false,
+			Size:        -1,
+		},
+	}, nil
+}
+
+// ListNodeVolumes implements core.ClusterBrowser by returning the mock data volumes
+// of every task scheduled in the node
+func (d *DevBrowser) ListNodeVolumes(ctx context.Context, node models.Node) ([]models.Volume, error) {
+	volumes := []models.Volume{}
+	for _, stackConfig := range d.config.GetStacksForCluster(d.clusterName) {
+		services, err := d.ListServices(ctx, models.Stack{Name: stackConfig.Name})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services: %w", err)
+		}
+		for _, service := range services {
+			tasks, err := d.ListTasks(ctx, service)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list tasks: %w", err)
+			}
+			for _, task := range tasks {
+				if task.Node != node {
+					continue
+				}
+				name := fmt.Sprintf("%s_data", task.ContainerID)
+				volumes = append(volumes, models.Volume{
+					Name:       name,
+					Driver:     "local",
+					Scope:      "local",
+					Mountpoint: fmt.Sprintf("/var/lib/docker/volumes/%s/_data", name),
+					Size:       268435456, // 256MB
+					RefCount:   1,
+					Node:       node,
+				})
+			}
+		}
+	}
+	return volumes, nil
+}
+
+// StreamTaskStats implements core.ClusterBrowser by emitting random resource usage
+// for every running task each second, until ctx is cancelled
+func (d *DevBrowser) StreamTaskStats(ctx context.Context, tasks []models.Task) (<-chan models.ContainerStats, error) {
+	running := make([]models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.Status == swarm.TaskStateRunning {
+			running = append(running, task)
+		}
+	}
+
+	statsCh := make(chan models.ContainerStats)
+	go func() {
+		defer close(statsCh)
+		counters := make(map[string]*models.ContainerStats, len(running))
+		for _, task := range running {
+			counters[task.TaskID] = &models.ContainerStats{
+				TaskID:      task.TaskID,
+				ContainerID: task.ContainerID,
+				MemoryLimit: 1073741824, // 1GB
+			}
+		}
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			for _, task := range running {
+				stats := counters[task.TaskID]
+				stats.Read = time.Now()
+				stats.CPUPercent = rand.Float64() * 100
+				stats.MemoryUsage = uint64(rand.Int63n(int64(stats.MemoryLimit)))
+				stats.NetRx += uint64(rand.Int63n(65536))
+				stats.NetTx += uint64(rand.Int63n(32768))
+				stats.BlockRead += uint64(rand.Int63n(16384))
+				stats.BlockWrite += uint64(rand.Int63n(8192))
+				select {
+				case statsCh <- *stats:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return statsCh, nil
+}
+
+// StatContainerPath implements core.ClusterBrowser using the mock container filesystem
+func (d *DevBrowser) StatContainerPath(ctx context.Context, task models.Task, filePath string) (models.FileInfo, error) {
+	localPath, err := d.containerPath(task, filePath)
+	if err != nil {
+		return models.FileInfo{}, err
+	}
+	info, err := os.Lstat(localPath)
+	if err != nil {
+		return models.FileInfo{}, fmt.Errorf("failed to stat %s: %w", filePath, err)
+	}
+	return fileInfo(filePath, info), nil
+}
+
+// ContainerWorkingDir implements core.ClusterBrowser, the mock containers working in /app
+func (d *DevBrowser) ContainerWorkingDir(ctx context.Context, task models.Task) (string, error) {
+	if _, err := d.containerPath(task, "/app"); err != nil {
+		return "", err
+	}
+	return "/app", nil
+}
+
+// ListContainerDir implements core.ClusterBrowser using the mock container filesystem
+func (d *DevBrowser) ListContainerDir(ctx context.Context, task models.Task, dirPath string) ([]models.FileInfo, error) {
+	localPath, err := d.containerPath(task, dirPath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dirPath, err)
+	}
+	files := make([]models.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", entry.Name(), err)
+		}
+		files = append(files, fileInfo(path.Join(dirPath, entry.Name()), info))
+	}
+	return files, nil
+}
+
+// CopyFromContainer implements core.ClusterBrowser by archiving a path of the mock container filesystem
+func (d *DevBrowser) CopyFromContainer(ctx context.Context, task models.Task, srcPath string) (io.ReadCloser, models.FileInfo, error) {
+	stat, err := d.StatContainerPath(ctx, task, srcPath)
+	if err != nil {
+		return nil, models.FileInfo{}, err
+	}
+	localPath, err := d.containerPath(task, srcPath)
+	if err != nil {
+		return nil, models.FileInfo{}, err
+	}
+	content, err := core.ArchivePath(localPath)
+	if err != nil {
+		return nil, models.FileInfo{}, fmt.Errorf("failed to archive %s: %w", srcPath, err)
+	}
+	return content, stat, nil
+}
+
+// CopyToContainer implements core.ClusterBrowser by extracting the archive into the mock container filesystem
+func (d *DevBrowser) CopyToContainer(ctx context.Context, task models.Task, dstDir string, content io.Reader) error {
+	localPath, err := d.containerPath(task, dstDir)
+	if err != nil {
+		return err
+	}
+	if err := core.ExtractArchive(content, localPath); err != nil {
+		return fmt.Errorf("failed to extract archive into %s: %w", dstDir, err)
+	}
+	return nil
+}
+
 // AttachToService implements core.ClusterBrowser with local terminal simulation
 func (d *DevBrowser) AttachToService(ctx context.Context, service models.Service, cmd []string) (core.ContainerConnection, error) {
 	// Get tasks to find a running one
@@ -330,7 +649,7 @@ func (d *DevBrowser) AttachToService(ctx context.Context, service models.Service
 			d.clusterName, service.Stack.Name, service.Name,
 			runningTask.TaskID, runningTask.ContainerID, runningTask.Node.Hostname,
 		)
-		serverConn.Write([]byte(banner))
+		ttyOutput{serverConn}.Write([]byte(banner))
 
 		// Copy from connection to process stdin
 		io.Copy(stdin, serverConn)
@@ -338,12 +657,12 @@ func (d *DevBrowser) AttachToService(ctx context.Context, service models.Service
 
 	go func() {
 		// Copy from process stdout to connection
-		io.Copy(serverConn, stdout)
+		io.Copy(ttyOutput{serverConn}, stdout)
 	}()
 
 	go func() {
 		// Copy from process stderr to connection
-		io.Copy(serverConn, stderr)
+		io.Copy(ttyOutput{serverConn}, stderr)
 	}()
 
 	// Monitor process and close connection when it exits
@@ -353,10 +672,10 @@ func (d *DevBrowser) AttachToService(ctx context.Context, service models.Service
 		serverConn.Close()
 	}()
 
-	return &DevContainerConnection{
+	return d.sessions.Open(&DevContainerConnection{
 		conn:        clientConn,
 		containerID: runningTask.ContainerID,
-	}, nil
+	}, models.ExecSession{Service: &service, Cmd: shellCmd}), nil
 }
 
 // AttachToTask implements core.ClusterBrowser by attaching to a specific task
@@ -448,7 +767,7 @@ func (d *DevBrowser) AttachToTask(ctx context.Context, task models.Task, cmd []s
 			containerIDDisplay,
 			task.Node.Hostname,
 		)
-		serverConn.Write([]byte(banner))
+		ttyOutput{serverConn}.Write([]byte(banner))
 
 		// Copy from connection to stdin
 		io.Copy(stdin, serverConn)
@@ -456,12 +775,12 @@ func (d *DevBrowser) AttachToTask(ctx context.Context, task models.Task, cmd []s
 
 	go func() {
 		// Copy stdout to connection
-		io.Copy(serverConn, stdout)
+		io.Copy(ttyOutput{serverConn}, stdout)
 	}()
 
 	go func() {
 		// Copy stderr to connection
-		io.Copy(serverConn, stderr)
+		io.Copy(ttyOutput{serverConn}, stderr)
 	}()
 
 	// Monitor process completion
@@ -471,10 +790,30 @@ func (d *DevBrowser) AttachToTask(ctx context.Context, task models.Task, cmd []s
 		serverConn.Close()
 	}()
 
-	return &DevContainerConnection{
+	return d.sessions.Open(&DevContainerConnection{
 		conn:        clientConn,
 		containerID: task.ContainerID,
-	}, nil
+	}, models.ExecSession{Task: &task, Cmd: shellCmd}), nil
+}
+
+// DetachSession implements core.ClusterBrowser
+func (d *DevBrowser) DetachSession(conn core.ContainerConnection) (models.ExecSession, error) {
+	return d.sessions.Detach(conn)
+}
+
+// ListDetachedSessions implements core.ClusterBrowser
+func (d *DevBrowser) ListDetachedSessions() []models.ExecSession {
+	return d.sessions.List()
+}
+
+// ReattachSession implements core.ClusterBrowser
+func (d *DevBrowser) ReattachSession(ctx context.Context, id int) (core.ContainerConnection, error) {
+	return d.sessions.Reattach(id)
+}
+
+// KillSession implements core.ClusterBrowser
+func (d *DevBrowser) KillSession(id int) error {
+	return d.sessions.Kill(id)
 }
 
 // Close implements core.ClusterBrowser
@@ -486,6 +825,8 @@ func (d *DevBrowser) Close() error {
 		}
 	}
 	d.conns = nil
+	d.closePortForwards()
+	d.sessions.CloseAll()
 	return nil
 }
 
@@ -514,6 +855,44 @@ func (d *DevBrowser) parseTaskStatus(status string) swarm.TaskState {
 	}
 }
 
+// containerPath maps a path of a task container to the local directory simulating its filesystem.
+// The directory is created with a few sample files on first use.
+func (d *DevBrowser) containerPath(task models.Task, containerPath string) (string, error) {
+	if task.ContainerID == "" {
+		return "", fmt.Errorf("task %s has no container", task.TaskID)
+	}
+	root := filepath.Join(os.TempDir(), "swarm-browser-dev", task.ContainerID)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		sampleFiles := map[string]string{
+			"app/README.md":      "Mock container filesystem for " + task.ContainerID + "\n",
+			"app/bin/start.sh":   "#!/bin/sh\necho starting\n",
+			"etc/hostname":       task.Node.Hostname + "\n",
+			"tmp/heapdump.hprof": "JAVA PROFILE 1.0.2\n",
+		}
+		for name, content := range sampleFiles {
+			filePath := filepath.Join(root, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+				return "", fmt.Errorf("failed to create mock filesystem: %w", err)
+			}
+			if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+				return "", fmt.Errorf("failed to create mock filesystem: %w", err)
+			}
+		}
+	}
+	// Cleaning the absolute path prevents escaping the container root
+	return filepath.Join(root, filepath.FromSlash(path.Clean("/"+containerPath))), nil
+}
+
+func fileInfo(filePath string, info os.FileInfo) models.FileInfo {
+	return models.FileInfo{
+		Name:    info.Name(),
+		Path:    path.Clean(filePath),
+		Size:    info.Size(),
+		Mode:    info.Mode(),
+		ModTime: info.ModTime(),
+	}
+}
+
 // DevContainerConnection wraps a net.Conn to implement core.ContainerConnection
 type DevContainerConnection struct {
 	conn        net.Conn
@@ -540,10 +919,27 @@ func (d *DevContainerConnection) Conn() net.Conn {
 	return d.conn
 }
 
+// Reader returns the underlying network connection, which has no buffered output
+func (d *DevContainerConnection) Reader() io.Reader {
+	return d.conn
+}
+
 // Close closes the underlying connection
 func (d *DevContainerConnection) Close() error {
 	if d.conn != nil {
 		return d.conn.Close()
 	}
 	return nil
-}
\ No newline at end of file
+}
+// ttyOutput translates line feeds into CRLF, as the TTY of a real container does,
+// since the local shell output comes from pipes.
+type ttyOutput struct {
+	w io.Writer
+}
+
+func (t ttyOutput) Write(p []byte) (int, error) {
+	if _, err := t.w.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -2,8 +2,14 @@ package devbrowser
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/mendes11/swarm-browser/internal/core"
 	"github.com/mendes11/swarm-browser/internal/core/models"
 	"github.com/moby/moby/api/types/swarm"
 )
@@ -106,6 +112,9 @@ func TestDevBrowser(t *testing.T) {
 		if services[0].RunningTasks != 2 {
 			t.Errorf("Expected 2 running tasks, got %d", services[0].RunningTasks)
 		}
+		if services[0].Mode != "replicated" {
+			t.Errorf("Expected mode 'replicated', got '%s'", services[0].Mode)
+		}
 	})
 
 	// Test ListTasks with auto-generated tasks
@@ -127,11 +136,13 @@ func TestDevBrowser(t *testing.T) {
 			t.Errorf("Expected 3 tasks, got %d", len(tasks))
 		}
 
-		// Check that we have 2 running and 1 pending/failed
+		// Check that we have 2 running and 1 pending/failed, which tells why
 		runningCount := 0
 		for _, task := range tasks {
 			if task.Status == swarm.TaskStateRunning {
 				runningCount++
+			} else if task.Err == "" {
+				t.Errorf("Expected an error for the %s task %s", task.Status, task.TaskID)
 			}
 		}
 		if runningCount != 2 {
@@ -171,6 +182,223 @@ func TestDevBrowser(t *testing.T) {
 		}
 	})
 
+	// Test ListNetworks
+	t.Run("ListNetworks", func(t *testing.T) {
+		networks, err := browser.ListNetworks(ctx)
+		if err != nil {
+			t.Fatalf("ListNetworks failed: %v", err)
+		}
+		if len(networks) != 2 {
+			t.Fatalf("Expected 2 networks, got %d", len(networks))
+		}
+		if !networks[0].Ingress {
+			t.Errorf("Expected first network to be the ingress network")
+		}
+		if networks[1].Name != "test-stack_default" {
+			t.Errorf("Expected network name 'test-stack_default', got '%s'", networks[1].Name)
+		}
+	})
+
+	// Test ListNetworkAttachments
+	t.Run("ListNetworkAttachments", func(t *testing.T) {
+		networks, err := browser.ListNetworks(ctx)
+		if err != nil {
+			t.Fatalf("ListNetworks failed: %v", err)
+		}
+		attachments, err := browser.ListNetworkAttachments(ctx, networks[1])
+		if err != nil {
+			t.Fatalf("ListNetworkAttachments failed: %v", err)
+		}
+		// 2 service VIPs + 2 running tasks of service1 + 1 running task of service2
+		if len(attachments) != 5 {
+			t.Errorf("Expected 5 attachments, got %d", len(attachments))
+		}
+		if attachments[0].TaskID != "" {
+			t.Errorf("Expected first attachment to be a service VIP, got task '%s'", attachments[0].TaskID)
+		}
+	})
+
+	// Test ListTaskMounts and ListNodeVolumes
+	t.Run("ListTaskMounts", func(t *testing.T) {
+		task := models.Task{
+			TaskID:      "custom-task-1",
+			ContainerID: "custom-container-1",
+			Node:        models.Node{Host: "test-node-1.local", Hostname: "test-node-1"},
+			Status:      swarm.TaskStateRunning,
+		}
+		mounts, err := browser.ListTaskMounts(ctx, task)
+		if err != nil {
+			t.Fatalf("ListTaskMounts failed: %v", err)
+		}
+		if len(mounts) != 2 {
+			t.Fatalf("Expected 2 mounts, got %d", len(mounts))
+		}
+
+		volumes, err := browser.ListNodeVolumes(ctx, task.Node)
+		if err != nil {
+			t.Fatalf("ListNodeVolumes failed: %v", err)
+		}
+		found := false
+		for _, volume := range volumes {
+			if volume.Name == mounts[0].Name {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected volume '%s' in node volumes", mounts[0].Name)
+		}
+	})
+
+	// Test StreamTaskStats
+	t.Run("StreamTaskStats", func(t *testing.T) {
+		service := models.Service{
+			ID:    "test-service-2",
+			Name:  "test-stack_service2",
+			Stack: models.Stack{Name: "test-stack"},
+		}
+		tasks, err := browser.ListTasks(ctx, service)
+		if err != nil {
+			t.Fatalf("ListTasks failed: %v", err)
+		}
+
+		streamCtx, cancel := context.WithCancel(ctx)
+		statsCh, err := browser.StreamTaskStats(streamCtx, tasks)
+		if err != nil {
+			t.Fatalf("StreamTaskStats failed: %v", err)
+		}
+		stats := <-statsCh
+		if stats.TaskID != "custom-task-1" {
+			t.Errorf("Expected stats for task 'custom-task-1', got '%s'", stats.TaskID)
+		}
+		if stats.MemoryUsage > stats.MemoryLimit {
+			t.Errorf("Expected memory usage below limit, got %d/%d", stats.MemoryUsage, stats.MemoryLimit)
+		}
+
+		cancel()
+		for range statsCh {
+			// Drain until the stream is closed
+		}
+	})
+
+	// Test port forwarding
+	t.Run("PortForward", func(t *testing.T) {
+		target, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		defer target.Close()
+		go func() {
+			conn, err := target.Accept()
+			if err == nil {
+				conn.Write([]byte("pong"))
+				conn.Close()
+			}
+		}()
+		free, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Failed to listen: %v", err)
+		}
+		localPort := free.Addr().(*net.TCPAddr).Port
+		free.Close()
+
+		task := models.Task{TaskID: "custom-task-1", ContainerID: "container-1"}
+		forward, err := browser.StartPortForward(ctx, task, localPort, target.Addr().(*net.TCPAddr).Port)
+		if err != nil {
+			t.Fatalf("StartPortForward failed: %v", err)
+		}
+		if forwards := browser.ListPortForwards(); len(forwards) != 1 {
+			t.Fatalf("Expected 1 port forward, got %d", len(forwards))
+		}
+
+		conn, err := net.Dial("tcp", forward.LocalAddr())
+		if err != nil {
+			t.Fatalf("Failed to dial forwarded port: %v", err)
+		}
+		reply, _ := io.ReadAll(conn)
+		conn.Close()
+		if string(reply) != "pong" {
+			t.Errorf("Expected 'pong' through the forward, got '%s'", reply)
+		}
+
+		if err := browser.StopPortForward(ctx, forward); err != nil {
+			t.Fatalf("StopPortForward failed: %v", err)
+		}
+		if forwards := browser.ListPortForwards(); len(forwards) != 0 {
+			t.Errorf("Expected no port forwards, got %d", len(forwards))
+		}
+	})
+
+	// Test running commands in all tasks
+	t.Run("ExecInTasks", func(t *testing.T) {
+		tasks := []models.Task{
+			{TaskID: "custom-task-1", ContainerID: "container-1"},
+			{TaskID: "custom-task-2", ContainerID: "container-2"},
+		}
+		results := core.ExecInTasks(ctx, browser, tasks, core.ShellCommand("echo $MOCK_TASK_ID; echo oops >&2; exit 3"))
+		if len(results) != 2 {
+			t.Fatalf("Expected 2 results, got %d", len(results))
+		}
+		for i, result := range results {
+			if result.Err != nil {
+				t.Fatalf("ExecInTasks failed for %s: %v", tasks[i].TaskID, result.Err)
+			}
+			if string(result.Stdout) != tasks[i].TaskID+"\n" {
+				t.Errorf("Expected stdout '%s', got '%s'", tasks[i].TaskID, result.Stdout)
+			}
+			if string(result.Stderr) != "oops\n" {
+				t.Errorf("Expected stderr 'oops', got '%s'", result.Stderr)
+			}
+			if result.ExitCode != 3 || result.Succeeded() {
+				t.Errorf("Expected exit code 3, got %d", result.ExitCode)
+			}
+		}
+	})
+
+	// Test piping stdin and cancelling a command
+	t.Run("RunInContainer", func(t *testing.T) {
+		task := models.Task{TaskID: "custom-task-1", ContainerID: "container-1"}
+		result, err := browser.RunInContainer(ctx, task, []string{"cat"}, strings.NewReader("piped input"))
+		if err != nil {
+			t.Fatalf("RunInContainer failed: %v", err)
+		}
+		if string(result.Stdout) != "piped input" || result.ExitCode != 0 {
+			t.Errorf("Expected the piped input and exit code 0, got '%s' (exit %d)", result.Stdout, result.ExitCode)
+		}
+
+		timeout, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		if _, err := browser.RunInContainer(timeout, task, []string{"sleep", "10"}, nil); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the command to be cancelled, got %v", err)
+		}
+	})
+
+	// Test debugging a task without a shell through a sidecar
+	t.Run("DebugSidecar", func(t *testing.T) {
+		task := models.Task{
+			TaskID:      "custom-task-1",
+			ContainerID: "container-1",
+			Status:      swarm.TaskStateRunning,
+			Image:       "gcr.io/distroless/static@sha256:abc",
+		}
+		probe := core.NewShellProbe()
+		if _, err := probe.Detect(ctx, browser, task); !errors.Is(err, core.ErrNoShell) {
+			t.Fatalf("Expected the distroless task to have no shell, got %v", err)
+		}
+		sidecar, err := browser.StartDebugSidecar(ctx, task, core.DefaultDebugImage)
+		if err != nil {
+			t.Fatalf("StartDebugSidecar failed: %v", err)
+		}
+		if sidecar.Node != task.Node || sidecar.ContainerID == task.ContainerID {
+			t.Errorf("Expected a sidecar container on the task node, got %+v", sidecar)
+		}
+		if _, err := probe.Detect(ctx, browser, sidecar); err != nil {
+			t.Errorf("Expected a shell in the sidecar, got %v", err)
+		}
+		if err := browser.RemoveDebugSidecar(ctx, sidecar); err != nil {
+			t.Errorf("RemoveDebugSidecar failed: %v", err)
+		}
+	})
+
 	// Test AttachToService
 	t.Run("AttachToService", func(t *testing.T) {
 		stack := models.Stack{Name: "test-stack"}
@@ -28,6 +28,13 @@ type ServiceConfig struct {
 	DesiredTasks uint64       `yaml:"desired_tasks"`
 	RunningTasks uint64       `yaml:"running_tasks"`
 	Tasks        []TaskConfig `yaml:"tasks,omitempty"`
+	// Image and Labels apply to the service and its task containers
+	Image  string            `yaml:"image,omitempty"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+	// Mode defaults to replicated, Ports are published as published:target/protocol
+	Mode        string   `yaml:"mode,omitempty"`
+	Ports       []string `yaml:"ports,omitempty"`
+	Constraints []string `yaml:"constraints,omitempty"`
 }
 
 // TaskConfig represents a mock task configuration
@@ -36,6 +43,9 @@ type TaskConfig struct {
 	ContainerID string `yaml:"container_id,omitempty"`
 	NodeName    string `yaml:"node"`  // Reference to node name in cluster
 	Status      string `yaml:"status"`
+	Image       string `yaml:"image,omitempty"`
+	// Error of a failed task, a generic one by default
+	Error string `yaml:"error,omitempty"`
 }
 
 // LoadConfig loads configuration from a file
@@ -0,0 +1,87 @@
+package devbrowser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/mendes11/swarm-browser/internal/core/models"
+	"github.com/moby/moby/api/types/swarm"
+)
+
+// RunInContainer implements core.ClusterBrowser by running the command as a local process
+func (d *DevBrowser) RunInContainer(ctx context.Context, task models.Task, cmd []string, stdin io.Reader) (models.ExecResult, error) {
+	if len(cmd) == 0 {
+		return models.ExecResult{}, fmt.Errorf("no command to run")
+	}
+	started := time.Now()
+	if isDistroless(task.Image) {
+		// Like Docker failing to start the missing program
+		return models.ExecResult{
+			Task:     task,
+			Cmd:      cmd,
+			Stdout:   fmt.Appendf(nil, "exec: %q: executable file not found in $PATH", cmd[0]),
+			ExitCode: 127,
+			Duration: time.Since(started),
+		}, nil
+	}
+	process := exec.CommandContext(ctx, cmd[0], cmd[1:]...)
+	process.Env = append(os.Environ(),
+		fmt.Sprintf("MOCK_TASK_ID=%s", task.TaskID),
+		fmt.Sprintf("MOCK_CONTAINER_ID=%s", task.ContainerID),
+		fmt.Sprintf("MOCK_NODE_HOST=%s", task.Node.Host),
+		fmt.Sprintf("MOCK_CLUSTER=%s", d.clusterName),
+		"MOCK_ENVIRONMENT=development",
+	)
+	var stdout, stderr bytes.Buffer
+	process.Stdin = stdin
+	process.Stdout = &stdout
+	process.Stderr = &stderr
+
+	exitCode := 0
+	if err := process.Run(); err != nil {
+		if ctx.Err() != nil {
+			return models.ExecResult{}, ctx.Err()
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return models.ExecResult{}, fmt.Errorf("failed to run command: %w", err)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+	return models.ExecResult{
+		Task:     task,
+		Cmd:      cmd,
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		ExitCode: exitCode,
+		Duration: time.Since(started),
+	}, nil
+}
+
+// isDistroless reports whether a mock task image provides no shell nor tools, like distroless images
+func isDistroless(image string) bool {
+	return strings.Contains(image, "distroless")
+}
+
+// StartDebugSidecar implements core.ClusterBrowser. The mock sidecar is a task running the local shell.
+func (d *DevBrowser) StartDebugSidecar(ctx context.Context, task models.Task, image string) (models.Task, error) {
+	if task.Status != swarm.TaskStateRunning {
+		return models.Task{}, fmt.Errorf("task %s is not running (status: %s)", task.TaskID, task.Status)
+	}
+	return core.DebugSidecarTask(task, "debug-"+task.ContainerID, image), nil
+}
+
+// RemoveDebugSidecar implements core.ClusterBrowser.
+func (d *DevBrowser) RemoveDebugSidecar(ctx context.Context, sidecar models.Task) error {
+	log.Printf("devbrowser.RemoveDebugSidecar: Removed mock sidecar %s\n", sidecar.ContainerID)
+	return nil
+}
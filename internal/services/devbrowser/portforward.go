@@ -0,0 +1,103 @@
+package devbrowser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/mendes11/swarm-browser/internal/core/models"
+)
+
+// devPortForward proxies a local port to a port of the local machine, standing in for the container
+type devPortForward struct {
+	forward  models.PortForward
+	listener net.Listener
+}
+
+// StartPortForward implements core.ClusterBrowser by proxying the local port to 127.0.0.1:remotePort
+func (d *DevBrowser) StartPortForward(ctx context.Context, task models.Task, localPort int, remotePort int) (models.PortForward, error) {
+	forward := models.PortForward{
+		Task:       task,
+		LocalPort:  localPort,
+		RemoteAddr: "127.0.0.1",
+		RemotePort: remotePort,
+		StartedAt:  time.Now(),
+	}
+
+	d.forwardsMu.Lock()
+	defer d.forwardsMu.Unlock()
+	if _, exists := d.forwards[localPort]; exists {
+		return models.PortForward{}, fmt.Errorf("local port %d is already forwarded", localPort)
+	}
+	listener, err := net.Listen("tcp", forward.LocalAddr())
+	if err != nil {
+		return models.PortForward{}, fmt.Errorf("failed to listen on %s: %w", forward.LocalAddr(), err)
+	}
+	if d.forwards == nil {
+		d.forwards = make(map[int]*devPortForward)
+	}
+	d.forwards[localPort] = &devPortForward{forward: forward, listener: listener}
+	go proxyConnections(listener, fmt.Sprintf("127.0.0.1:%d", remotePort))
+	return forward, nil
+}
+
+// StopPortForward implements core.ClusterBrowser
+func (d *DevBrowser) StopPortForward(ctx context.Context, forward models.PortForward) error {
+	d.forwardsMu.Lock()
+	defer d.forwardsMu.Unlock()
+	active, exists := d.forwards[forward.LocalPort]
+	if !exists {
+		return fmt.Errorf("local port %d is not forwarded", forward.LocalPort)
+	}
+	delete(d.forwards, forward.LocalPort)
+	return active.listener.Close()
+}
+
+// ListPortForwards implements core.ClusterBrowser
+func (d *DevBrowser) ListPortForwards() []models.PortForward {
+	d.forwardsMu.Lock()
+	defer d.forwardsMu.Unlock()
+	forwards := make([]models.PortForward, 0, len(d.forwards))
+	for _, active := range d.forwards {
+		forwards = append(forwards, active.forward)
+	}
+	slices.SortFunc(forwards, func(a, b models.PortForward) int {
+		return a.LocalPort - b.LocalPort
+	})
+	return forwards
+}
+
+// closePortForwards stops every active forward
+func (d *DevBrowser) closePortForwards() {
+	d.forwardsMu.Lock()
+	defer d.forwardsMu.Unlock()
+	for localPort, active := range d.forwards {
+		active.listener.Close()
+		delete(d.forwards, localPort)
+	}
+}
+
+// proxyConnections accepts connections until the listener is closed, piping each one to remoteAddr
+func proxyConnections(listener net.Listener, remoteAddr string) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			remote, err := net.Dial("tcp", remoteAddr)
+			if err != nil {
+				log.Printf("devbrowser: failed to dial %s: %v\n", remoteAddr, err)
+				return
+			}
+			defer remote.Close()
+			go io.Copy(remote, conn)
+			io.Copy(conn, remote)
+		}()
+	}
+}
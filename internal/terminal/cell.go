@@ -0,0 +1,110 @@
+package terminal
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Color is a cell foreground or background color: the terminal default,
+// an entry of the 256 color palette or a 24-bit RGB color.
+type Color uint32
+
+const (
+	DefaultColor Color = 0
+
+	paletteColorFlag Color = 1 << 24
+	rgbColorFlag     Color = 1 << 25
+)
+
+// PaletteColor returns the color at the index of the 256 color palette
+func PaletteColor(index uint8) Color {
+	return paletteColorFlag | Color(index)
+}
+
+// RGBColor returns a 24-bit color
+func RGBColor(r, g, b uint8) Color {
+	return rgbColorFlag | Color(r)<<16 | Color(g)<<8 | Color(b)
+}
+
+// sgr appends the SGR parameters selecting the color, with base 30 for foreground and 40 for background
+func (c Color) sgr(params []string, base int) []string {
+	switch {
+	case c&paletteColorFlag != 0:
+		index := int(c & 0xff)
+		switch {
+		case index < 8:
+			return append(params, strconv.Itoa(base+index))
+		case index < 16:
+			return append(params, strconv.Itoa(base+60+index-8))
+		default:
+			return append(params, strconv.Itoa(base+8), "5", strconv.Itoa(index))
+		}
+	case c&rgbColorFlag != 0:
+		return append(params, strconv.Itoa(base+8), "2",
+			strconv.Itoa(int(c>>16&0xff)), strconv.Itoa(int(c>>8&0xff)), strconv.Itoa(int(c&0xff)))
+	default:
+		return params
+	}
+}
+
+// Style holds the graphic rendition of a cell
+type Style struct {
+	Fg        Color
+	Bg        Color
+	Bold      bool
+	Faint     bool
+	Italic    bool
+	Underline bool
+	Blink     bool
+	Reverse   bool
+	Conceal   bool
+	Strike    bool
+}
+
+// SGR returns the escape sequence that selects the style, starting from a reset
+func (s Style) SGR() string {
+	params := []string{"0"}
+	for _, attr := range []struct {
+		enabled bool
+		param   string
+	}{
+		{s.Bold, "1"},
+		{s.Faint, "2"},
+		{s.Italic, "3"},
+		{s.Underline, "4"},
+		{s.Blink, "5"},
+		{s.Reverse, "7"},
+		{s.Conceal, "8"},
+		{s.Strike, "9"},
+	} {
+		if attr.enabled {
+			params = append(params, attr.param)
+		}
+	}
+	params = s.Fg.sgr(params, 30)
+	params = s.Bg.sgr(params, 40)
+	return "\x1b[" + strings.Join(params, ";") + "m"
+}
+
+// Cell is a single position of the screen.
+//
+// Wide characters take two cells: the first one holds the content with Width 2,
+// and the second one is a placeholder with Width 0.
+type Cell struct {
+	Content string // Empty for blank cells
+	Width   int
+	Style   Style
+}
+
+// blankCell returns an erased cell. Erasing keeps the current background color.
+func blankCell(style Style) Cell {
+	return Cell{Width: 1, Style: Style{Bg: style.Bg}}
+}
+
+// String returns the cell content, rendering blank cells as a space
+func (c Cell) String() string {
+	if c.Content == "" {
+		return " "
+	}
+	return c.Content
+}
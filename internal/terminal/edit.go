@@ -0,0 +1,210 @@
+package terminal
+
+// moveCursor moves the cursor to an absolute screen position, clamped to the screen
+func (t *Terminal) moveCursor(x, y int) {
+	t.cursor.x = min(max(x, 0), t.width-1)
+	t.cursor.y = min(max(y, 0), t.height-1)
+	t.cursor.pendingWrap = false
+}
+
+// setCursor moves the cursor to a position addressed by the application (CUP),
+// relative to the scroll region in origin mode
+func (t *Terminal) setCursor(x, y int) {
+	if t.cursor.originMode {
+		t.moveCursor(x, min(y+t.scrollTop, t.scrollBottom))
+		return
+	}
+	t.moveCursor(x, y)
+}
+
+// topLimit returns the highest row relative cursor movements reach
+func (t *Terminal) topLimit() int {
+	if t.cursor.y >= t.scrollTop {
+		return t.scrollTop
+	}
+	return 0
+}
+
+// bottomLimit returns the lowest row relative cursor movements reach
+func (t *Terminal) bottomLimit() int {
+	if t.cursor.y <= t.scrollBottom {
+		return t.scrollBottom
+	}
+	return t.height - 1
+}
+
+func (t *Terminal) tab(n int) {
+	x := t.cursor.x
+	for ; n > 0 && x < t.width-1; n-- {
+		for x++; x < t.width-1 && !t.tabStops[x]; x++ {
+		}
+	}
+	t.moveCursor(x, t.cursor.y)
+}
+
+func (t *Terminal) backTab(n int) {
+	x := t.cursor.x
+	for ; n > 0 && x > 0; n-- {
+		for x--; x > 0 && !t.tabStops[x]; x-- {
+		}
+	}
+	t.moveCursor(x, t.cursor.y)
+}
+
+// linefeed moves the cursor down, scrolling when it is at the bottom of the scroll region
+func (t *Terminal) linefeed() {
+	switch {
+	case t.cursor.y == t.scrollBottom:
+		t.scrollUp(1)
+	case t.cursor.y < t.height-1:
+		t.cursor.y++
+	}
+}
+
+// reverseIndex moves the cursor up, scrolling when it is at the top of the scroll region
+func (t *Terminal) reverseIndex() {
+	switch {
+	case t.cursor.y == t.scrollTop:
+		t.scrollDown(1)
+	case t.cursor.y > 0:
+		t.cursor.y--
+	}
+	t.cursor.pendingWrap = false
+}
+
+// scrollUp moves the lines of the scroll region up, adding blank lines at its bottom.
+// Lines leaving the top of the main screen go to the scrollback.
+func (t *Terminal) scrollUp(n int) {
+	if t.scrollTop == 0 && !t.AltScreen() {
+		n = min(n, t.scrollBottom+1)
+		for _, line := range t.screen.lines[:n] {
+			t.scrollback.push(line)
+		}
+		t.scrolled += n
+	}
+	t.shiftLinesUp(t.scrollTop, n)
+}
+
+// scrollDown moves the lines of the scroll region down, adding blank lines at its top
+func (t *Terminal) scrollDown(n int) {
+	t.shiftLinesDown(t.scrollTop, n)
+}
+
+// insertLines inserts blank lines at the cursor line, within the scroll region
+func (t *Terminal) insertLines(n int) {
+	if t.cursor.y < t.scrollTop || t.cursor.y > t.scrollBottom {
+		return
+	}
+	t.shiftLinesDown(t.cursor.y, n)
+	t.cursor.x = 0
+	t.cursor.pendingWrap = false
+}
+
+// deleteLines removes lines at the cursor line, within the scroll region
+func (t *Terminal) deleteLines(n int) {
+	if t.cursor.y < t.scrollTop || t.cursor.y > t.scrollBottom {
+		return
+	}
+	t.shiftLinesUp(t.cursor.y, n)
+	t.cursor.x = 0
+	t.cursor.pendingWrap = false
+}
+
+// shiftLinesUp removes n lines at top, shifting the lines below it up to the scroll region bottom
+func (t *Terminal) shiftLinesUp(top, n int) {
+	lines := t.screen.lines
+	n = min(n, t.scrollBottom-top+1)
+	copy(lines[top:t.scrollBottom+1], lines[top+n:t.scrollBottom+1])
+	for y := t.scrollBottom - n + 1; y <= t.scrollBottom; y++ {
+		lines[y] = blankLine(t.width, t.cursor.style)
+	}
+}
+
+// shiftLinesDown inserts n blank lines at top, pushing the lines below it out of the scroll region bottom
+func (t *Terminal) shiftLinesDown(top, n int) {
+	lines := t.screen.lines
+	n = min(n, t.scrollBottom-top+1)
+	copy(lines[top+n:t.scrollBottom+1], lines[top:t.scrollBottom+1-n])
+	for y := top; y < top+n; y++ {
+		lines[y] = blankLine(t.width, t.cursor.style)
+	}
+}
+
+// insertCells inserts blank cells at the cursor, shifting the rest of the line right
+func (t *Terminal) insertCells(n int) {
+	line := t.screen.lines[t.cursor.y]
+	x := t.cursor.x
+	n = min(n, t.width-x)
+	t.clearWide(line, x)
+	copy(line[x+n:], line[x:t.width-n])
+	for i := x; i < x+n; i++ {
+		line[i] = blankCell(t.cursor.style)
+	}
+	t.cursor.pendingWrap = false
+}
+
+// deleteCells removes cells at the cursor, shifting the rest of the line left
+func (t *Terminal) deleteCells(n int) {
+	line := t.screen.lines[t.cursor.y]
+	x := t.cursor.x
+	n = min(n, t.width-x)
+	t.clearWide(line, x)
+	copy(line[x:], line[x+n:])
+	for i := t.width - n; i < t.width; i++ {
+		line[i] = blankCell(t.cursor.style)
+	}
+	t.cursor.pendingWrap = false
+}
+
+// eraseDisplay implements ED: 0 erases below the cursor, 1 above it and 2 the whole screen
+func (t *Terminal) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		t.eraseLine(0)
+		for y := t.cursor.y + 1; y < t.height; y++ {
+			t.screen.lines[y] = blankLine(t.width, t.cursor.style)
+		}
+	case 1:
+		t.eraseLine(1)
+		for y := 0; y < t.cursor.y; y++ {
+			t.screen.lines[y] = blankLine(t.width, t.cursor.style)
+		}
+	case 2:
+		for y := range t.screen.lines {
+			t.screen.lines[y] = blankLine(t.width, t.cursor.style)
+		}
+	case 3:
+		// Erase the saved lines, as done by clear
+		t.scrollback.clear()
+	}
+}
+
+// eraseLine implements EL: 0 erases right of the cursor, 1 left of it and 2 the whole line
+func (t *Terminal) eraseLine(mode int) {
+	line := t.screen.lines[t.cursor.y]
+	from, to := 0, t.width
+	switch mode {
+	case 0:
+		from = t.cursor.x
+	case 1:
+		to = t.cursor.x + 1
+	case 2:
+	default:
+		return
+	}
+	for x := from; x < to; x++ {
+		line[x] = blankCell(t.cursor.style)
+	}
+	t.cursor.pendingWrap = false
+}
+
+// saveCursor implements DECSC, saving the cursor of the active screen
+func (t *Terminal) saveCursor() {
+	t.screen.saved = t.cursor
+}
+
+// restoreCursor implements DECRC
+func (t *Terminal) restoreCursor() {
+	t.cursor = t.screen.saved
+	t.moveCursor(t.cursor.x, t.cursor.y)
+}
@@ -0,0 +1,480 @@
+package terminal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mattn/go-runewidth"
+)
+
+// decSpecialGraphics maps the DEC special graphics set to the line drawing characters
+var decSpecialGraphics = map[rune]rune{
+	'`': '◆', 'a': '▒', 'f': '°', 'g': '±', 'j': '┘', 'k': '┐', 'l': '┌', 'm': '└',
+	'n': '┼', 'o': '⎺', 'p': '⎻', 'q': '─', 'r': '⎼', 's': '⎽', 't': '├', 'u': '┤',
+	'v': '┴', 'w': '┬', 'x': '│', 'y': '≤', 'z': '≥', '{': 'π', '|': '≠', '}': '£',
+	'~': '·',
+}
+
+func (t *Terminal) print(r rune) {
+	charset := 0
+	if t.shiftOut {
+		charset = 1
+	}
+	if t.cursor.charsets[charset] {
+		if graphic, exists := decSpecialGraphics[r]; exists {
+			r = graphic
+		}
+	}
+
+	width := runewidth.RuneWidth(r)
+	if width == 0 {
+		// Combining characters join the previously printed cell
+		x := t.cursor.x
+		if !t.cursor.pendingWrap {
+			x--
+		}
+		line := t.screen.lines[t.cursor.y]
+		for x > 0 && line[x].Width == 0 {
+			x--
+		}
+		if x >= 0 && line[x].Content != "" {
+			line[x].Content += string(r)
+		}
+		return
+	}
+	t.lastRune = r
+
+	if t.cursor.pendingWrap && t.autowrap {
+		t.cursor.x = 0
+		t.linefeed()
+	}
+	t.cursor.pendingWrap = false
+	if width == 2 && t.cursor.x == t.width-1 {
+		if !t.autowrap {
+			return
+		}
+		t.screen.lines[t.cursor.y][t.cursor.x] = blankCell(t.cursor.style)
+		t.cursor.x = 0
+		t.linefeed()
+	}
+	if t.insertMode {
+		t.insertCells(width)
+	}
+
+	line := t.screen.lines[t.cursor.y]
+	t.clearWide(line, t.cursor.x)
+	if width == 2 && t.width > 1 {
+		t.clearWide(line, t.cursor.x+1)
+	}
+	line[t.cursor.x] = Cell{Content: string(r), Width: width, Style: t.cursor.style}
+	if width == 2 && t.cursor.x+1 < t.width {
+		line[t.cursor.x+1] = Cell{Width: 0, Style: t.cursor.style}
+	}
+
+	if t.cursor.x+width >= t.width {
+		t.cursor.x = t.width - 1
+		t.cursor.pendingWrap = true
+	} else {
+		t.cursor.x += width
+	}
+}
+
+// clearWide blanks the other half of a wide character about to be overwritten at x
+func (t *Terminal) clearWide(line []Cell, x int) {
+	if x < 0 || x >= len(line) {
+		return
+	}
+	switch line[x].Width {
+	case 0:
+		if x > 0 {
+			line[x-1] = blankCell(line[x-1].Style)
+		}
+	case 2:
+		if x+1 < len(line) {
+			line[x+1] = blankCell(line[x+1].Style)
+		}
+	}
+}
+
+func (t *Terminal) execute(b byte) {
+	switch b {
+	case ansi.BS:
+		t.moveCursor(t.cursor.x-1, t.cursor.y)
+	case ansi.HT:
+		t.tab(1)
+	case ansi.LF, ansi.VT, ansi.FF:
+		t.linefeed()
+		if t.newlineMode {
+			t.cursor.x = 0
+		}
+		t.cursor.pendingWrap = false
+	case ansi.CR:
+		t.cursor.x = 0
+		t.cursor.pendingWrap = false
+	case ansi.SO:
+		t.shiftOut = true
+	case ansi.SI:
+		t.shiftOut = false
+	}
+}
+
+func (t *Terminal) handleEsc(cmd ansi.Cmd) {
+	switch cmd.Intermediate() {
+	case '(', ')':
+		charset := 0
+		if cmd.Intermediate() == ')' {
+			charset = 1
+		}
+		t.cursor.charsets[charset] = cmd.Final() == '0'
+		return
+	case '#':
+		if cmd.Final() == '8' {
+			// DECALN: fill the screen with E's
+			for y := range t.screen.lines {
+				for x := range t.screen.lines[y] {
+					t.screen.lines[y][x] = Cell{Content: "E", Width: 1}
+				}
+			}
+		}
+		return
+	case 0:
+	default:
+		return
+	}
+
+	switch cmd.Final() {
+	case '7':
+		t.saveCursor()
+	case '8':
+		t.restoreCursor()
+	case 'D':
+		t.linefeed()
+	case 'E':
+		t.linefeed()
+		t.cursor.x = 0
+	case 'H':
+		t.tabStops[t.cursor.x] = true
+	case 'M':
+		t.reverseIndex()
+	case 'c':
+		t.reset(t.width, t.height)
+	}
+}
+
+func (t *Terminal) handleOsc(cmd int, data []byte) {
+	// Window title
+	if cmd == 0 || cmd == 2 {
+		_, title, _ := strings.Cut(string(data), ";")
+		t.title = title
+	}
+}
+
+func (t *Terminal) handleCsi(cmd ansi.Cmd, params ansi.Params) {
+	// count returns the parameter at i, where missing and zero values mean 1
+	count := func(i int) int {
+		n, _, _ := params.Param(i, 1)
+		return max(n, 1)
+	}
+	param := func(i, def int) int {
+		n, _, _ := params.Param(i, def)
+		return n
+	}
+
+	switch cmd.Prefix() {
+	case '?':
+		switch cmd.Final() {
+		case 'h':
+			t.setPrivateModes(params, true)
+		case 'l':
+			t.setPrivateModes(params, false)
+		}
+		return
+	case '>':
+		if cmd.Final() == 'c' {
+			// Secondary device attributes: VT220
+			t.reply("\x1b[>1;10;0c")
+		}
+		return
+	case 0:
+	default:
+		return
+	}
+	if cmd.Intermediate() != 0 {
+		// e.g. DECSCUSR (cursor shape), which doesn't affect the screen contents
+		return
+	}
+
+	switch cmd.Final() {
+	case '@':
+		t.insertCells(count(0))
+	case 'A':
+		t.moveCursor(t.cursor.x, max(t.cursor.y-count(0), t.topLimit()))
+	case 'B', 'e':
+		t.moveCursor(t.cursor.x, min(t.cursor.y+count(0), t.bottomLimit()))
+	case 'C', 'a':
+		t.moveCursor(t.cursor.x+count(0), t.cursor.y)
+	case 'D':
+		t.moveCursor(t.cursor.x-count(0), t.cursor.y)
+	case 'E':
+		t.moveCursor(0, min(t.cursor.y+count(0), t.bottomLimit()))
+	case 'F':
+		t.moveCursor(0, max(t.cursor.y-count(0), t.topLimit()))
+	case 'G', '`':
+		t.moveCursor(count(0)-1, t.cursor.y)
+	case 'H', 'f':
+		t.setCursor(count(1)-1, count(0)-1)
+	case 'I':
+		t.tab(count(0))
+	case 'J':
+		t.eraseDisplay(param(0, 0))
+	case 'K':
+		t.eraseLine(param(0, 0))
+	case 'L':
+		t.insertLines(count(0))
+	case 'M':
+		t.deleteLines(count(0))
+	case 'P':
+		t.deleteCells(count(0))
+	case 'S':
+		t.scrollUp(count(0))
+	case 'T':
+		t.scrollDown(count(0))
+	case 'X':
+		line := t.screen.lines[t.cursor.y]
+		for x := t.cursor.x; x < min(t.cursor.x+count(0), t.width); x++ {
+			line[x] = blankCell(t.cursor.style)
+		}
+		t.cursor.pendingWrap = false
+	case 'Z':
+		t.backTab(count(0))
+	case 'b':
+		if t.lastRune != 0 {
+			for range count(0) {
+				t.print(t.lastRune)
+			}
+		}
+	case 'c':
+		// Primary device attributes: VT100 with advanced video option
+		t.reply("\x1b[?1;2c")
+	case 'd':
+		t.setCursor(t.cursor.x, count(0)-1)
+	case 'g':
+		switch param(0, 0) {
+		case 0:
+			t.tabStops[t.cursor.x] = false
+		case 3:
+			clear(t.tabStops)
+		}
+	case 'h', 'l':
+		enabled := cmd.Final() == 'h'
+		params.ForEach(0, func(_, mode int, _ bool) {
+			switch mode {
+			case 4:
+				t.insertMode = enabled
+			case 20:
+				t.newlineMode = enabled
+			}
+		})
+	case 'm':
+		t.setGraphicRendition(params)
+	case 'n':
+		switch param(0, 0) {
+		case 5:
+			t.reply("\x1b[0n")
+		case 6:
+			y := t.cursor.y
+			if t.cursor.originMode {
+				y -= t.scrollTop
+			}
+			t.reply(fmt.Sprintf("\x1b[%d;%dR", y+1, t.cursor.x+1))
+		}
+	case 'r':
+		top, bottom := count(0)-1, param(1, t.height)-1
+		if bottom <= 0 || bottom >= t.height {
+			bottom = t.height - 1
+		}
+		if top < bottom {
+			t.scrollTop, t.scrollBottom = top, bottom
+			t.setCursor(0, 0)
+		}
+	case 's':
+		t.saveCursor()
+	case 'u':
+		t.restoreCursor()
+	}
+}
+
+func (t *Terminal) setPrivateModes(params ansi.Params, enabled bool) {
+	params.ForEach(0, func(_, mode int, _ bool) {
+		switch mode {
+		case 1:
+			t.appCursorKeys = enabled
+		case 6:
+			t.cursor.originMode = enabled
+			t.setCursor(0, 0)
+		case 7:
+			t.autowrap = enabled
+		case 25:
+			t.cursorVisible = enabled
+		case 47, 1047:
+			t.switchScreen(enabled)
+		case 1049:
+			if enabled {
+				t.saveCursor()
+				t.switchScreen(true)
+			} else {
+				t.switchScreen(false)
+				t.restoreCursor()
+			}
+		case 1000:
+			t.setMouseMode(MouseClick, enabled)
+		case 1002:
+			t.setMouseMode(MouseDrag, enabled)
+		case 1003:
+			t.setMouseMode(MouseMotion, enabled)
+		case 1006:
+			t.mouseSGR = enabled
+		case 2004:
+			t.bracketedPaste = enabled
+		}
+	})
+}
+
+func (t *Terminal) setMouseMode(mode MouseMode, enabled bool) {
+	if enabled {
+		t.mouseMode = mode
+	} else if t.mouseMode == mode {
+		t.mouseMode = MouseNone
+	}
+}
+
+// switchScreen switches to the alternate screen, clearing it, or back to the main one
+func (t *Terminal) switchScreen(alt bool) {
+	if alt == t.AltScreen() {
+		return
+	}
+	if alt {
+		t.alt = newScreen(t.width, t.height)
+		t.screen = t.alt
+	} else {
+		t.screen = t.main
+	}
+}
+
+func (t *Terminal) reply(response string) {
+	t.replies = append(t.replies, response...)
+}
+
+// setGraphicRendition applies SGR parameters to the cursor style
+func (t *Terminal) setGraphicRendition(params ansi.Params) {
+	style := &t.cursor.style
+	if len(params) == 0 {
+		*style = Style{}
+		return
+	}
+	for i := 0; i < len(params); i++ {
+		code := params[i].Param(0)
+		switch {
+		case code == 0:
+			*style = Style{}
+		case code == 1:
+			style.Bold = true
+		case code == 2:
+			style.Faint = true
+		case code == 3:
+			style.Italic = true
+		case code == 4 || code == 21:
+			// Underline styles (4:x) are all rendered as a single underline
+			style.Underline = true
+			if params[i].HasMore() {
+				i++
+				style.Underline = params[i].Param(1) != 0
+			}
+		case code == 5 || code == 6:
+			style.Blink = true
+		case code == 7:
+			style.Reverse = true
+		case code == 8:
+			style.Conceal = true
+		case code == 9:
+			style.Strike = true
+		case code == 22:
+			style.Bold, style.Faint = false, false
+		case code == 23:
+			style.Italic = false
+		case code == 24:
+			style.Underline = false
+		case code == 25:
+			style.Blink = false
+		case code == 27:
+			style.Reverse = false
+		case code == 28:
+			style.Conceal = false
+		case code == 29:
+			style.Strike = false
+		case code >= 30 && code <= 37:
+			style.Fg = PaletteColor(uint8(code - 30))
+		case code == 38:
+			var color Color
+			color, i = extendedColor(params, i)
+			style.Fg = color
+		case code == 39:
+			style.Fg = DefaultColor
+		case code >= 40 && code <= 47:
+			style.Bg = PaletteColor(uint8(code - 40))
+		case code == 48:
+			var color Color
+			color, i = extendedColor(params, i)
+			style.Bg = color
+		case code == 49:
+			style.Bg = DefaultColor
+		case code >= 90 && code <= 97:
+			style.Fg = PaletteColor(uint8(code - 90 + 8))
+		case code >= 100 && code <= 107:
+			style.Bg = PaletteColor(uint8(code - 100 + 8))
+		}
+	}
+}
+
+// extendedColor parses the 256 color or RGB color following a 38 or 48 parameter at i,
+// either separated by semicolons (38;5;n) or colons (38:5:n, 38:2::r:g:b).
+// It returns the color and the index of the last parameter it consumed.
+func extendedColor(params ansi.Params, i int) (Color, int) {
+	if params[i].HasMore() {
+		// Colon separated sub-parameters are grouped until one without HasMore
+		group := []int{}
+		for j := i + 1; j < len(params); j++ {
+			group = append(group, params[j].Param(0))
+			if !params[j].HasMore() {
+				i = j
+				break
+			}
+		}
+		switch {
+		case len(group) >= 2 && group[0] == 5:
+			return PaletteColor(uint8(group[1])), i
+		case len(group) == 4 && group[0] == 2:
+			return RGBColor(uint8(group[1]), uint8(group[2]), uint8(group[3])), i
+		case len(group) >= 5 && group[0] == 2:
+			// Includes the color space ID
+			return RGBColor(uint8(group[2]), uint8(group[3]), uint8(group[4])), i
+		}
+		return DefaultColor, i
+	}
+
+	if i+1 >= len(params) {
+		return DefaultColor, i
+	}
+	switch params[i+1].Param(0) {
+	case 5:
+		if i+2 < len(params) {
+			return PaletteColor(uint8(params[i+2].Param(0))), i + 2
+		}
+	case 2:
+		if i+4 < len(params) {
+			return RGBColor(uint8(params[i+2].Param(0)), uint8(params[i+3].Param(0)), uint8(params[i+4].Param(0))), i + 4
+		}
+	}
+	return DefaultColor, len(params) - 1
+}
@@ -0,0 +1,167 @@
+package terminal
+
+import "strings"
+
+// DefaultScrollback is the number of lines kept once they scroll off the main screen
+const DefaultScrollback = 5000
+
+// scrollback is a ring buffer of the lines scrolled off the top of the main screen.
+// Once full, each new line overwrites the oldest one.
+type scrollback struct {
+	lines [][]Cell
+	start int // Index of the oldest line
+	count int
+}
+
+func newScrollback(size int) *scrollback {
+	return &scrollback{lines: make([][]Cell, max(size, 0))}
+}
+
+// push adds a line, dropping the oldest one when the buffer is full
+func (s *scrollback) push(line []Cell) {
+	if len(s.lines) == 0 {
+		return
+	}
+	line = trimLine(line)
+	if s.count < len(s.lines) {
+		s.lines[(s.start+s.count)%len(s.lines)] = line
+		s.count++
+		return
+	}
+	s.lines[s.start] = line
+	s.start = (s.start + 1) % len(s.lines)
+}
+
+// line returns the i-th line, from the oldest one
+func (s *scrollback) line(i int) []Cell {
+	return s.lines[(s.start+i)%len(s.lines)]
+}
+
+func (s *scrollback) clear() {
+	clear(s.lines)
+	s.start, s.count = 0, 0
+}
+
+// trimLine drops the unstyled blank cells at the end of a line, which most lines are made of
+func trimLine(line []Cell) []Cell {
+	end := len(line)
+	for end > 0 && line[end-1] == blankCell(Style{}) {
+		end--
+	}
+	return append([]Cell(nil), line[:end]...)
+}
+
+// SetScrollback changes the number of lines kept in the scrollback, dropping its contents
+func (t *Terminal) SetScrollback(lines int) {
+	t.scrollback = newScrollback(lines)
+}
+
+// FirstRow returns the oldest row still available, in the row numbering of Row.
+// It equals ScreenTop on the alternate screen, which has no scrollback.
+func (t *Terminal) FirstRow() int {
+	if t.AltScreen() {
+		return t.ScreenTop()
+	}
+	return t.scrolled - t.scrollback.count
+}
+
+// ScreenTop returns the row of the first screen line. Rows are numbered from the first line
+// ever displayed, so a row keeps its number while it moves to the scrollback.
+func (t *Terminal) ScreenTop() int {
+	return t.scrolled
+}
+
+// Row returns the cells of a row, which are fewer than the terminal width for scrollback lines
+func (t *Terminal) Row(row int) []Cell {
+	switch {
+	case row >= t.ScreenTop() && row < t.ScreenTop()+t.height:
+		return t.screen.lines[row-t.ScreenTop()]
+	case row >= t.FirstRow() && row < t.ScreenTop():
+		return t.scrollback.line(row - t.FirstRow())
+	default:
+		return nil
+	}
+}
+
+// RowText returns the text of a row, without styles and trailing blanks
+func (t *Terminal) RowText(row int) string {
+	return cellsText(t.Row(row), 0, -1)
+}
+
+// Text returns the text between two positions, inclusive, with a line break between rows
+func (t *Terminal) Text(fromX, fromRow, toX, toRow int) string {
+	if fromRow > toRow || fromRow == toRow && fromX > toX {
+		fromX, fromRow, toX, toRow = toX, toRow, fromX, fromRow
+	}
+	lines := make([]string, 0, toRow-fromRow+1)
+	for row := fromRow; row <= toRow; row++ {
+		from, to := 0, -1
+		if row == fromRow {
+			from = fromX
+		}
+		if row == toRow {
+			to = toX + 1
+		}
+		lines = append(lines, cellsText(t.Row(row), from, to))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cellsText returns the text of the cells from one index up to another, -1 meaning the end
+func cellsText(cells []Cell, from, to int) string {
+	if to < 0 || to > len(cells) {
+		to = len(cells)
+	}
+	var sb strings.Builder
+	for x := max(from, 0); x < to; x++ {
+		if cells[x].Width == 0 {
+			continue
+		}
+		sb.WriteString(cells[x].String())
+	}
+	return strings.TrimRight(sb.String(), " ")
+}
+
+// RenderRows returns height rows starting at top as styled lines, without the cursor.
+// decorate, if set, can change the style of each cell, e.g. to highlight a selection.
+func (t *Terminal) RenderRows(top int, decorate func(x, row int, style Style) Style) string {
+	lines := make([]string, t.height)
+	for y := range lines {
+		lines[y] = t.renderLine(t.Row(top+y), func(x int, style Style) Style {
+			if decorate == nil {
+				return style
+			}
+			return decorate(x, top+y, style)
+		})
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderLine renders a line padded to the terminal width, styling each cell with decorate
+func (t *Terminal) renderLine(line []Cell, decorate func(x int, style Style) Style) string {
+	var sb strings.Builder
+	current := Style{}
+	for x := range t.width {
+		cell := blankCell(Style{})
+		if x < len(line) {
+			cell = line[x]
+		}
+		if cell.Width == 0 {
+			continue
+		}
+		// Don't render half of a wide character cut by a narrower terminal
+		if cell.Width == 2 && x == t.width-1 {
+			cell = blankCell(cell.Style)
+		}
+		style := decorate(x, cell.Style)
+		if style != current {
+			sb.WriteString(style.SGR())
+			current = style
+		}
+		sb.WriteString(cell.String())
+	}
+	if current != (Style{}) {
+		sb.WriteString("\x1b[0m")
+	}
+	return sb.String()
+}
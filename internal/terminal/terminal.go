@@ -0,0 +1,277 @@
+// Package terminal implements a VT100/xterm screen buffer emulator.
+//
+// Container sessions write their output into a Terminal, which keeps the screen
+// contents so the TUI can render them inside a pane instead of handing the whole
+// terminal over to the container.
+package terminal
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// MouseMode is the mouse tracking mode requested by the application running in the terminal
+type MouseMode int
+
+const (
+	MouseNone   MouseMode = iota
+	MouseClick            // Button presses and releases (1000)
+	MouseDrag             // Presses, releases and motion while a button is held (1002)
+	MouseMotion           // Every mouse event (1003)
+)
+
+// cursor holds the cursor position along with the state saved by DECSC
+type cursor struct {
+	x, y  int
+	style Style
+	// pendingWrap is set after printing on the last column, wrapping on the next printed character
+	pendingWrap bool
+	originMode  bool
+	charsets    [2]bool // Whether G0 / G1 use the DEC special graphics set
+}
+
+// screen is a grid of cells. The terminal has a main and an alternate screen.
+type screen struct {
+	lines [][]Cell
+	saved cursor
+}
+
+func newScreen(width, height int) *screen {
+	s := &screen{lines: make([][]Cell, height)}
+	for y := range s.lines {
+		s.lines[y] = blankLine(width, Style{})
+	}
+	return s
+}
+
+func blankLine(width int, style Style) []Cell {
+	line := make([]Cell, width)
+	for x := range line {
+		line[x] = blankCell(style)
+	}
+	return line
+}
+
+// Terminal emulates a VT100/xterm compatible terminal
+type Terminal struct {
+	width, height int
+
+	main, alt *screen
+	screen    *screen // Either main or alt
+
+	// Lines scrolled off the top of the main screen, and their total count
+	scrollback *scrollback
+	scrolled   int
+
+	cursor cursor
+	// Scroll region rows, inclusive
+	scrollTop, scrollBottom int
+	tabStops                []bool
+
+	autowrap      bool
+	insertMode    bool
+	newlineMode   bool
+	cursorVisible bool
+	shiftOut      bool // Whether G1 is the active charset
+
+	appCursorKeys  bool
+	bracketedPaste bool
+	mouseMode      MouseMode
+	mouseSGR       bool
+
+	title    string
+	lastRune rune
+	replies  []byte
+
+	parser *ansi.Parser
+}
+
+// New creates a terminal with the given size, in columns and rows
+func New(width, height int) *Terminal {
+	t := &Terminal{}
+	t.parser = ansi.NewParser()
+	t.parser.SetHandler(ansi.Handler{
+		Print:     t.print,
+		Execute:   t.execute,
+		HandleCsi: t.handleCsi,
+		HandleEsc: t.handleEsc,
+		HandleOsc: t.handleOsc,
+	})
+	t.scrollback = newScrollback(DefaultScrollback)
+	t.reset(max(width, 1), max(height, 1))
+	return t
+}
+
+// reset brings the terminal back to its initial state (RIS)
+func (t *Terminal) reset(width, height int) {
+	t.width, t.height = width, height
+	t.main = newScreen(width, height)
+	t.alt = newScreen(width, height)
+	t.screen = t.main
+	t.cursor = cursor{}
+	t.scrollTop, t.scrollBottom = 0, height-1
+	t.tabStops = defaultTabStops(width)
+	t.autowrap = true
+	t.insertMode = false
+	t.newlineMode = false
+	t.cursorVisible = true
+	t.shiftOut = false
+	t.appCursorKeys = false
+	t.bracketedPaste = false
+	t.mouseMode = MouseNone
+	t.mouseSGR = false
+	t.title = ""
+	t.lastRune = 0
+}
+
+func defaultTabStops(width int) []bool {
+	stops := make([]bool, width)
+	for x := 8; x < width; x += 8 {
+		stops[x] = true
+	}
+	return stops
+}
+
+// Write feeds output of the application into the terminal
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.parser.Parse(p)
+	return len(p), nil
+}
+
+// Size returns the terminal size, in columns and rows
+func (t *Terminal) Size() (width, height int) {
+	return t.width, t.height
+}
+
+// Resize changes the terminal size, keeping the cursor line visible
+func (t *Terminal) Resize(width, height int) {
+	width, height = max(width, 1), max(height, 1)
+	if width == t.width && height == t.height {
+		return
+	}
+	// Drop lines from the top when the cursor would fall below the new height
+	drop := max(t.cursor.y-(height-1), 0)
+	for _, line := range t.main.lines[:min(drop, len(t.main.lines))] {
+		t.scrollback.push(line)
+	}
+	t.scrolled += drop
+	for _, s := range []*screen{t.main, t.alt} {
+		lines := s.lines[min(drop, len(s.lines)):]
+		resized := make([][]Cell, height)
+		for y := range resized {
+			if y < len(lines) {
+				resized[y] = resizeLine(lines[y], width)
+			} else {
+				resized[y] = blankLine(width, Style{})
+			}
+		}
+		s.lines = resized
+		s.saved.x = min(s.saved.x, width-1)
+		s.saved.y = min(max(s.saved.y-drop, 0), height-1)
+	}
+
+	stops := defaultTabStops(width)
+	copy(stops, t.tabStops)
+	t.tabStops = stops
+
+	t.width, t.height = width, height
+	t.scrollTop, t.scrollBottom = 0, height-1
+	t.cursor.y -= drop
+	t.cursor.x = min(t.cursor.x, width-1)
+	t.cursor.pendingWrap = false
+}
+
+func resizeLine(line []Cell, width int) []Cell {
+	if len(line) >= width {
+		line = line[:width]
+		// Don't keep half of a wide character
+		if line[width-1].Width == 2 {
+			line[width-1] = blankCell(line[width-1].Style)
+		}
+		return line
+	}
+	return append(line, blankLine(width-len(line), Style{})...)
+}
+
+// Cursor returns the cursor position
+func (t *Terminal) Cursor() (x, y int) {
+	return t.cursor.x, t.cursor.y
+}
+
+// CursorVisible reports whether the application shows the cursor (DECTCEM)
+func (t *Terminal) CursorVisible() bool {
+	return t.cursorVisible
+}
+
+// Title returns the window title set by the application
+func (t *Terminal) Title() string {
+	return t.title
+}
+
+// AltScreen reports whether the alternate screen is active, as used by full-screen applications
+func (t *Terminal) AltScreen() bool {
+	return t.screen == t.alt
+}
+
+// AppCursorKeys reports whether cursor keys must be sent in application mode (DECCKM)
+func (t *Terminal) AppCursorKeys() bool {
+	return t.appCursorKeys
+}
+
+// BracketedPaste reports whether pasted text must be wrapped in bracketed paste sequences
+func (t *Terminal) BracketedPaste() bool {
+	return t.bracketedPaste
+}
+
+// MouseMode returns the mouse tracking mode requested by the application
+func (t *Terminal) MouseMode() MouseMode {
+	return t.mouseMode
+}
+
+// MouseSGR reports whether mouse events must be encoded with the SGR extended format (1006)
+func (t *Terminal) MouseSGR() bool {
+	return t.mouseSGR
+}
+
+// Cell returns the cell at the given position
+func (t *Terminal) Cell(x, y int) Cell {
+	if y < 0 || y >= t.height || x < 0 || x >= t.width {
+		return Cell{}
+	}
+	return t.screen.lines[y][x]
+}
+
+// Line returns the text of a line, without styles and trailing blanks
+func (t *Terminal) Line(y int) string {
+	if y < 0 || y >= t.height {
+		return ""
+	}
+	var sb strings.Builder
+	for _, cell := range t.screen.lines[y] {
+		if cell.Width == 0 {
+			continue
+		}
+		sb.WriteString(cell.String())
+	}
+	return strings.TrimRight(sb.String(), " ")
+}
+
+// Replies returns and clears the responses to queries of the application, such as the
+// cursor position report. They must be written back to the application input.
+func (t *Terminal) Replies() []byte {
+	replies := t.replies
+	t.replies = nil
+	return replies
+}
+
+// Render returns the screen contents as styled lines.
+// The cursor is drawn in reverse video when showCursor is set and the application didn't hide it.
+func (t *Terminal) Render(showCursor bool) string {
+	return t.RenderRows(t.ScreenTop(), func(x, row int, style Style) Style {
+		if showCursor && t.cursorVisible && x == t.cursor.x && row == t.ScreenTop()+t.cursor.y {
+			style.Reverse = !style.Reverse
+		}
+		return style
+	})
+}
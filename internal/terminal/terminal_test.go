@@ -0,0 +1,210 @@
+package terminal
+
+import (
+	"strings"
+	"testing"
+)
+
+func write(t *Terminal, s string) {
+	t.Write([]byte(s))
+}
+
+func TestPrintAndWrap(t *testing.T) {
+	term := New(5, 3)
+	write(term, "hello world")
+	if line := term.Line(0); line != "hello" {
+		t.Errorf("Expected 'hello' on line 0, got '%s'", line)
+	}
+	if line := term.Line(1); line != " worl" {
+		t.Errorf("Expected ' worl' on line 1, got '%s'", line)
+	}
+	if line := term.Line(2); line != "d" {
+		t.Errorf("Expected 'd' on line 2, got '%s'", line)
+	}
+
+	// Printing past the last line scrolls the screen
+	write(term, "\r\nabc\r\nxyz")
+	if line := term.Line(0); line != "d" {
+		t.Errorf("Expected 'd' on line 0 after scrolling, got '%s'", line)
+	}
+	if x, y := term.Cursor(); x != 3 || y != 2 {
+		t.Errorf("Expected cursor at 3,2, got %d,%d", x, y)
+	}
+}
+
+func TestCursorMovementAndErase(t *testing.T) {
+	term := New(10, 4)
+	write(term, "aaaaaaaaaa\r\nbbbbbbbbbb\r\ncccccccccc")
+	write(term, "\x1b[2;4H\x1b[K")
+	if line := term.Line(1); line != "bbb" {
+		t.Errorf("Expected 'bbb' after erasing to end of line, got '%s'", line)
+	}
+	write(term, "\x1b[1;3H\x1b[2P")
+	if line := term.Line(0); line != "aaaaaaaa" {
+		t.Errorf("Expected 8 a's after deleting 2 chars, got '%s'", line)
+	}
+	write(term, "\x1b[J")
+	if line := term.Line(2); line != "" {
+		t.Errorf("Expected line 2 erased, got '%s'", line)
+	}
+	write(term, "\x1b[2J\x1b[H")
+	for y := range 4 {
+		if line := term.Line(y); line != "" {
+			t.Errorf("Expected empty line %d after clearing the screen, got '%s'", y, line)
+		}
+	}
+}
+
+func TestScrollRegion(t *testing.T) {
+	term := New(5, 4)
+	write(term, "1\r\n2\r\n3\r\n4")
+	// Scroll only lines 2-3
+	write(term, "\x1b[2;3r\x1b[3;1H\n")
+	expected := []string{"1", "3", "", "4"}
+	for y, want := range expected {
+		if line := term.Line(y); line != want {
+			t.Errorf("Expected '%s' on line %d, got '%s'", want, y, line)
+		}
+	}
+	// Inserting a line pushes the region bottom out
+	write(term, "\x1b[2;1H\x1b[L")
+	expected = []string{"1", "", "3", "4"}
+	for y, want := range expected {
+		if line := term.Line(y); line != want {
+			t.Errorf("Expected '%s' on line %d after insert, got '%s'", want, y, line)
+		}
+	}
+}
+
+func TestGraphicRendition(t *testing.T) {
+	term := New(10, 1)
+	write(term, "\x1b[1;31mA\x1b[38;5;208mB\x1b[38:2::1:2:3mC\x1b[0mD")
+	if style := term.Cell(0, 0).Style; !style.Bold || style.Fg != PaletteColor(1) {
+		t.Errorf("Expected bold red, got %+v", style)
+	}
+	if style := term.Cell(1, 0).Style; style.Fg != PaletteColor(208) {
+		t.Errorf("Expected 256 color 208, got %+v", style)
+	}
+	if style := term.Cell(2, 0).Style; style.Fg != RGBColor(1, 2, 3) {
+		t.Errorf("Expected RGB 1,2,3, got %+v", style)
+	}
+	if style := term.Cell(3, 0).Style; style != (Style{}) {
+		t.Errorf("Expected default style after reset, got %+v", style)
+	}
+
+	rendered := term.Render(false)
+	if !strings.Contains(rendered, "\x1b[0;1;31mA") {
+		t.Errorf("Expected rendered bold red A, got %q", rendered)
+	}
+}
+
+func TestAltScreen(t *testing.T) {
+	term := New(10, 2)
+	write(term, "shell$")
+	write(term, "\x1b[?1049h\x1b[Hvim")
+	if !term.AltScreen() {
+		t.Fatal("Expected the alternate screen to be active")
+	}
+	if line := term.Line(0); line != "vim" {
+		t.Errorf("Expected 'vim' on the alternate screen, got '%s'", line)
+	}
+	write(term, "\x1b[?1049l")
+	if line := term.Line(0); line != "shell$" {
+		t.Errorf("Expected the main screen restored, got '%s'", line)
+	}
+	if x, _ := term.Cursor(); x != 6 {
+		t.Errorf("Expected the cursor restored to column 6, got %d", x)
+	}
+}
+
+func TestWideCharacters(t *testing.T) {
+	term := New(5, 2)
+	write(term, "a世b")
+	if cell := term.Cell(1, 0); cell.Content != "世" || cell.Width != 2 {
+		t.Errorf("Expected wide cell at 1, got %+v", cell)
+	}
+	if x, _ := term.Cursor(); x != 4 {
+		t.Errorf("Expected cursor at column 4, got %d", x)
+	}
+	// Overwriting half of the wide character blanks the other half
+	write(term, "\x1b[1;3Hx")
+	if line := term.Line(0); line != "a xb" {
+		t.Errorf("Expected 'a xb', got '%s'", line)
+	}
+}
+
+func TestResize(t *testing.T) {
+	term := New(10, 4)
+	write(term, "1\r\n2\r\n3\r\n4")
+	term.Resize(4, 2)
+	if width, height := term.Size(); width != 4 || height != 2 {
+		t.Fatalf("Expected 4x2, got %dx%d", width, height)
+	}
+	// The cursor line is kept visible
+	if line := term.Line(1); line != "4" {
+		t.Errorf("Expected '4' on the last line, got '%s'", line)
+	}
+	if _, y := term.Cursor(); y != 1 {
+		t.Errorf("Expected cursor on line 1, got %d", y)
+	}
+	term.Resize(8, 3)
+	if line := term.Line(2); line != "" {
+		t.Errorf("Expected a new blank line, got '%s'", line)
+	}
+}
+
+func TestModesAndReplies(t *testing.T) {
+	term := New(10, 5)
+	write(term, "\x1b[?1h\x1b[?2004h\x1b[?1002h\x1b[?1006h\x1b[?25l")
+	if !term.AppCursorKeys() || !term.BracketedPaste() || term.MouseMode() != MouseDrag || !term.MouseSGR() {
+		t.Error("Expected application cursor keys, bracketed paste and SGR drag mouse tracking")
+	}
+	if term.CursorVisible() {
+		t.Error("Expected hidden cursor")
+	}
+
+	write(term, "\x1b[3;4H\x1b[6n")
+	if reply := string(term.Replies()); reply != "\x1b[3;4R" {
+		t.Errorf("Expected cursor position report, got %q", reply)
+	}
+	if replies := term.Replies(); len(replies) != 0 {
+		t.Errorf("Expected replies to be cleared, got %q", replies)
+	}
+
+	write(term, "\x1b]0;root@web-1: /app\x07")
+	if title := term.Title(); title != "root@web-1: /app" {
+		t.Errorf("Expected title to be set, got '%s'", title)
+	}
+}
+
+func TestScrollback(t *testing.T) {
+	term := New(10, 2)
+	term.SetScrollback(3)
+	write(term, "one\r\ntwo\r\nthree\r\nfour\r\nfive\r\nsix")
+
+	// "one" was dropped from the full scrollback
+	if first, top := term.FirstRow(), term.ScreenTop(); first != 1 || top != 4 {
+		t.Fatalf("Expected rows 1 to 3 in the scrollback, got %d to %d", first, top)
+	}
+	for row, want := range map[int]string{0: "", 1: "two", 2: "three", 3: "four", 4: "five", 5: "six"} {
+		if text := term.RowText(row); text != want {
+			t.Errorf("Expected '%s' on row %d, got '%s'", want, row, text)
+		}
+	}
+	if text := term.Text(1, 1, 1, 3); text != "wo\nthree\nfo" {
+		t.Errorf("Expected the text across rows, got %q", text)
+	}
+	if rendered := term.RenderRows(2, nil); !strings.Contains(rendered, "three") || strings.Contains(rendered, "five") {
+		t.Errorf("Expected rows 2 and 3 to be rendered, got %q", rendered)
+	}
+
+	// The alternate screen doesn't feed the scrollback
+	write(term, "\x1b[?1049h\r\na\r\nb\r\nc")
+	if term.FirstRow() != term.ScreenTop() {
+		t.Errorf("Expected no scrollback on the alternate screen")
+	}
+	write(term, "\x1b[?1049l\x1b[3J")
+	if term.FirstRow() != term.ScreenTop() {
+		t.Errorf("Expected the scrollback to be erased")
+	}
+}
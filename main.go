@@ -5,10 +5,15 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mendes11/swarm-browser/internal/app"
+	"github.com/mendes11/swarm-browser/internal/cli"
 	"github.com/mendes11/swarm-browser/internal/config"
+	"github.com/mendes11/swarm-browser/internal/core"
+	"github.com/mendes11/swarm-browser/internal/terminal"
 )
 
 // Version variables - set by goreleaser at build time
@@ -23,14 +28,30 @@ func main() {
 	// Define command line flags
 	versionFlag := flag.Bool("version", false, "Print version information")
 	versionShortFlag := flag.Bool("v", false, "Print version information")
+	recordFlag := flag.String("record", "", "Record container sessions as asciicast files in the given directory")
+	recordInputFlag := flag.Bool("record-input", false, "Record the keys typed in container sessions (with --record)")
+	scrollbackFlag := flag.Int("scrollback", terminal.DefaultScrollback, "Lines of output kept by container sessions, for copy mode and search")
+	debugImageFlag := flag.String("debug-image", core.DefaultDebugImage, "Image of the debug sidecars started on tasks")
+	multiplexerFlag := flag.String("multiplexer", "", "Run container sessions inside tmux, screen or auto (either) when the image provides it")
+	openFlag := flag.String("open", "", "Open a bookmark at startup, switching to its cluster")
+	themeFlag := flag.String("theme", "", "Color theme: auto, dark, light, high-contrast or one defined in clusters.yml (default auto)")
+	noMouseFlag := flag.Bool("no-mouse", false, "Leave the mouse to the terminal, e.g. to select text, instead of clicking and scrolling the views")
+	timeoutFlag := flag.Duration("timeout", 30*time.Second, "Cancel the requests to the cluster taking longer than this, e.g. listing services (0 waits forever)")
+	keymapFlag := flag.String("keymap", "", "Keymap file overriding the keybindings (default keymap.yml in the user configuration directory)")
 
 	// Custom usage message
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Swarm Browser - Terminal UI for Docker Swarm\n\n")
 		fmt.Fprintf(os.Stderr, "Usage:\n")
-		fmt.Fprintf(os.Stderr, "  swarm-browser [flags]\n\n")
+		fmt.Fprintf(os.Stderr, "  swarm-browser [flags]\n")
+		fmt.Fprintf(os.Stderr, "  swarm-browser <command> [flags] [args]\n\n")
 		fmt.Fprintf(os.Stderr, "Flags:\n")
 		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nCommands:\n")
+		for _, command := range cli.Commands {
+			fmt.Fprintf(os.Stderr, "  %-14s %s\n", command.Name, command.Description)
+			fmt.Fprintf(os.Stderr, "  %-14s   swarm-browser %s\n", "", command.Usage)
+		}
 		fmt.Fprintf(os.Stderr, "\nConfiguration:\n")
 		fmt.Fprintf(os.Stderr, "  Swarm Browser looks for a 'clusters.yml' file in the current directory\n")
 		fmt.Fprintf(os.Stderr, "  to configure cluster connections.\n\n")
@@ -49,18 +70,105 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Normal application startup
-	conf := config.LoadConfig()
-	app := app.New(conf)
-	defer app.Close()
-
 	f, err := tea.LogToFile("debug.log", "debug")
 	if err != nil {
 		panic(err)
 	}
 	defer f.Close()
 
-	if _, err := tea.NewProgram(app, tea.WithAltScreen()).Run(); err != nil {
+	// Handle the subcommands that don't connect to a cluster
+	if flag.NArg() > 0 {
+		if command, found := cli.Lookup(flag.Arg(0)); found && command.Offline {
+			if err := command.Run(config.Config{}, flag.Args()[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	conf := config.LoadConfig()
+	if *multiplexerFlag != "" && !slices.Contains(core.Multiplexers, *multiplexerFlag) {
+		fmt.Fprintf(os.Stderr, "Invalid multiplexer %q, expected one of %v\n", *multiplexerFlag, core.Multiplexers)
+		os.Exit(2)
+	}
+	conf.Multiplexer = *multiplexerFlag
+	conf.RecordDir = *recordFlag
+	conf.RecordInput = *recordInputFlag
+	conf.DebugImage = *debugImageFlag
+	conf.Scrollback = *scrollbackFlag
+	conf.Mouse = !*noMouseFlag
+	conf.RequestTimeout = *timeoutFlag
+
+	// Handle subcommands
+	if flag.NArg() > 0 {
+		command, found := cli.Lookup(flag.Arg(0))
+		if !found {
+			fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", flag.Arg(0))
+			flag.Usage()
+			os.Exit(2)
+		}
+		if err := command.Run(conf, flag.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Check the bookmark to open before starting, as its cluster is connected to first
+	if *openFlag != "" {
+		state, err := config.LoadState(conf.StateFilePath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		bookmark, found := state.Bookmark(*openFlag)
+		if !found {
+			fmt.Fprintf(os.Stderr, "Unknown bookmark %q\n", *openFlag)
+			os.Exit(2)
+		}
+		if _, found := conf.Clusters[bookmark.Cluster]; !found {
+			fmt.Fprintf(os.Stderr, "The cluster %s of bookmark %q isn't configured\n", bookmark.Cluster, *openFlag)
+			os.Exit(2)
+		}
+		conf.OpenBookmark = *openFlag
+	}
+
+	// Check the keymap before starting, so that conflicting keys are reported right away
+	keymapPath := *keymapFlag
+	if keymapPath == "" {
+		keymapPath = config.DefaultKeymapPath()
+	} else if _, err := os.Stat(keymapPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	if conf.Keymap, err = config.LoadKeymap(keymapPath); err == nil {
+		_, err = app.NewAppKeyMap(conf.Keymap)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid keymap %s: %v\n", keymapPath, err)
+		os.Exit(2)
+	}
+
+	// Check the theme before starting, detecting the terminal background for auto
+	if *themeFlag != "" {
+		conf.Theme = *themeFlag
+	}
+	if _, err := app.LoadTheme(conf.Theme, conf.Themes); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid theme: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Normal application startup
+	app := app.New(conf)
+	defer app.Close()
+
+	options := []tea.ProgramOption{tea.WithAltScreen()}
+	if conf.Mouse {
+		// Terminals without mouse reporting ignore the request, leaving the keys to browse
+		options = append(options, tea.WithMouseCellMotion())
+	}
+	if _, err := tea.NewProgram(app, options...).Run(); err != nil {
 		log.Printf("Program exited with error: %v", err)
 		panic(err)
 	}